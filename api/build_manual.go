@@ -0,0 +1,469 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/build"
+	"github.com/limetext/text"
+)
+
+// Name of the output panel build output goes to
+const execPanel = "exec"
+
+var (
+	// running build processes of the windows
+	execs = struct {
+		sync.Mutex
+		m map[*backend.Window]*exec.Cmd
+	}{m: make(map[*backend.Window]*exec.Cmd)}
+
+	// index of the current build result of the windows
+	results = struct {
+		sync.Mutex
+		m map[*backend.Window]int
+	}{m: make(map[*backend.Window]int)}
+)
+
+// BuildSystemFor returns the build system used for the window. The build
+// system could be chosen with the build_system setting which is either a
+// build system resource name or the name of a project build system,
+// otherwise the build system whose selector best matches the active view is
+// used.
+func BuildSystemFor(w *backend.Window) *build.System {
+	var systems []*build.System
+	if p := ProjectOf(w); p != nil {
+		for _, m := range p.BuildSystems() {
+			if s, err := build.FromMap(m); err != nil {
+				log.Warn("Error in project build system: %s", err)
+			} else {
+				systems = append(systems, s)
+			}
+		}
+	}
+	for _, k := range build.Keys() {
+		systems = append(systems, build.Get(k))
+	}
+
+	if name := w.Settings().String("build_system", ""); name != "" {
+		if s := build.Get(name); s != nil {
+			return s
+		}
+		for _, s := range systems {
+			if s.Name == name {
+				return s
+			}
+		}
+	}
+
+	v := w.ActiveView()
+	if v == nil {
+		return nil
+	}
+	var (
+		ret  *build.System
+		best int
+	)
+	for _, s := range systems {
+		if s.Selector == "" {
+			continue
+		}
+		if score := ScoreSelector(v, 0, s.Selector); score > best {
+			ret, best = s, score
+		}
+	}
+	return ret
+}
+
+// Inserts the process output at the end of the panel view, incomplete utf8
+// sequences are kept until the rest of the bytes arrive. The output of a
+// build replaced by another one is dropped.
+type panelWriter struct {
+	lock    sync.Mutex
+	view    *backend.View
+	pending []byte
+	win     *backend.Window
+	cmd     *exec.Cmd
+}
+
+// Reports whether the process is the running build of the window.
+func isRunning(w *backend.Window, cmd *exec.Cmd) bool {
+	execs.Lock()
+	defer execs.Unlock()
+	return execs.m[w] == cmd
+}
+
+func (p *panelWriter) Write(b []byte) (int, error) {
+	if !isRunning(p.win, p.cmd) {
+		return len(b), nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	data := append(p.pending, b...)
+	n := len(data)
+	// looking for the start of a trailing incomplete rune
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				n = i
+			}
+			break
+		}
+	}
+	p.pending = append([]byte(nil), data[n:]...)
+	p.append(string(data[:n]))
+	return len(b), nil
+}
+
+func (p *panelWriter) append(s string) {
+	if s == "" {
+		return
+	}
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	e := p.view.BeginEdit()
+	p.view.Insert(e, p.view.Size(), s)
+	p.view.EndEdit(e)
+}
+
+type (
+	// ExecCommand runs a build command streaming its output to the exec
+	// output panel.
+	ExecCommand struct {
+		backend.DefaultCommand
+		args backend.Args
+	}
+
+	// BuildCommand runs the build system of the window.
+	BuildCommand struct {
+		backend.DefaultCommand
+		variant string
+	}
+
+	// NextResultCommand goes to the next result of the build output.
+	NextResultCommand struct {
+		backend.DefaultCommand
+	}
+
+	// PrevResultCommand goes to the previous result of the build output.
+	PrevResultCommand struct {
+		backend.DefaultCommand
+	}
+)
+
+func (c *ExecCommand) Init(args backend.Args) error {
+	c.args = args
+	return nil
+}
+
+// Returns the command line of the exec arguments
+func (c *ExecCommand) command() ([]string, error) {
+	shell := func(cmd string) []string {
+		if runtime.GOOS == "windows" {
+			return []string{"cmd.exe", "/C", cmd}
+		}
+		return []string{"/bin/sh", "-c", cmd}
+	}
+	if cmd, ok := c.args["shell_cmd"].(string); ok && cmd != "" {
+		return shell(cmd), nil
+	}
+	var argv []string
+	switch t := c.args["cmd"].(type) {
+	case string:
+		argv = []string{t}
+	case List:
+		for _, a := range t {
+			argv = append(argv, fmt.Sprint(a))
+		}
+	case []interface{}:
+		for _, a := range t {
+			argv = append(argv, fmt.Sprint(a))
+		}
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("exec requires cmd or shell_cmd")
+	}
+	if sh, _ := c.args["shell"].(bool); sh {
+		return shell(strings.Join(argv, " ")), nil
+	}
+	return argv, nil
+}
+
+func (c *ExecCommand) Run(w *backend.Window) error {
+	if kill, _ := c.args["kill"].(bool); kill {
+		execs.Lock()
+		cmd := execs.m[w]
+		execs.Unlock()
+		if cmd != nil && cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+		return nil
+	}
+
+	argv, err := c.command()
+	if err != nil {
+		return err
+	}
+	dir, _ := c.args["working_dir"].(string)
+	if dir == "" {
+		if v := w.ActiveView(); v != nil && v.FileName() != "" {
+			dir = filepath.Dir(v.FileName())
+		}
+	}
+	quiet, _ := c.args["quiet"].(bool)
+
+	v := CreateOutputPanel(w, execPanel, false)
+	set := v.Settings()
+	for key, arg := range map[string]string{
+		"result_file_regex": "file_regex",
+		"result_line_regex": "line_regex",
+	} {
+		s, _ := c.args[arg].(string)
+		set.Set(key, s)
+	}
+	set.Set("result_base_dir", dir)
+	if syn, ok := c.args["syntax"].(string); ok {
+		set.Set("syntax", syn)
+	}
+	results.Lock()
+	results.m[w] = -1
+	results.Unlock()
+	ShowPanel(w, outputPrefix+execPanel)
+
+	env := os.Environ()
+	var vars map[string]interface{}
+	switch t := c.args["env"].(type) {
+	case backend.Args:
+		vars = t
+	case map[string]interface{}:
+		vars = t
+	}
+	for k, val := range vars {
+		env = append(env, k+"="+os.ExpandEnv(fmt.Sprint(val)))
+	}
+	name := argv[0]
+	if path, ok := c.args["path"].(string); ok {
+		path = os.ExpandEnv(path)
+		env = append(env, "PATH="+path)
+		// exec looks for the program in the PATH of the editor
+		name = lookPath(name, path)
+	}
+	cmd := exec.Command(name, argv[1:]...)
+	cmd.Args[0] = argv[0]
+	cmd.Dir = dir
+	cmd.Env = env
+	out := &panelWriter{view: v, win: w, cmd: cmd}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	execs.Lock()
+	// only one build runs in a window at a time
+	if old := execs.m[w]; old != nil && old.Process != nil {
+		old.Process.Kill()
+	}
+	execs.m[w] = cmd
+	execs.Unlock()
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		execs.Lock()
+		delete(execs.m, w)
+		execs.Unlock()
+		out.append(fmt.Sprintf("[%s]\n[Finished]", err))
+		return err
+	}
+
+	go func() {
+		err := cmd.Wait()
+		execs.Lock()
+		running := execs.m[w] == cmd
+		if running {
+			delete(execs.m, w)
+		}
+		execs.Unlock()
+		// the panel belongs to the build that replaced this one
+		if !running || quiet {
+			return
+		}
+		elapsed := time.Since(start).Seconds()
+		if exit, ok := err.(*exec.ExitError); ok {
+			out.append(fmt.Sprintf("[Finished in %.1fs with %s]", elapsed, exit))
+		} else {
+			out.append(fmt.Sprintf("[Finished in %.1fs]", elapsed))
+		}
+	}()
+	return nil
+}
+
+// Looks for the program in the directories of path like exec.LookPath does
+// with the PATH environment variable, the name is returned as it is if it's
+// not found.
+func lookPath(name, path string) string {
+	if strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator) {
+		return name
+	}
+	exts := []string{""}
+	if runtime.GOOS == "windows" && filepath.Ext(name) == "" {
+		exts = append(exts, strings.Split(strings.ToLower(os.Getenv("PATHEXT")), ";")...)
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+		for _, ext := range exts {
+			p := filepath.Join(dir, name+ext)
+			fi, err := os.Stat(p)
+			if err != nil || fi.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" || fi.Mode()&0111 != 0 {
+				return p
+			}
+		}
+	}
+	return name
+}
+
+func (c *BuildCommand) Init(args backend.Args) error {
+	c.variant, _ = args["variant"].(string)
+	return nil
+}
+
+func (c *BuildCommand) Run(w *backend.Window) error {
+	s := BuildSystemFor(w)
+	if s == nil {
+		backend.GetEditor().Frontend().StatusMessage("No Build System")
+		return nil
+	}
+	if c.variant != "" {
+		if s = s.Variant(c.variant); s == nil {
+			return fmt.Errorf("No such build system variant: %s", c.variant)
+		}
+	}
+	args := backend.Args(s.Expand(ExtractVariables(w)))
+	backend.GetEditor().CommandHandler().RunWindowCommand(w, s.Command(), args)
+	return nil
+}
+
+func (c *NextResultCommand) Run(w *backend.Window) error {
+	return gotoResult(w, 1)
+}
+
+func (c *PrevResultCommand) Run(w *backend.Window) error {
+	return gotoResult(w, -1)
+}
+
+// Opens the result next to the current result in the given direction
+func gotoResult(w *backend.Window, dir int) error {
+	v := FindOutputPanel(w, execPanel)
+	if v == nil {
+		return nil
+	}
+	set := v.Settings()
+	rs, err := build.ParseResults(v.Substr(text.Region{A: 0, B: v.Size()}),
+		set.String("result_file_regex", ""), set.String("result_line_regex", ""),
+		set.String("result_base_dir", ""))
+	if err != nil || len(rs) == 0 {
+		return err
+	}
+
+	results.Lock()
+	i, ok := results.m[w]
+	if !ok || i < 0 || i >= len(rs) {
+		i = -1
+		if dir < 0 {
+			i = 0
+		}
+	}
+	i = (i + dir + len(rs)) % len(rs)
+	results.m[w] = i
+	results.Unlock()
+
+	r := rs[i]
+	line := v.Line(v.TextPoint(r.OutputLine, 0))
+	v.Sel().Clear()
+	v.Sel().Add(text.Region{A: line.A, B: line.A})
+	ShowPanel(w, outputPrefix+execPanel)
+
+	fv := OpenFile(w, r.File, 0)
+	if fv == nil || r.Line <= 0 {
+		return nil
+	}
+	col := r.Column - 1
+	if col < 0 {
+		col = 0
+	}
+	pt := fv.TextPoint(r.Line-1, col)
+	fv.Sel().Clear()
+	fv.Sel().Add(text.Region{A: pt, B: pt})
+	return nil
+}
+
+// Converts python variables dict to string map
+func pyVariables(v py.Object) (map[string]string, error) {
+	v2, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	args, ok := v2.(backend.Args)
+	if !ok {
+		return nil, fmt.Errorf("Expected type dict for variables, not %s", v.Type())
+	}
+	vars := make(map[string]string, len(args))
+	for k, val := range args {
+		if s, ok := val.(string); ok {
+			vars[k] = s
+		} else {
+			vars[k] = fmt.Sprint(val)
+		}
+	}
+	return vars, nil
+}
+
+func sublime_ExpandVariables(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	val, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	v, err = tu.GetItem(1)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := pyVariables(v)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(build.ExpandVariables(val, vars))
+}
+
+func init() {
+	ch := backend.GetEditor().CommandHandler()
+	cmds := map[string]interface{}{
+		"exec":        &ExecCommand{},
+		"build":       &BuildCommand{},
+		"next_result": &NextResultCommand{},
+		"prev_result": &PrevResultCommand{},
+	}
+	for name, cmd := range cmds {
+		if err := ch.Register(name, cmd); err != nil {
+			log.Warn("Failed to register command %s: %s", name, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLookPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Executables are found by extension on windows")
+	}
+	dir, err := ioutil.TempDir("", "build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	prog := filepath.Join(dir, "prog")
+	if err := ioutil.WriteFile(prog, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "data"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/nonexistent" + string(filepath.ListSeparator) + dir
+	tests := []struct {
+		name, path, exp string
+	}{
+		{"prog", path, prog},
+		{"prog", "/nonexistent", "prog"},
+		{"data", path, "data"},
+		{"./prog", path, "./prog"},
+	}
+	for i, test := range tests {
+		if got := lookPath(test.name, test.path); got != test.exp {
+			t.Errorf("Test %d: Expected %s, but got %s", i, test.exp, got)
+		}
+	}
+}
@@ -6,6 +6,8 @@ package api
 
 import (
 	"fmt"
+	"reflect"
+	"sync"
 
 	"github.com/limetext/backend"
 	"github.com/limetext/backend/log"
@@ -39,6 +41,9 @@ type (
 		py.BaseObject
 		inner py.Object
 		args  backend.Args
+		// set by the glue types to return the python command that
+		// is_enabled and the like are called on
+		instance func() (py.Object, error)
 	}
 	WindowCommandGlue struct {
 		py.BaseObject
@@ -76,6 +81,12 @@ func (c *CommandGlue) PyInit(args *py.Tuple, kwds *py.Dict) error {
 	return nil
 }
 
+// Returns a copy of the python command to query its state with other
+// arguments.
+func (c *CommandGlue) glue() *CommandGlue {
+	return &CommandGlue{inner: c.inner, instance: c.instance}
+}
+
 func (c *CommandGlue) CreatePyArgs(args backend.Args) (ret *py.Dict, err error) {
 	if r, err := toPython(args); err != nil {
 		return nil, err
@@ -84,55 +95,65 @@ func (c *CommandGlue) CreatePyArgs(args backend.Args) (ret *py.Dict, err error)
 	}
 }
 
-func (c *CommandGlue) callBool(name string, args backend.Args) bool {
-	gs := py.GilState_Ensure()
-	defer gs.Release()
-
+// Calls the method of the command's instance, returning nil and reporting
+// the error if it fails. The methods are the wrappers of sublime_plugin's
+// Command passing the arguments as keyword arguments.
+func (c *CommandGlue) call(name string, args backend.Args) py.Object {
+	if c.instance == nil {
+		return nil
+	}
 	var (
-		pyargs, r py.Object
-		err       error
+		pyargs, obj, r py.Object
+		err            error
 	)
 	if pyargs, err = c.CreatePyArgs(args); err != nil {
-		log.Error(err)
-		return false
+		reportError(err)
+		return nil
 	}
 	defer pyargs.Decref()
 
-	if r, err = c.CallMethodObjArgs(name, pyargs); err != nil {
-		log.Error(err)
-		return true
+	if obj, err = c.instance(); err != nil {
+		reportError(err)
+		return nil
 	}
-	defer r.Decref()
-	if r, ok := r.(*py.Bool); ok {
-		return r.Bool()
+	defer obj.Decref()
+
+	if r, err = callMethod(obj, name, pyargs); err != nil {
+		reportError(err)
+		return nil
+	}
+	return r
+}
+
+// Commands failing to tell get the default.
+func (c *CommandGlue) callBool(name string, args backend.Args, def bool) bool {
+	gs := py.GilState_Ensure()
+	defer gs.Release()
+
+	r := c.call(name, args)
+	if r == nil {
+		return def
 	}
-	return true
+	defer r.Decref()
+	return r.IsTrue()
 }
 
+// Commands failing to tell whether they are enabled or visible are, so
+// menus don't disable working commands.
 func (c *CommandGlue) IsEnabled() bool {
-	return c.callBool("is_enabled", c.args)
+	return c.callBool("is_enabled_", c.args, true)
 }
 
 func (c *CommandGlue) IsVisible() bool {
-	return c.callBool("is_visible", c.args)
+	return c.callBool("is_visible_", c.args, true)
 }
 
 func (c *CommandGlue) Description() string {
 	gs := py.GilState_Ensure()
 	defer gs.Release()
 
-	var (
-		pyargs, r py.Object
-		err       error
-	)
-	if pyargs, err = c.CreatePyArgs(c.args); err != nil {
-		log.Error(err)
-		return ""
-	}
-	defer pyargs.Decref()
-
-	if r, err = c.CallMethodObjArgs("description", pyargs); err != nil {
-		log.Error(err)
+	r := c.call("description_", c.args)
+	if r == nil {
 		return ""
 	}
 	defer r.Decref()
@@ -142,8 +163,134 @@ func (c *CommandGlue) Description() string {
 	return ""
 }
 
-func pyError(err error) error {
-	return fmt.Errorf("%v", err)
+// The python command instances states are queried on, one per command class
+// and view or window like sublime does.
+var instances = struct {
+	sync.Mutex
+	m map[instanceKey]py.Object
+}{m: make(map[instanceKey]py.Object)}
+
+type instanceKey struct {
+	class py.Object
+	// the view or window the instance was created with, nil for application
+	// commands
+	target interface{}
+}
+
+// Returns the instance of the class for the target, creating it with args
+// the first time. It must be called with the python lock held.
+func instanceOf(class py.Object, target interface{}, args ...py.Object) (py.Object, error) {
+	k := instanceKey{class, target}
+	instances.Lock()
+	obj, ok := instances.m[k]
+	instances.Unlock()
+	if !ok {
+		var err error
+		if obj, err = callPython(class, args...); err != nil {
+			return nil, err
+		}
+		instances.Lock()
+		instances.m[k] = obj
+		instances.Unlock()
+	}
+	obj.Incref()
+	return obj, nil
+}
+
+// Drops the instances of the class, or the ones created with the closed view
+// and windows when class is nil.
+func forgetInstances(class py.Object, v *backend.View) {
+	var windows []*backend.Window
+	if class == nil {
+		windows = backend.GetEditor().Windows()
+	}
+	instances.Lock()
+	var drop []py.Object
+	for k, obj := range instances.m {
+		forget := k.class == class
+		switch t := k.target.(type) {
+		case *backend.View:
+			forget = forget || (class == nil && t == v)
+		case *backend.Window:
+			forget = forget || (class == nil && !hasWindow(windows, t))
+		}
+		if forget {
+			drop = append(drop, obj)
+			delete(instances.m, k)
+		}
+	}
+	instances.Unlock()
+	if len(drop) == 0 {
+		return
+	}
+	gs := py.GilState_Ensure()
+	defer gs.Release()
+	for _, obj := range drop {
+		obj.Decref()
+	}
+}
+
+func hasWindow(windows []*backend.Window, w *backend.Window) bool {
+	for _, w2 := range windows {
+		if w2 == w {
+			return true
+		}
+	}
+	return false
+}
+
+func onInstancesClose(v *backend.View) {
+	forgetInstances(nil, v)
+}
+
+func (c *TextCommandGlue) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	c.instance = c.activeInstance
+	return c.CommandGlue.PyInit(args, kwds)
+}
+
+// Text commands are queried on the active view, like sublime does.
+func (c *TextCommandGlue) activeInstance() (py.Object, error) {
+	var v *backend.View
+	if w := backend.GetEditor().ActiveWindow(); w != nil {
+		v = w.ActiveView()
+	}
+	if v == nil {
+		return nil, fmt.Errorf("No active view to query %v on", c.inner)
+	}
+	pyv, err := toPython(v)
+	if err != nil {
+		return nil, err
+	}
+	defer pyv.Decref()
+	return instanceOf(c.inner, v, pyv)
+}
+
+func (c *WindowCommandGlue) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	c.instance = c.activeInstance
+	return c.CommandGlue.PyInit(args, kwds)
+}
+
+// Window commands are queried on the active window.
+func (c *WindowCommandGlue) activeInstance() (py.Object, error) {
+	w := backend.GetEditor().ActiveWindow()
+	if w == nil {
+		return nil, fmt.Errorf("No active window to query %v on", c.inner)
+	}
+	pyw, err := toPython(w)
+	if err != nil {
+		return nil, err
+	}
+	defer pyw.Decref()
+	return instanceOf(c.inner, w, pyw)
+}
+
+func (c *ApplicationCommandGlue) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	c.instance = c.activeInstance
+	return c.CommandGlue.PyInit(args, kwds)
+}
+
+func (c *ApplicationCommandGlue) activeInstance() (py.Object, error) {
+	return instanceOf(c.inner, nil)
 }
 
 func (c *TextCommandGlue) Run(v *backend.View, e *backend.Edit) error {
@@ -172,7 +319,7 @@ func (c *TextCommandGlue) Run(v *backend.View, e *backend.Edit) error {
 	defer pyargs.Decref()
 
 	init := util.Prof.Enter("tc.init")
-	if obj, err = c.inner.Base().CallFunctionObjArgs(pyv); err != nil {
+	if obj, err = callPython(c.inner, pyv); err != nil {
 		return pyError(err)
 	}
 	defer obj.Decref()
@@ -186,7 +333,8 @@ func (c *TextCommandGlue) Run(v *backend.View, e *backend.Edit) error {
 		log.Finest("Discarded: %s", e)
 		v.EndEdit(e)
 		v.SetScratch(old)
-		ret, err := obj.Base().CallMethodObjArgs("run_", pye, pyargs)
+		// the edit is ended, so using it raises a ValueError
+		ret, err := callMethod(obj, "run_", pye, pyargs)
 		if ret != nil {
 			ret.Decref()
 		}
@@ -195,7 +343,10 @@ func (c *TextCommandGlue) Run(v *backend.View, e *backend.Edit) error {
 		}
 		return nil
 	}
-	ret, err := obj.Base().CallMethodObjArgs("run__", pye, pyargs)
+	// plugins keeping the edit for later get a ValueError using it
+	startCommandEdit(v, e)
+	defer endCommandEdit(e)
+	ret, err := callMethod(obj, "run__", pye, pyargs)
 	if ret != nil {
 		ret.Decref()
 	}
@@ -224,11 +375,11 @@ func (c *WindowCommandGlue) Run(w *backend.Window) error {
 	}
 	defer pyargs.Decref()
 
-	if obj, err = c.inner.Base().CallFunctionObjArgs(pyw); err != nil {
+	if obj, err = callPython(c.inner, pyw); err != nil {
 		return pyError(err)
 	}
 	defer obj.Decref()
-	if ret, err := obj.Base().CallMethodObjArgs("run_", pyargs); err != nil {
+	if ret, err := callMethod(obj, "run_", pyargs); err != nil {
 		return pyError(err)
 	} else {
 		ret.Decref()
@@ -249,12 +400,12 @@ func (c *ApplicationCommandGlue) Run() error {
 	}
 	defer pyargs.Decref()
 
-	obj, err := c.inner.Base().CallFunctionObjArgs()
+	obj, err := callPython(c.inner)
 	if err != nil {
 		return pyError(err)
 	}
 	defer obj.Decref()
-	if ret, err := obj.Base().CallMethodObjArgs("run", pyargs); err != nil {
+	if ret, err := callMethod(obj, "run", pyargs); err != nil {
 		return pyError(err)
 	} else {
 		ret.Decref()
@@ -262,6 +413,144 @@ func (c *ApplicationCommandGlue) Run() error {
 	return nil
 }
 
-func (c *ApplicationCommandGlue) IsChecked(args backend.Args) bool {
-	return c.callBool("is_checked", args)
+func (c *CommandGlue) IsChecked(args backend.Args) bool {
+	return c.callBool("is_checked_", args, false)
+}
+
+// Commands registered through RegisterCommand by name, kept for querying
+// their states
+var commands = struct {
+	sync.Mutex
+	m map[string]interface{}
+}{m: make(map[string]interface{})}
+
+// RegisterCommand registers the command in the editor command handler and
+// keeps it for CommandState.
+func RegisterCommand(name string, cmd interface{}) error {
+	if err := backend.GetEditor().CommandHandler().Register(name, cmd); err != nil {
+		return err
+	}
+	commands.Lock()
+	old := commands.m[name]
+	commands.m[name] = cmd
+	commands.Unlock()
+	forgetCommand(old)
+	return nil
+}
+
+// UnregisterCommand removes the command from the editor command handler.
+func UnregisterCommand(name string) error {
+	commands.Lock()
+	old := commands.m[name]
+	delete(commands.m, name)
+	commands.Unlock()
+	forgetCommand(old)
+	return backend.GetEditor().CommandHandler().Unregister(name)
+}
+
+// Drops the instances of a replaced python command.
+func forgetCommand(cmd interface{}) {
+	if c, ok := cmd.(interface {
+		glue() *CommandGlue
+	}); ok {
+		forgetInstances(c.glue().inner, nil)
+	}
+}
+
+// Returns a new instance of the registered command initialized with the
+// arguments, the registered command could be running with other arguments.
+// It returns nil if there is no such command.
+func queryCommand(name string, args backend.Args) interface{} {
+	commands.Lock()
+	cmd := commands.m[name]
+	commands.Unlock()
+	switch t := cmd.(type) {
+	case nil:
+		return nil
+	case interface {
+		glue() *CommandGlue
+	}:
+		cmd = t.glue()
+	default:
+		if v := reflect.ValueOf(cmd); v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+			cmd = reflect.New(v.Elem().Type()).Interface()
+		}
+	}
+	if c, ok := cmd.(backend.CustomInit); ok {
+		if err := c.Init(args); err != nil {
+			log.Warn("Error initializing %s command: %s", name, err)
+		}
+	}
+	return cmd
+}
+
+// CommandState returns whether the command is visible, enabled and checked
+// with the given arguments. Commands not registered with RegisterCommand are
+// visible, enabled and unchecked.
+func CommandState(name string, args backend.Args) (visible, enabled, checked bool) {
+	cmd := queryCommand(name, args)
+	if cmd == nil {
+		return true, true, false
+	}
+	visible, enabled = true, true
+	if c, ok := cmd.(backend.Command); ok {
+		visible, enabled = c.IsVisible(), c.IsEnabled()
+	}
+	if c, ok := cmd.(interface {
+		IsChecked(backend.Args) bool
+	}); ok {
+		checked = c.IsChecked(args)
+	}
+	return
+}
+
+// CommandDescription returns the description of the command with the given
+// arguments, an empty string for commands not registered with
+// RegisterCommand.
+func CommandDescription(name string, args backend.Args) string {
+	if c, ok := queryCommand(name, args).(backend.Command); ok {
+		return c.Description()
+	}
+	return ""
+}
+
+func sublime_Register(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := v.(*py.Unicode)
+	if !ok {
+		return nil, fmt.Errorf("Expected type string for register() arg1, not %s", v.Type())
+	}
+	if v, err = tu.GetItem(1); err != nil {
+		return nil, err
+	}
+	cmd, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := RegisterCommand(name.String(), cmd); err != nil {
+		return nil, err
+	}
+	return toPython(nil)
+}
+
+func sublime_Unregister(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := v.(*py.Unicode)
+	if !ok {
+		return nil, fmt.Errorf("Expected type string for unregister() arg1, not %s", v.Type())
+	}
+	if err := UnregisterCommand(name.String()); err != nil {
+		return nil, err
+	}
+	return toPython(nil)
+}
+
+func init() {
+	backend.OnClose.Add(onInstancesClose)
 }
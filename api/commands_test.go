@@ -5,10 +5,13 @@
 package api
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
 )
 
 func TestCommandGlueInit(t *testing.T) {
@@ -33,3 +36,58 @@ func TestCommandGlueBypassUndo(t *testing.T) {
 		t.Error("Expected false, but got true")
 	}
 }
+
+func TestCommandGlueCallBoolError(t *testing.T) {
+	c := CommandGlue{instance: func() (py.Object, error) {
+		return nil, fmt.Errorf("failing instance")
+	}}
+	if !c.IsEnabled() || !c.IsVisible() || c.IsChecked(nil) || c.Description() != "" {
+		t.Error("Expected commands failing to tell to be enabled, visible, unchecked and undescribed")
+	}
+}
+
+func TestCallPythonError(t *testing.T) {
+	l := py.NewLock()
+	defer l.Unlock()
+
+	builtins, err := py.Import("builtins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer builtins.Decref()
+	f, err := builtins.Base().GetAttrString("int")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Decref()
+
+	arg, _ := py.NewUnicode("12")
+	if ret, err := callPython(f, arg); err != nil {
+		t.Errorf("Error calling int: %s", err)
+	} else if v, _ := fromPython(ret); v != 12 {
+		t.Errorf("Expected 12, but got %v", v)
+	}
+
+	var got []*Error
+	defer func(old ErrorEvent) { OnError = old }(OnError)
+	OnError.Add(func(e *Error) { got = append(got, e) })
+	arg, _ = py.NewUnicode("x")
+	_, err = callPython(f, arg)
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Expected an *Error, but got %#v", err)
+	}
+	if e.Type != "ValueError" {
+		t.Errorf("Expected a ValueError, but got %s", e.Type)
+	}
+	if !strings.Contains(e.Message, "'x'") {
+		t.Errorf("Unexpected message %q", e.Message)
+	}
+	if !strings.HasPrefix(e.Traceback, "Traceback") || !strings.HasSuffix(e.Traceback, e.Error()+"\n") {
+		t.Errorf("Unexpected traceback %q", e.Traceback)
+	}
+	reportError(err)
+	if len(got) != 1 || got[0] != e {
+		t.Errorf("Expected OnError to be called with the error, but got %v", got)
+	}
+}
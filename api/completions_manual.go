@@ -0,0 +1,344 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/completion"
+	"github.com/limetext/text"
+)
+
+// Flags returned by completion listeners
+const (
+	INHIBIT_WORD_COMPLETIONS     = 8
+	INHIBIT_EXPLICIT_COMPLETIONS = 16
+)
+
+type (
+	// QueryCompletionsCallback returns the completions for the prefix typed
+	// at the locations of the view and the INHIBIT_* flags.
+	QueryCompletionsCallback func(v *backend.View, prefix string, locations []int) ([]completion.Completion, int)
+
+	QueryCompletionsEvent []QueryCompletionsCallback
+)
+
+// OnQueryCompletions is called when completions are requested, python
+// on_query_completions listeners are added to it.
+var OnQueryCompletions QueryCompletionsEvent
+
+func (qe *QueryCompletionsEvent) Add(cb QueryCompletionsCallback) {
+	*qe = append(*qe, cb)
+}
+
+// QueryCompletions returns the completions of the prefix typed at the
+// locations. Completions of the listeners come first, then the ones of the
+// completion files and snippets matching the scope of the first location and
+// at last the words of the view, unless the listeners inhibit them.
+func QueryCompletions(v *backend.View, prefix string, locations []int) []completion.Completion {
+	var (
+		ret   []completion.Completion
+		flags int
+	)
+	for _, cb := range OnQueryCompletions {
+		cs, f := cb(v, prefix, locations)
+		ret = append(ret, cs...)
+		flags |= f
+	}
+	pt := 0
+	if len(locations) > 0 {
+		pt = locations[0]
+	}
+	if flags&INHIBIT_EXPLICIT_COMPLETIONS == 0 {
+		ret = append(ret, completion.Find(prefix, func(sel string) int {
+			return ScoreSelector(v, pt, sel)
+		})...)
+		for _, s := range SnippetsFor(v, pt, prefix) {
+			c := completion.Completion{Trigger: s.TabTrigger, Contents: s.Content}
+			if s.Description != "" {
+				c.Trigger += "\t" + s.Description
+			}
+			ret = append(ret, c)
+		}
+	}
+	if flags&INHIBIT_WORD_COMPLETIONS == 0 {
+		for _, w := range ExtractCompletions(v, prefix, pt) {
+			ret = append(ret, completion.Completion{Trigger: w, Contents: w})
+		}
+	}
+
+	// keeping the first completion of every trigger
+	seen := make(map[string]bool)
+	uniq := ret[:0]
+	for _, c := range ret {
+		if !seen[c.Trigger] {
+			seen[c.Trigger] = true
+			uniq = append(uniq, c)
+		}
+	}
+	return uniq
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ExtractCompletions returns the words of the view starting with prefix,
+// the words closer to pt come first. The word at pt isn't included.
+func ExtractCompletions(v *backend.View, prefix string, pt int) []string {
+	if prefix == "" {
+		return nil
+	}
+	type word struct {
+		s    string
+		dist int
+	}
+	var words []word
+	index := make(map[string]int)
+	pr := []rune(prefix)
+	rs := []rune(v.Substr(text.Region{A: 0, B: v.Size()}))
+	for i := 0; i < len(rs); {
+		if !isWordRune(rs[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(rs) && isWordRune(rs[i]) {
+			i++
+		}
+		s := string(rs[start:i])
+		if i-start <= len(pr) || (start <= pt && pt <= i) || string(rs[start:start+len(pr)]) != prefix {
+			continue
+		}
+		dist := start - pt
+		if dist < 0 {
+			dist = pt - i
+		}
+		if j, ok := index[s]; ok {
+			if dist < words[j].dist {
+				words[j].dist = dist
+			}
+			continue
+		}
+		index[s] = len(words)
+		words = append(words, word{s, dist})
+	}
+	// insertion sort keeps the order of the words with the same distance
+	for i := 1; i < len(words); i++ {
+		for j := i; j > 0 && words[j].dist < words[j-1].dist; j-- {
+			words[j], words[j-1] = words[j-1], words[j]
+		}
+	}
+	ret := make([]string, len(words))
+	for i, w := range words {
+		ret[i] = w.s
+	}
+	return ret
+}
+
+// Returns the word characters right before pt
+func wordPrefix(v *backend.View, pt int) text.Region {
+	l := v.Line(pt)
+	rs := []rune(v.Substr(text.Region{A: l.A, B: pt}))
+	i := len(rs)
+	for i > 0 && isWordRune(rs[i-1]) {
+		i--
+	}
+	return text.Region{A: pt - (len(rs) - i), B: pt}
+}
+
+// InsertBestCompletionCommand replaces the word before the cursors with the
+// first completion of it. The default argument is inserted when there is no
+// completion, with exact only completions matching the whole word are used.
+type InsertBestCompletionCommand struct {
+	backend.DefaultCommand
+	def   string
+	exact bool
+}
+
+func (c *InsertBestCompletionCommand) Init(args backend.Args) error {
+	c.def, _ = args["default"].(string)
+	c.exact, _ = args["exact"].(bool)
+	return nil
+}
+
+func (c *InsertBestCompletionCommand) Run(v *backend.View, e *backend.Edit) error {
+	if err := CheckEdit(v); err != nil {
+		return err
+	}
+	sel := v.Sel().Regions()
+	if len(sel) == 0 {
+		return nil
+	}
+	locations := make([]int, len(sel))
+	for i, r := range sel {
+		locations[i] = r.B
+	}
+	pr := wordPrefix(v, sel[0].B)
+	prefix := v.Substr(pr)
+	if prefix != "" {
+		for _, comp := range QueryCompletions(v, prefix, locations) {
+			if c.exact && comp.Word() != prefix {
+				continue
+			}
+			// replacing the prefix of every cursor
+			v.Sel().Clear()
+			for _, r := range sel {
+				v.Sel().Add(text.Region{A: r.B - pr.Size(), B: r.B})
+			}
+			return InsertSnippet(v, e, comp.Contents, nil)
+		}
+	}
+	for i := range sel {
+		r := v.Sel().Get(i)
+		v.Replace(e, r, c.def)
+	}
+	return nil
+}
+
+var _onQueryCompletionsGlueClass = py.Class{
+	Name:    "sublime.OnQueryCompletionsGlue",
+	Pointer: (*OnQueryCompletionsGlue)(nil),
+}
+
+// OnQueryCompletionsGlue adds python on_query_completions listeners to
+// OnQueryCompletions.
+type OnQueryCompletionsGlue struct {
+	py.BaseObject
+	inner py.Object
+}
+
+func (c *OnQueryCompletionsGlue) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	if args.Size() != 1 {
+		return fmt.Errorf("Expected only 1 argument not %d", args.Size())
+	}
+	if v, err := args.GetItem(0); err != nil {
+		return err
+	} else {
+		c.inner = v
+	}
+	c.inner.Incref()
+	c.Incref()
+
+	OnQueryCompletions.Add(c.onQueryCompletions)
+	return nil
+}
+
+func (c *OnQueryCompletionsGlue) onQueryCompletions(v *backend.View, prefix string, locations []int) ([]completion.Completion, int) {
+	l := py.NewLock()
+	defer l.Unlock()
+
+	pv, err := toPython(v)
+	if err != nil {
+		log.Error(err)
+		return nil, 0
+	}
+	defer pv.Decref()
+	pp, err := toPython(prefix)
+	if err != nil {
+		log.Error(err)
+		return nil, 0
+	}
+	defer pp.Decref()
+	pl, err := toPython(locations)
+	if err != nil {
+		log.Error(err)
+		return nil, 0
+	}
+	defer pl.Decref()
+
+	ret, err := callPython(c.inner, pv, pp, pl)
+	if err != nil {
+		reportError(err)
+		return nil, 0
+	}
+	defer ret.Decref()
+	r, err := fromPython(ret)
+	if err != nil {
+		log.Error(err)
+		return nil, 0
+	}
+	cs, flags, err := completionsFromPython(r)
+	if err != nil {
+		log.Error(err)
+	}
+	return cs, flags
+}
+
+// Converts the return value of on_query_completions, which is either a list
+// of completions or a tuple of the list and flags.
+func completionsFromPython(r interface{}) ([]completion.Completion, int, error) {
+	var (
+		items []interface{}
+		flags int
+	)
+	switch t := r.(type) {
+	case nil:
+		return nil, 0, nil
+	case List:
+		items = t
+	case Tuple:
+		if len(t) != 2 {
+			return nil, 0, fmt.Errorf("Expected (completions, flags) from on_query_completions, not %v", t)
+		}
+		l, ok := t[0].(List)
+		if !ok {
+			return nil, 0, fmt.Errorf("Expected list of completions, not %v", t[0])
+		}
+		items = l
+		flags, _ = t[1].(int)
+	default:
+		return nil, 0, fmt.Errorf("Unexpected on_query_completions return value: %v", r)
+	}
+
+	var ret []completion.Completion
+	for _, it := range items {
+		switch t := it.(type) {
+		case List:
+			it = []interface{}(t)
+		case Tuple:
+			it = []interface{}(t)
+		case backend.Args:
+			it = map[string]interface{}(t)
+		}
+		c, err := completion.New(it)
+		if err != nil {
+			return ret, flags, err
+		}
+		ret = append(ret, c)
+	}
+	return ret, flags, nil
+}
+
+func (o *View) Py_extract_completions(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	prefix, err := pyStringArg(tu, kw, 0, "prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	pt, err := pyIntArg(tu, kw, 1, "tp", -1)
+	if err != nil {
+		return nil, err
+	}
+	if pt < 0 {
+		if sel := o.data.Sel(); sel.Len() > 0 {
+			pt = sel.Get(0).B
+		}
+	}
+	return toPython(ExtractCompletions(o.data, prefix, pt))
+}
+
+func init() {
+	ch := backend.GetEditor().CommandHandler()
+	if err := ch.Register("insert_best_completion", &InsertBestCompletionCommand{}); err != nil {
+		log.Warn("Failed to register command %s: %s", "insert_best_completion", err)
+	}
+}
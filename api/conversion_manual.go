@@ -10,6 +10,7 @@ import (
 
 	"github.com/limetext/backend"
 	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/sheet"
 	"github.com/limetext/text"
 )
 
@@ -72,6 +73,16 @@ func toPython(r interface{}) (py.Object, error) {
 			v2.data = t
 			return v2, nil
 		}
+	case *sheet.Sheet:
+		pyret0, err := _sheetClass.Alloc(1)
+		if err != nil {
+			return nil, err
+		} else if v2, ok := pyret0.(*Sheet); !ok {
+			return nil, fmt.Errorf("Unable to convert return value to the right type?!: %s", pyret0.Type())
+		} else {
+			v2.data = t
+			return v2, nil
+		}
 	case text.Region:
 		pyret0, err := _regionClass.Alloc(1)
 		if err != nil {
@@ -79,7 +90,7 @@ func toPython(r interface{}) (py.Object, error) {
 		} else if v2, ok := pyret0.(*Region); !ok {
 			return nil, fmt.Errorf("Unable to convert return value to the right type?!: %s", pyret0.Type())
 		} else {
-			v2.data = t
+			v2.data, v2.xpos = t, -1
 			return v2, nil
 		}
 	case *text.RegionSet:
@@ -193,6 +204,12 @@ func fromPython(r py.Object) (interface{}, error) {
 		return t.data, nil
 	case *Region:
 		return t.data, nil
+	case *View:
+		return t.data, nil
+	case *Window:
+		return t.data, nil
+	case *Sheet:
+		return t.data, nil
 	case *py.List:
 		g := make(List, t.Size())
 		for i, r := range t.Slice() {
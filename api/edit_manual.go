@@ -0,0 +1,166 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+)
+
+// The edits python may use. The edit of a text command expires when the
+// command returns, taking the edits begun while it ran with it. Other edits
+// expire when they are passed to end_edit or their view closes.
+var edits = struct {
+	sync.Mutex
+	m map[*backend.Edit]editState
+	// the edits of the running text commands, innermost last
+	cmds []*backend.Edit
+	// counts the edits begun
+	n int
+}{m: make(map[*backend.Edit]editState)}
+
+type editState struct {
+	view *backend.View
+	// the edit of the text command running when the edit began
+	cmd *backend.Edit
+	n   int
+}
+
+// Makes the edit of a text command live until endCommandEdit.
+func startCommandEdit(v *backend.View, e *backend.Edit) {
+	edits.Lock()
+	edits.m[e] = editState{view: v}
+	edits.cmds = append(edits.cmds, e)
+	edits.Unlock()
+}
+
+// Expires the edit of the text command, the edits begun while it ran which
+// weren't ended are ended here.
+func endCommandEdit(e *backend.Edit) {
+	edits.Lock()
+	for i := len(edits.cmds) - 1; i >= 0; i-- {
+		if edits.cmds[i] == e {
+			edits.cmds = append(edits.cmds[:i], edits.cmds[i+1:]...)
+			break
+		}
+	}
+	delete(edits.m, e)
+	// the last begun first
+	var begun []*backend.Edit
+	var states []editState
+	for e2, st := range edits.m {
+		if st.cmd != e {
+			continue
+		}
+		delete(edits.m, e2)
+		i := sort.Search(len(states), func(i int) bool { return states[i].n < st.n })
+		begun = append(begun[:i], append([]*backend.Edit{e2}, begun[i:]...)...)
+		states = append(states[:i], append([]editState{st}, states[i:]...)...)
+	}
+	edits.Unlock()
+	for i, e2 := range begun {
+		states[i].view.EndEdit(e2)
+	}
+}
+
+// Makes an edit begun by python live, tying it to the running text command.
+func startEdit(v *backend.View, e *backend.Edit) {
+	edits.Lock()
+	defer edits.Unlock()
+	edits.n++
+	st := editState{view: v, n: edits.n}
+	if n := len(edits.cmds); n != 0 {
+		st.cmd = edits.cmds[n-1]
+	}
+	edits.m[e] = st
+}
+
+func expireEdit(e *backend.Edit) {
+	edits.Lock()
+	delete(edits.m, e)
+	edits.Unlock()
+}
+
+func onEditsClose(v *backend.View) {
+	edits.Lock()
+	defer edits.Unlock()
+	for e, st := range edits.m {
+		if st.view == v {
+			delete(edits.m, e)
+		}
+	}
+}
+
+func liveEdit(e *backend.Edit) bool {
+	edits.Lock()
+	defer edits.Unlock()
+	_, ok := edits.m[e]
+	return ok
+}
+
+// IsInEdit reports whether python has a live edit of the view.
+func IsInEdit(v *backend.View) bool {
+	edits.Lock()
+	defer edits.Unlock()
+	for _, st := range edits.m {
+		if st.view == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the edit argument, a ValueError if it expired.
+func editArg(tu *py.Tuple, i int64) (*backend.Edit, error) {
+	v, ok := pyArg(tu, nil, i, "edit")
+	if !ok {
+		return nil, fmt.Errorf("Missing edit argument")
+	}
+	e, ok := v.(*Edit)
+	if !ok {
+		return nil, fmt.Errorf("Expected type Edit for edit, not %s", v.Type())
+	}
+	if !liveEdit(e.data) {
+		return nil, py.NewError(py.ValueError, "Edit objects may not be used after the TextCommand's run method has returned")
+	}
+	return e.data, nil
+}
+
+func (o *View) Py_begin_edit() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	e := o.data.BeginEdit()
+	startEdit(o.data, e)
+	return toPython(e)
+}
+
+func (o *View) Py_end_edit(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	e, err := editArg(tu, 0)
+	if err != nil {
+		return nil, err
+	}
+	expireEdit(e)
+	o.data.EndEdit(e)
+	return toPython(nil)
+}
+
+func (o *View) Py_is_in_edit() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(IsInEdit(o.data))
+}
+
+func init() {
+	backend.OnClose.Add(onEditsClose)
+}
@@ -0,0 +1,369 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/encoding"
+	"github.com/limetext/text"
+)
+
+// The encoding and line endings a view is saved with, and the number of
+// changes made to its buffer decoding the loaded text.
+type fileFormat struct {
+	encoding    string
+	lineEndings string
+	decoded     int
+}
+
+var formats = struct {
+	sync.Mutex
+	m map[*backend.View]fileFormat
+}{m: make(map[*backend.View]fileFormat)}
+
+// Returns the format of the view, views which weren't loaded or saved yet
+// have the default line endings and an undefined encoding.
+func formatOf(v *backend.View) fileFormat {
+	formats.Lock()
+	f, ok := formats.m[v]
+	formats.Unlock()
+	if !ok {
+		f = fileFormat{encoding: encoding.Undefined, lineEndings: defaultLineEndings(v)}
+	}
+	return f
+}
+
+func setFormat(v *backend.View, f fileFormat) {
+	formats.Lock()
+	formats.m[v] = f
+	formats.Unlock()
+}
+
+// Returns the line endings of the default_line_ending setting.
+func defaultLineEndings(v *backend.View) string {
+	switch strings.ToLower(v.Settings().String("default_line_ending", "system")) {
+	case "windows":
+		return encoding.Windows
+	case "unix":
+		return encoding.Unix
+	case "cr":
+		return encoding.CR
+	}
+	if runtime.GOOS == "windows" {
+		return encoding.Windows
+	}
+	return encoding.Unix
+}
+
+// Returns the encoding new files are saved with.
+func defaultEncoding(v *backend.View) string {
+	if enc := v.Settings().String("default_encoding", encoding.UTF8); encoding.Valid(enc) {
+		return enc
+	}
+	return encoding.UTF8
+}
+
+// Encoding returns the encoding of the view's file.
+func Encoding(v *backend.View) string {
+	return formatOf(v).encoding
+}
+
+// SetEncoding changes the encoding the view is saved with.
+func SetEncoding(v *backend.View, enc string) error {
+	if !encoding.Valid(enc) {
+		return fmt.Errorf("Unknown encoding %s", enc)
+	}
+	f := formatOf(v)
+	f.encoding = enc
+	setFormat(v, f)
+	return nil
+}
+
+// LineEndings returns the line endings of the view's file.
+func LineEndings(v *backend.View) string {
+	return formatOf(v).lineEndings
+}
+
+// SetLineEndings changes the line endings the view is saved with.
+func SetLineEndings(v *backend.View, le string) error {
+	if !encoding.ValidLineEndings(le) {
+		return fmt.Errorf("Unknown line endings %s", le)
+	}
+	f := formatOf(v)
+	f.lineEndings = le
+	setFormat(v, f)
+	return nil
+}
+
+// ChangeCount returns the change count of the view's buffer leaving out the
+// changes made decoding its file, it's the change_count of python views.
+func ChangeCount(v *backend.View) int {
+	return v.ChangeCount() - formatOf(v).decoded
+}
+
+// Marks the buffer as saved the way the backend does once it's written.
+func markSaved(v *backend.View) {
+	v.Settings().Set("lime.last_save_change_count", v.ChangeCount())
+}
+
+// The backend loads files as UTF-8 text, so the file is read again to
+// detect its format and the buffer is replaced with the decoded text when
+// the two differ. The replacement isn't undoable, doesn't make the view
+// dirty and isn't counted by ChangeCount.
+func onEncodingLoad(v *backend.View) {
+	name := v.FileName()
+	if name == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		log.Warn("Error reading %s: %s", name, err)
+		return
+	}
+	f := fileFormat{encoding: encoding.Detect(data)}
+	s, err := encoding.Decode(data, f.encoding)
+	if err != nil {
+		log.Warn("Error decoding %s as %s: %s", name, f.encoding, err)
+		return
+	}
+	if f.lineEndings = encoding.DetectLineEndings(s); f.lineEndings == "" {
+		f.lineEndings = defaultLineEndings(v)
+	}
+
+	s = encoding.NormalizeLineEndings(s)
+	all := text.Region{A: 0, B: v.Size()}
+	if s == v.Substr(all) {
+		setFormat(v, f)
+		return
+	}
+	// like the backend does while loading, edits of scratch views aren't
+	// added to the undo stack
+	count := v.ChangeCount()
+	scratch := v.IsScratch()
+	v.SetScratch(true)
+	e := v.BeginEdit()
+	v.Replace(e, all, s)
+	v.EndEdit(e)
+	v.SetScratch(scratch)
+	f.decoded = v.ChangeCount() - count
+	setFormat(v, f)
+	markSaved(v)
+}
+
+// Save saves the view to its file like SaveAs.
+func Save(v *backend.View) error {
+	return SaveAs(v, v.FileName())
+}
+
+// SaveAs saves the view to the file in the view's encoding and line
+// endings, views which weren't loaded or saved yet get the default_encoding.
+// The backend saves UTF-8 text with the line endings of the buffer, views
+// in other formats are encoded and written here instead.
+func SaveAs(v *backend.View, name string) error {
+	f := formatOf(v)
+	if f.encoding == encoding.Undefined {
+		f.encoding = defaultEncoding(v)
+		setFormat(v, f)
+	}
+	if f.encoding == encoding.UTF8 && f.lineEndings == encoding.Unix {
+		return v.SaveAs(name)
+	}
+	backend.OnPreSave.Call(v)
+	// on_pre_save listeners can change the format
+	f = formatOf(v)
+	s := encoding.ApplyLineEndings(v.Substr(text.Region{A: 0, B: v.Size()}), f.lineEndings)
+	data, err := encoding.Encode(s, f.encoding)
+	if err != nil {
+		log.Error("Unable to save %s as %s, saved as %s instead: %s", name, f.encoding, encoding.UTF8, err)
+		f.encoding = encoding.UTF8
+		setFormat(v, f)
+		data = []byte(s)
+	}
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(name); err == nil {
+		mode = fi.Mode()
+	}
+	if err := ioutil.WriteFile(name, data, mode); err != nil {
+		return err
+	}
+	if v.FileName() != name {
+		if err := v.SetFileName(name); err != nil {
+			return err
+		}
+	}
+	markSaved(v)
+	backend.OnPostSave.Call(v)
+	return nil
+}
+
+type (
+	// SaveCommand saves the view in its encoding and line endings, views
+	// without a file are saved where the frontend prompts for.
+	SaveCommand struct {
+		backend.DefaultCommand
+	}
+
+	// SaveAsCommand saves the view to the file given by the name argument.
+	SaveAsCommand struct {
+		backend.DefaultCommand
+		name string
+	}
+
+	// PromptSaveAsCommand saves the view where the frontend prompts for.
+	PromptSaveAsCommand struct {
+		backend.DefaultCommand
+	}
+
+	// SaveAllCommand saves the views of the window which have a file.
+	SaveAllCommand struct {
+		backend.DefaultCommand
+	}
+)
+
+// Saves the view telling the user about errors.
+func saveAs(v *backend.View, name string) error {
+	if err := SaveAs(v, name); err != nil {
+		backend.GetEditor().Frontend().ErrorMessage(fmt.Sprintf("Failed to save %s: %s", name, err))
+		return err
+	}
+	return nil
+}
+
+// Saves the view where the frontend prompts for, doing nothing when the
+// prompt is canceled.
+func promptSaveAs(v *backend.View) error {
+	dir := ""
+	if name := v.FileName(); name != "" {
+		dir = filepath.Dir(name)
+	}
+	files := backend.GetEditor().Frontend().Prompt("Save file", dir, backend.PROMPT_SAVE_AS)
+	if len(files) == 0 {
+		return nil
+	}
+	return saveAs(v, files[0])
+}
+
+func (c *SaveCommand) Run(v *backend.View, e *backend.Edit) error {
+	if v.FileName() == "" {
+		return promptSaveAs(v)
+	}
+	return saveAs(v, v.FileName())
+}
+
+func (c *SaveAsCommand) Init(args backend.Args) error {
+	c.name, _ = args["name"].(string)
+	return nil
+}
+
+func (c *SaveAsCommand) Run(v *backend.View, e *backend.Edit) error {
+	if c.name == "" {
+		return fmt.Errorf("save_as needs a name")
+	}
+	return saveAs(v, c.name)
+}
+
+func (c *PromptSaveAsCommand) Run(v *backend.View, e *backend.Edit) error {
+	return promptSaveAs(v)
+}
+
+func (c *SaveAllCommand) Run(w *backend.Window) error {
+	for _, v := range WindowViews(w) {
+		if v.FileName() == "" || !v.IsDirty() {
+			continue
+		}
+		if err := saveAs(v, v.FileName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The save commands replace the backend's, they are registered once all the
+// packages registered theirs.
+func registerSaveCommands() {
+	ch := backend.GetEditor().CommandHandler()
+	cmds := map[string]interface{}{
+		"save":           &SaveCommand{},
+		"save_as":        &SaveAsCommand{},
+		"prompt_save_as": &PromptSaveAsCommand{},
+		"save_all":       &SaveAllCommand{},
+	}
+	for name, cmd := range cmds {
+		ch.Unregister(name)
+		if err := ch.Register(name, cmd); err != nil {
+			log.Warn("Failed to register command %s: %s", name, err)
+		}
+	}
+}
+
+func onEncodingClose(v *backend.View) {
+	formats.Lock()
+	delete(formats.m, v)
+	formats.Unlock()
+}
+
+func (o *View) Py_change_count() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(ChangeCount(o.data))
+}
+
+func (o *View) Py_encoding() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(Encoding(o.data))
+}
+
+func (o *View) Py_set_encoding(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	enc, err := pyStringArg(tu, nil, 0, "encoding_name", "")
+	if err != nil {
+		return nil, err
+	}
+	if err := SetEncoding(o.data, enc); err != nil {
+		return nil, err
+	}
+	return toPython(nil)
+}
+
+func (o *View) Py_line_endings() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(LineEndings(o.data))
+}
+
+func (o *View) Py_set_line_endings(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	le, err := pyStringArg(tu, nil, 0, "line_ending_name", "")
+	if err != nil {
+		return nil, err
+	}
+	if err := SetLineEndings(o.data, le); err != nil {
+		return nil, err
+	}
+	return toPython(nil)
+}
+
+func init() {
+	backend.OnInit.Add(registerSaveCommands)
+	backend.OnClose.Add(onEncodingClose)
+}
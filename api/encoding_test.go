@@ -0,0 +1,86 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/encoding"
+	"github.com/limetext/text"
+)
+
+func TestEncodingLoadSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encoding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "legacy.txt")
+	if err := ioutil.WriteFile(name, []byte("\x93caf\xe9\x94\r\nb\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.OpenFile(name, 0)
+
+	if enc := Encoding(v); enc != encoding.Windows1252 {
+		t.Errorf("Expected encoding %s, but got %s", encoding.Windows1252, enc)
+	}
+	if le := LineEndings(v); le != encoding.Windows {
+		t.Errorf("Expected line endings %s, but got %s", encoding.Windows, le)
+	}
+	if s := v.Substr(text.Region{A: 0, B: v.Size()}); s != "“café”\nb\n" {
+		t.Errorf("Expected the decoded text, but got %q", s)
+	}
+	if v.IsDirty() {
+		t.Error("Expected decoding not to make the view dirty")
+	}
+	// decoding doesn't count as a change
+	utf8Name := filepath.Join(dir, "utf8.txt")
+	if err := ioutil.WriteFile(utf8Name, []byte("“café”\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := ChangeCount(w.OpenFile(utf8Name, 0)), ChangeCount(v); got != exp {
+		t.Errorf("Expected change count %d, but got %d", exp, got)
+	}
+
+	// saved back the way it was loaded
+	if err := Save(v); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(name); err != nil {
+		t.Fatal(err)
+	} else if exp := []byte("\x93caf\xe9\x94\r\nb\r\n"); !bytes.Equal(data, exp) {
+		t.Errorf("Expected %q, but got %q", exp, data)
+	}
+
+	if err := SetEncoding(v, encoding.UTF16LEBOM); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLineEndings(v, encoding.Unix); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetEncoding(v, "EBCDIC"); err == nil {
+		t.Error("Expected an error setting an unknown encoding")
+	}
+	if err := Save(v); err != nil {
+		t.Fatal(err)
+	}
+	if v.IsDirty() {
+		t.Error("Expected the saved view not to be dirty")
+	}
+	exp, _ := encoding.Encode("“café”\nb\n", encoding.UTF16LEBOM)
+	if data, err := ioutil.ReadFile(name); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(data, exp) {
+		t.Errorf("Expected %q, but got %q", exp, data)
+	}
+}
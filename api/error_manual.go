@@ -0,0 +1,204 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+)
+
+type (
+	// Error is an exception raised by python code. Python commands return
+	// it to the backend, errors of events and callbacks are passed to
+	// OnError.
+	Error struct {
+		// Type is the name of the exception class, e.g. "ValueError".
+		Type    string
+		Message string
+		// Traceback is formatted the way python prints it, it's empty for
+		// exceptions raised outside of callPython.
+		Traceback string
+	}
+
+	// ErrorEvent is called with the errors of python code that has no
+	// caller to return them to.
+	ErrorEvent []func(err *Error)
+)
+
+// OnError is called with the errors of python events and callbacks.
+var OnError ErrorEvent
+
+// Python errors lose their traceback once they get to go, so the calls go
+// through this function catching the exceptions.
+const callSource = `
+import sys, traceback
+
+def call(f, *args):
+    try:
+        return f(*args), None
+    except:
+        t, v, tb = sys.exc_info()
+        return None, (t.__name__, str(v), "".join(traceback.format_exception(t, v, tb)))
+`
+
+var pyCall py.Object
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Type
+	}
+	return e.Type + ": " + e.Message
+}
+
+// Add adds a listener to the event.
+func (e *ErrorEvent) Add(cb func(err *Error)) {
+	*e = append(*e, cb)
+}
+
+// Call calls all the listeners of the event.
+func (e ErrorEvent) Call(err *Error) {
+	for _, cb := range e {
+		cb(err)
+	}
+}
+
+// Converts the errors of python calls to *Error.
+func pyError(err error) error {
+	switch t := err.(type) {
+	case *Error:
+		return t
+	case *py.Exception:
+		e := &Error{Type: "Exception", Message: t.Error()}
+		if t.Kind == nil {
+			return e
+		}
+		if name, err := t.Kind.Base().GetAttrString("__name__"); err == nil {
+			if u, ok := name.(*py.Unicode); ok {
+				e.Type = u.String()
+				e.Message = strings.TrimPrefix(e.Message, e.Type+": ")
+			}
+			name.Decref()
+		}
+		return e
+	}
+	return err
+}
+
+// Logs the error of python code nothing returns it from, passing it to
+// OnError.
+func reportError(err error) {
+	err = pyError(err)
+	e, ok := err.(*Error)
+	if !ok {
+		log.Error(err)
+		return
+	}
+	if e.Traceback != "" {
+		log.Error(e.Traceback)
+	} else {
+		log.Error(e)
+	}
+	OnError.Call(e)
+}
+
+// Returns the python call function, compiling it the first time.
+func caller() (py.Object, error) {
+	if pyCall != nil {
+		return pyCall, nil
+	}
+	builtins, err := py.Import("builtins")
+	if err != nil {
+		return nil, err
+	}
+	defer builtins.Decref()
+	exec, err := builtins.Base().GetAttrString("exec")
+	if err != nil {
+		return nil, err
+	}
+	defer exec.Decref()
+	src, err := py.NewUnicode(callSource)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Decref()
+	globals, err := py.NewDict()
+	if err != nil {
+		return nil, err
+	}
+	defer globals.Decref()
+	if ret, err := exec.Base().CallFunctionObjArgs(src, globals); err != nil {
+		return nil, err
+	} else {
+		ret.Decref()
+	}
+	call, err := globals.GetItemString("call")
+	if err != nil {
+		return nil, err
+	}
+	call.Incref()
+	pyCall = call
+	return pyCall, nil
+}
+
+// Calls the python function, an exception it raises is returned as *Error
+// with its traceback. It must be called with the python lock held.
+func callPython(f py.Object, args ...py.Object) (py.Object, error) {
+	call, err := caller()
+	if err != nil {
+		return nil, pyError(err)
+	}
+	ret, err := call.Base().CallFunctionObjArgs(append([]py.Object{f}, args...)...)
+	if err != nil {
+		return nil, pyError(err)
+	}
+	defer ret.Decref()
+	tu, ok := ret.(*py.Tuple)
+	if !ok || tu.Size() != 2 {
+		return nil, fmt.Errorf("Unexpected result of calling %v: %v", f, ret)
+	}
+	res, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	exc, err := tu.GetItem(1)
+	if err != nil {
+		return nil, err
+	}
+	if _, none := exc.(*py.NoneObject); !none {
+		return nil, excError(exc)
+	}
+	res.Incref()
+	return res, nil
+}
+
+// Calls the method of the python object like callPython.
+func callMethod(o py.Object, name string, args ...py.Object) (py.Object, error) {
+	m, err := o.Base().GetAttrString(name)
+	if err != nil {
+		return nil, pyError(err)
+	}
+	defer m.Decref()
+	return callPython(m, args...)
+}
+
+// Converts the (type, message, traceback) tuple of call to *Error.
+func excError(exc py.Object) error {
+	v, err := fromPython(exc)
+	if err != nil {
+		return err
+	}
+	t, ok := v.(Tuple)
+	if !ok || len(t) != 3 {
+		return fmt.Errorf("Unexpected exception %v", v)
+	}
+	e := &Error{}
+	e.Type, _ = t[0].(string)
+	e.Message, _ = t[1].(string)
+	e.Traceback, _ = t[2].(string)
+	return e
+}
@@ -42,8 +42,8 @@ type (
 )
 
 var evmap = map[string]*backend.ViewEvent{
-	"on_new":                &backend.OnNew,
-	"on_load":               &backend.OnLoad,
+	"on_new":                &OnNewFile,
+	"on_load":               &OnLoaded,
 	"on_activated":          &backend.OnActivated,
 	"on_deactivated":        &backend.OnDeactivated,
 	"on_pre_close":          &backend.OnPreClose,
@@ -89,8 +89,8 @@ func (c *ViewEventGlue) onEvent(v *backend.View) {
 	defer pv.Decref()
 	log.Fine("onEvent: %v, %v, %v", c, c.inner, pv)
 
-	if ret, err := c.inner.Base().CallFunctionObjArgs(pv); err != nil {
-		log.Error(err)
+	if ret, err := callPython(c.inner, pv); err != nil {
+		reportError(err)
 	} else if ret != nil {
 		ret.Decref()
 	}
@@ -150,8 +150,8 @@ func (c *OnQueryContextGlue) onQueryContext(v *backend.View, key string, operato
 	}
 	defer pm.Decref()
 
-	if ret, err = c.inner.Base().CallFunctionObjArgs(pv, pk, po, poa, pm); err != nil {
-		log.Error(err)
+	if ret, err = callPython(c.inner, pv, pk, po, poa, pm); err != nil {
+		reportError(err)
 		return backend.Unknown
 	}
 	defer ret.Decref()
@@ -0,0 +1,168 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+	"github.com/limetext/rubex"
+	"github.com/limetext/sublime/selector"
+	"github.com/limetext/sublime/snippet"
+	"github.com/limetext/text"
+	"github.com/quarnster/parser"
+)
+
+// FindAll returns the regions of the view matching the pattern, flags are
+// backend.LITERAL and backend.IGNORECASE. When format isn't empty the format
+// expanded with the groups of every match is returned as well. Patterns are
+// Oniguruma regexes like the ones of View.Find.
+func FindAll(v *backend.View, pattern string, flags int, format string) ([]text.Region, []string, error) {
+	if flags&backend.LITERAL != 0 {
+		pattern = rubex.QuoteMeta(pattern)
+	}
+	if flags&backend.IGNORECASE != 0 {
+		pattern = "(?i)" + pattern
+	}
+	re, err := rubex.Compile(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := v.Substr(text.Region{A: 0, B: v.Size()})
+	var (
+		regions     []text.Region
+		extractions []string
+		// byte offset converted to runes last and its rune offset
+		last, pos int
+	)
+	runes := func(b int) int {
+		pos += utf8.RuneCountInString(data[last:b])
+		last = b
+		return pos
+	}
+	for _, m := range re.FindAllStringSubmatchIndex(data, -1) {
+		a := runes(m[0])
+		regions = append(regions, text.Region{A: a, B: runes(m[1])})
+		if format == "" {
+			continue
+		}
+		groups := make([]string, len(m)/2)
+		for i := range groups {
+			if m[2*i] >= 0 {
+				groups[i] = data[m[2*i]:m[2*i+1]]
+			}
+		}
+		extractions = append(extractions, snippet.Format(format, groups))
+	}
+	return regions, extractions, nil
+}
+
+// FindBySelector returns the regions of the view whose scope matches the
+// selector, adjacent regions are merged. Scopes are matched at the leaves of
+// the scope tree, so the children a selector excludes aren't returned with
+// their parents.
+func FindBySelector(v *backend.View, sel string) ([]text.Region, error) {
+	return selectorRegions(viewTree(v), sel)
+}
+
+// Returns the regions of the scope tree matching the selector.
+func selectorRegions(root *parser.Node, sel string) ([]text.Region, error) {
+	s, err := selector.Compile(sel)
+	if err != nil {
+		return nil, err
+	}
+	var ret []text.Region
+	add := func(a, b int, scope string) {
+		if a >= b || !s.Match(scope) {
+			return
+		}
+		if l := len(ret) - 1; l >= 0 && ret[l].B == a {
+			ret[l].B = b
+		} else {
+			ret = append(ret, text.Region{A: a, B: b})
+		}
+	}
+	var walk func(n *parser.Node, scope string)
+	walk = func(n *parser.Node, scope string) {
+		if n.Name != "" {
+			if scope != "" {
+				scope += " "
+			}
+			scope += n.Name
+		}
+		// the text between the children has the scope of the node
+		pos := n.Range.Begin()
+		for _, c := range n.Children {
+			add(pos, c.Range.Begin(), scope)
+			walk(c, scope)
+			pos = c.Range.End()
+		}
+		add(pos, n.Range.End(), scope)
+	}
+	walk(root, "")
+	return ret, nil
+}
+
+func (o *View) Py_find_all(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	pattern, err := pyStringArg(tu, kw, 0, "pattern", "")
+	if err != nil {
+		return nil, err
+	}
+	flags, err := pyIntArg(tu, kw, 1, "flags", 0)
+	if err != nil {
+		return nil, err
+	}
+	format := ""
+	if v, ok := pyArg(tu, kw, 2, "fmt"); ok && v != py.None {
+		if format, err = pyStringArg(tu, kw, 2, "fmt", ""); err != nil {
+			return nil, err
+		}
+	}
+	var extractions *py.List
+	if v, ok := pyArg(tu, kw, 3, "extractions"); ok && v != py.None {
+		if extractions, ok = v.(*py.List); !ok {
+			return nil, fmt.Errorf("Expected type list for extractions, not %s", v.Type())
+		}
+	}
+
+	regions, strs, err := FindAll(o.data, pattern, flags, format)
+	if err != nil {
+		return nil, err
+	}
+	if extractions != nil {
+		for _, s := range strs {
+			ps, err := toPython(s)
+			if err != nil {
+				return nil, err
+			}
+			err = extractions.Append(ps)
+			ps.Decref()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return regionsToPython(regions)
+}
+
+func (o *View) Py_find_by_selector(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	sel, err := pyStringArg(tu, nil, 0, "selector", "")
+	if err != nil {
+		return nil, err
+	}
+	regions, err := FindBySelector(o.data, sel)
+	if err != nil {
+		return nil, err
+	}
+	return regionsToPython(regions)
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/text"
+	"github.com/quarnster/parser"
+)
+
+func TestSelectorRegions(t *testing.T) {
+	// a = 1 // one
+	root := &parser.Node{
+		Name:  "source.go",
+		Range: text.Region{A: 0, B: 15},
+		Children: []*parser.Node{
+			{Name: "constant.numeric", Range: text.Region{A: 4, B: 5}},
+			{
+				Name:  "comment.line",
+				Range: text.Region{A: 6, B: 15},
+				Children: []*parser.Node{
+					{Name: "punctuation.definition.comment", Range: text.Region{A: 6, B: 8}},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		sel string
+		exp []text.Region
+	}{
+		{"source", []text.Region{{A: 0, B: 15}}},
+		{"source - comment", []text.Region{{A: 0, B: 6}}},
+		{"comment - punctuation", []text.Region{{A: 8, B: 15}}},
+		{"constant, punctuation", []text.Region{{A: 4, B: 5}, {A: 6, B: 8}}},
+		{"string", nil},
+	}
+	for i, test := range tests {
+		got, err := selectorRegions(root, test.sel)
+		if err != nil {
+			t.Errorf("Test %d: Error finding %s: %s", i, test.sel, err)
+		} else if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("Test %d: Expected %v, but got %v", i, test.exp, got)
+		}
+	}
+}
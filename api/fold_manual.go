@@ -0,0 +1,321 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/fold"
+	"github.com/limetext/text"
+)
+
+// Keeps the folds of a view in place as its buffer changes
+type foldObserver struct {
+	folds *fold.Folds
+}
+
+func (o *foldObserver) Inserted(b text.Buffer, r text.Region, data []rune) {
+	o.folds.Inserted(r.Begin(), len(data))
+}
+
+func (o *foldObserver) Erased(b text.Buffer, r text.Region, data []rune) {
+	o.folds.Erased(r)
+}
+
+var (
+	folds = struct {
+		sync.Mutex
+		m map[*backend.View]*foldObserver
+	}{m: make(map[*backend.View]*foldObserver)}
+
+	// OnFoldsChanged is called when the folded regions of a view change,
+	// frontends hide the folded text.
+	OnFoldsChanged backend.ViewEvent
+)
+
+// FoldsOf returns the folds of the view.
+func FoldsOf(v *backend.View) *fold.Folds {
+	folds.Lock()
+	defer folds.Unlock()
+	if o, ok := folds.m[v]; ok {
+		return o.folds
+	}
+	o := &foldObserver{folds: new(fold.Folds)}
+	if err := v.AddObserver(o); err != nil {
+		log.Warn("Error observing %s for folds: %s", v.FileName(), err)
+	}
+	folds.m[v] = o
+	return o.folds
+}
+
+func onFoldsClose(v *backend.View) {
+	folds.Lock()
+	o := folds.m[v]
+	delete(folds.m, v)
+	folds.Unlock()
+	if o != nil {
+		v.RemoveObserver(o)
+	}
+}
+
+// Fold folds the regions of the view, it returns false when all of them are
+// folded already.
+func Fold(v *backend.View, regions ...text.Region) bool {
+	f, ret := FoldsOf(v), false
+	for _, r := range regions {
+		if f.Fold(r) {
+			ret = true
+		}
+	}
+	if ret {
+		OnFoldsChanged.Call(v)
+	}
+	return ret
+}
+
+// Unfold unfolds the folds of the view intersecting the regions and returns
+// them.
+func Unfold(v *backend.View, regions ...text.Region) []text.Region {
+	f := FoldsOf(v)
+	var ret []text.Region
+	for _, r := range regions {
+		ret = append(ret, f.Unfold(r)...)
+	}
+	if len(ret) != 0 {
+		OnFoldsChanged.Call(v)
+	}
+	return ret
+}
+
+func tabSize(v *backend.View) int {
+	return v.Settings().Int("tab_size", 4)
+}
+
+// FoldRanges returns the ranges of the view which could be folded, they come
+// from the begin and end patterns of the syntax and from the indentation when
+// the syntax has none.
+func FoldRanges(v *backend.View) []text.Region {
+	if ranges := fold.SyntaxRanges(viewTree(v)); len(ranges) != 0 {
+		return ranges
+	}
+	return fold.IndentRanges(v.Substr(text.Region{A: 0, B: v.Size()}), tabSize(v))
+}
+
+// IndentationLevel returns the indentation level of the line containing pt.
+func IndentationLevel(v *backend.View, pt int) int {
+	return fold.Level(v.Substr(v.Line(pt)), tabSize(v))
+}
+
+// IndentedRegion returns the lines around pt which are indented at least as
+// much as the line of pt.
+func IndentedRegion(v *backend.View, pt int) text.Region {
+	return fold.IndentedRegion(v.Substr(text.Region{A: 0, B: v.Size()}), pt, tabSize(v))
+}
+
+type (
+	// FoldCommand folds the selections, empty selections fold the innermost
+	// fold range containing them.
+	FoldCommand struct {
+		backend.DefaultCommand
+	}
+
+	// UnfoldCommand unfolds the folds intersecting the selections.
+	UnfoldCommand struct {
+		backend.DefaultCommand
+	}
+
+	// UnfoldAllCommand unfolds all the folds of the view.
+	UnfoldAllCommand struct {
+		backend.DefaultCommand
+	}
+
+	// FoldByLevelCommand folds the indented blocks at the given level.
+	FoldByLevelCommand struct {
+		backend.DefaultCommand
+		level int
+	}
+)
+
+func (c *FoldCommand) Run(v *backend.View, e *backend.Edit) error {
+	var (
+		regions []text.Region
+		ranges  []text.Region
+	)
+	for _, r := range v.Sel().Regions() {
+		if !r.Empty() {
+			regions = append(regions, r)
+			continue
+		}
+		if ranges == nil {
+			ranges = FoldRanges(v)
+		}
+		// ranges are ordered with the outer ones first
+		var inner *text.Region
+		for i := range ranges {
+			rng := ranges[i]
+			if rng.Begin() <= r.B && r.B <= rng.End() && !FoldsOf(v).IsFolded(rng) {
+				inner = &ranges[i]
+			}
+		}
+		if inner != nil {
+			regions = append(regions, *inner)
+		}
+	}
+	Fold(v, regions...)
+	return nil
+}
+
+func (c *UnfoldCommand) Run(v *backend.View, e *backend.Edit) error {
+	Unfold(v, v.Sel().Regions()...)
+	return nil
+}
+
+func (c *UnfoldAllCommand) Run(v *backend.View, e *backend.Edit) error {
+	if len(FoldsOf(v).UnfoldAll()) != 0 {
+		OnFoldsChanged.Call(v)
+	}
+	return nil
+}
+
+func (c *FoldByLevelCommand) Init(args backend.Args) error {
+	switch l := args["level"].(type) {
+	case int:
+		c.level = l
+	case float64:
+		c.level = int(l)
+	default:
+		c.level = 1
+	}
+	return nil
+}
+
+func (c *FoldByLevelCommand) Run(v *backend.View, e *backend.Edit) error {
+	var regions []text.Region
+	for _, r := range fold.IndentRanges(v.Substr(text.Region{A: 0, B: v.Size()}), tabSize(v)) {
+		if IndentationLevel(v, r.A) == c.level-1 {
+			regions = append(regions, r)
+		}
+	}
+	Fold(v, regions...)
+	return nil
+}
+
+// Returns the regions of a Region or a list of Regions
+func regionsArg(tu *py.Tuple, name string) ([]text.Region, error) {
+	v, ok := pyArg(tu, nil, 0, name)
+	if !ok {
+		return nil, fmt.Errorf("Missing %s argument", name)
+	}
+	r, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	switch t := r.(type) {
+	case text.Region:
+		return []text.Region{t}, nil
+	case List:
+		ret := make([]text.Region, len(t))
+		for i, it := range t {
+			r, ok := it.(text.Region)
+			if !ok {
+				return nil, fmt.Errorf("Expected list of Regions, not %v", t)
+			}
+			ret[i] = r
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("Expected Region or list of Regions, not %s", v.Type())
+}
+
+func regionsToPython(regions []text.Region) (py.Object, error) {
+	ret := make(List, len(regions))
+	for i, r := range regions {
+		ret[i] = r
+	}
+	return toPython(ret)
+}
+
+func (o *View) Py_fold(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	regions, err := regionsArg(tu, "x")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(Fold(o.data, regions...))
+}
+
+func (o *View) Py_unfold(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	regions, err := regionsArg(tu, "x")
+	if err != nil {
+		return nil, err
+	}
+	return regionsToPython(Unfold(o.data, regions...))
+}
+
+func (o *View) Py_is_folded(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	regions, err := regionsArg(tu, "region")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(FoldsOf(o.data).IsFolded(regions[0]))
+}
+
+func (o *View) Py_folded_regions() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return regionsToPython(FoldsOf(o.data).Regions())
+}
+
+func (o *View) Py_indentation_level(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	pt, err := pyIntArg(tu, nil, 0, "pt", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(IndentationLevel(o.data, pt))
+}
+
+func (o *View) Py_indented_region(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	pt, err := pyIntArg(tu, nil, 0, "pt", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(IndentedRegion(o.data, pt))
+}
+
+func init() {
+	backend.OnClose.Add(onFoldsClose)
+
+	ch := backend.GetEditor().CommandHandler()
+	cmds := map[string]interface{}{
+		"fold":          &FoldCommand{},
+		"unfold":        &UnfoldCommand{},
+		"unfold_all":    &UnfoldAllCommand{},
+		"fold_by_level": &FoldByLevelCommand{},
+	}
+	for name, cmd := range cmds {
+		if err := ch.Register(name, cmd); err != nil {
+			log.Warn("Failed to register command %s: %s", name, err)
+		}
+	}
+}
@@ -112,6 +112,11 @@ func generatemethod(m reflect.Method, t2 reflect.Type, callobject, name string)
 	}
 
 	ret += fmt.Sprintf("\nfunc %s (%s) %s {", name, args, rv)
+	// handles of closed views fail instead of touching the view, except
+	// for their id
+	if strings.Contains(name, "(o *View)") && rv == "(py.Object, error)" && !strings.HasSuffix(name, " Py_id") {
+		ret += "\nif err := o.check(); err != nil {\nreturn nil, err\n}"
+	}
 
 	if m.Name == "Get" && in == 1 && m.Type.In(1).Kind() == reflect.Int && out == 1 {
 		ret += `var (
@@ -297,12 +302,14 @@ func generateWrapper(ptr reflect.Type, canCreate bool, ignorefunc func(name stri
 
 		type %s struct {
 			py.BaseObject
-			data %s
+			data %s%s
 		}
-		`, pyname(t.Name()), t.Name(), t.Name(), t.Name(), it)
+		`, pyname(t.Name()), t.Name(), t.Name(), t.Name(), it, extraFields[t.Name()])
 
 	cons := ""
-	if canCreate {
+	// types with extra fields initialize them in a manual PyInit
+	manualInit := ignorefunc != nil && ignorefunc("PyInit")
+	if canCreate && !manualInit {
 		cons = fmt.Sprintf(`
 			func (o *%s) PyInit(args *py.Tuple, kwds *py.Dict) error {
 				if args.Size() > %d {
@@ -332,7 +339,7 @@ func generateWrapper(ptr reflect.Type, canCreate bool, ignorefunc func(name stri
 			cons += "\n\treturn nil\n}"
 		}
 	}
-	if cons == "" {
+	if cons == "" && !manualInit {
 		ret += fmt.Sprintf(`
 			func (o *%s) PyInit(args *py.Tuple, kwds *py.Dict) error {
 				return fmt.Errorf("Can't initialize type %s")
@@ -372,6 +379,11 @@ func generateWrapper(ptr reflect.Type, canCreate bool, ignorefunc func(name stri
 	return
 }
 
+// The fields of the wrappers besides the wrapped data.
+var extraFields = map[string]string{
+	"Region": "\nxpos int",
+}
+
 var (
 	remove      = regexp.MustCompile(`^.+_generated\.go$`)
 	sublimepath string
@@ -409,15 +421,15 @@ func main() {
 	}
 
 	data := [][]string{
-		{path.Join(sublimepath, "region_generated.go"), generateWrapper(reflect.TypeOf(text.Region{}), true, regexp.MustCompile("Cut|Clip|Covers").MatchString)},
-		{path.Join(sublimepath, "regionset_generated.go"), generateWrapper(reflect.TypeOf(&text.RegionSet{}), false, regexp.MustCompile("Less|Swap|Adjust|Has|Cut|Regions").MatchString)},
+		{path.Join(sublimepath, "region_generated.go"), generateWrapper(reflect.TypeOf(text.Region{}), true, regexp.MustCompile("Cut|Clip|Covers|Contains|Intersect|PyInit").MatchString)},
+		{path.Join(sublimepath, "regionset_generated.go"), generateWrapper(reflect.TypeOf(&text.RegionSet{}), false, regexp.MustCompile("Less|Swap|Adjust|Has|Cut|Regions|Add|Contains|Subtract").MatchString)},
 		{path.Join(sublimepath, "edit_generated.go"), generateWrapper(reflect.TypeOf(&backend.Edit{}), false, regexp.MustCompile("Apply|Undo").MatchString)},
-		{path.Join(sublimepath, "view_generated.go"), generateWrapper(reflect.TypeOf(&backend.View{}), false, regexp.MustCompile("Buffer|Syntax|CommandHistory|Show|AddRegions|UndoStack|Transform|Reload|Save|Close|ExpandByClass|Erased|FileChanged|Inserted|Find$|^Status|Word|Line|Substr|FullLine|ChangeCount|FileName|^Name|RowCol|SetName|Size|TextPoint|AddObserver").MatchString)},
-		{path.Join(sublimepath, "window_generated.go"), generateWrapper(reflect.TypeOf(&backend.Window{}), false, regexp.MustCompile("OpenFile|SetActiveView|Close|Project$").MatchString)},
-		{path.Join(sublimepath, "settings_generated.go"), generateWrapper(reflect.TypeOf(&text.Settings{}), false, regexp.MustCompile("Parent|Set|Get|UnmarshalJSON|MarshalJSON|Int|Bool|String|Id").MatchString)},
+		{path.Join(sublimepath, "view_generated.go"), generateWrapper(reflect.TypeOf(&backend.View{}), false, regexp.MustCompile("Buffer|Syntax|CommandHistory|Show|AddRegions|UndoStack|Transform|Reload|Save|Close|ExpandByClass|Erased|FileChanged|Inserted|Find$|^Status|Word|Line|Substr|FullLine|ChangeCount|FileName|^Name|RowCol|SetName|Size|TextPoint|AddObserver|ScoreSelector|^Insert$|^Erase$|^Replace$|^BeginEdit$|^EndEdit$").MatchString)},
+		{path.Join(sublimepath, "window_generated.go"), generateWrapper(reflect.TypeOf(&backend.Window{}), false, regexp.MustCompile("OpenFile|SetActiveView|Close|Project$|^Views$").MatchString)},
+		{path.Join(sublimepath, "settings_generated.go"), generateWrapper(reflect.TypeOf(&text.Settings{}), false, regexp.MustCompile("Parent|Set|Get|UnmarshalJSON|MarshalJSON|Int|Bool|String|Id|AddOnChange|ClearOnChange").MatchString)},
 		{path.Join(sublimepath, "view_buffer_generated.go"), generatemethodsEx(
 			reflect.TypeOf(text.NewBuffer()),
-			regexp.MustCompile("Erase|Insert|Substr|SetFile|AddCallback|AddObserver|RemoveObserver|Data|Runes|Settings|Index|Close|Unlock|Lock|String").MatchString,
+			regexp.MustCompile("Erase|Insert|Substr|SetFile|AddCallback|AddObserver|RemoveObserver|Data|Runes|Settings|Index|Close|Unlock|Lock|String|ChangeCount").MatchString,
 			"o.data.",
 			func(t reflect.Type, m reflect.Method) string {
 				mn := ""
@@ -431,13 +443,8 @@ func main() {
 				}
 				return "(o *View) " + mn
 			})},
-		{path.Join(sublimepath, "commands_generated.go"), generatemethodsEx(reflect.TypeOf(backend.GetEditor().CommandHandler()),
-			regexp.MustCompile("RunWindowCommand|RunTextCommand|RunApplicationCommand|RegisterWithDefault").MatchString,
-			"backend.GetEditor().CommandHandler().",
-			sn),
-		},
 		{path.Join(sublimepath, "sublime_generated.go"), generatemethodsEx(reflect.TypeOf(backend.GetEditor()),
-			regexp.MustCompile("Info|HandleInput|CommandHandler|Console|Frontend|SetActiveWindow|Init|Watch|Observe|SetClipboardFuncs|DefaultPath|UserPath|AddPackagesPath|RemovePackagesPath|KeyBindings|ColorScheme|Syntax|[lL]ock$|Settings|^Plat$|NewWindow|Close|^Clipboard$|UseClipboard").MatchString,
+			regexp.MustCompile("Info|HandleInput|CommandHandler|Console|Frontend|SetActiveWindow|Init|Watch|Observe|SetClipboardFuncs|DefaultPath|UserPath|AddPackagesPath|RemovePackagesPath|KeyBindings|ColorScheme|Syntax|[lL]ock$|Settings|^Plat$|NewWindow|Close|^Clipboard$|UseClipboard|LogCommands|^RunCommand$").MatchString,
 			"backend.GetEditor().",
 			sn),
 		},
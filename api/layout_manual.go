@@ -0,0 +1,505 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/sheet"
+)
+
+type (
+	// Layout describes how the groups of a window are placed, the same way
+	// sublime does. Cols and Rows are increasing fractions of the window
+	// width and height from 0 to 1, each cell is a group and is given as
+	// [x1, y1, x2, y2] indexes into Cols and Rows.
+	Layout struct {
+		Cols  []float64
+		Rows  []float64
+		Cells [][4]int
+	}
+
+	// LayoutFrontend is implemented by frontends that are able to show
+	// multiple groups.
+	LayoutFrontend interface {
+		SetLayout(w *backend.Window, l Layout)
+	}
+
+	// Keeps track of the window groups and which sheets are in which group
+	windowLayout struct {
+		layout Layout
+		groups [][]*sheet.Sheet
+		// active sheet of each group
+		actives []*sheet.Sheet
+		active  int
+	}
+)
+
+var layouts = struct {
+	sync.Mutex
+	m map[*backend.Window]*windowLayout
+	// text sheets of the views
+	views map[*backend.View]*sheet.Sheet
+}{
+	m:     make(map[*backend.Window]*windowLayout),
+	views: make(map[*backend.View]*sheet.Sheet),
+}
+
+// DefaultLayout is the layout of a window with a single group.
+func DefaultLayout() Layout {
+	return Layout{
+		Cols:  []float64{0, 1},
+		Rows:  []float64{0, 1},
+		Cells: [][4]int{{0, 0, 1, 1}},
+	}
+}
+
+func (l Layout) validate() error {
+	if len(l.Cols) < 2 || len(l.Rows) < 2 {
+		return fmt.Errorf("Layout needs at least 2 cols and 2 rows")
+	}
+	if len(l.Cells) == 0 {
+		return fmt.Errorf("Layout needs at least 1 cell")
+	}
+	for _, fs := range [][]float64{l.Cols, l.Rows} {
+		for i := range fs {
+			if fs[i] < 0 || fs[i] > 1 || (i > 0 && fs[i] < fs[i-1]) {
+				return fmt.Errorf("Layout cols and rows should be increasing between 0 and 1: %v", fs)
+			}
+		}
+	}
+	for _, c := range l.Cells {
+		if c[0] < 0 || c[1] < 0 || c[2] >= len(l.Cols) || c[3] >= len(l.Rows) || c[0] >= c[2] || c[1] >= c[3] {
+			return fmt.Errorf("Invalid layout cell: %v", c)
+		}
+	}
+	return nil
+}
+
+// Returns the window layout after syncing text sheets with the window views,
+// new views are added to the active group and closed ones are removed.
+// layouts should be locked.
+func layoutOf(w *backend.Window) *windowLayout {
+	wl, ok := layouts.m[w]
+	if !ok {
+		wl = &windowLayout{
+			layout:  DefaultLayout(),
+			groups:  make([][]*sheet.Sheet, 1),
+			actives: make([]*sheet.Sheet, 1),
+		}
+		layouts.m[w] = wl
+	}
+
+	views := WindowViews(w)
+	exist := make(map[*backend.View]bool, len(views))
+	for _, v := range views {
+		exist[v] = true
+	}
+	for g := range wl.groups {
+		ss := wl.groups[g][:0]
+		for _, s := range wl.groups[g] {
+			if v := s.View(); v == nil || exist[v] {
+				ss = append(ss, s)
+				delete(exist, v)
+			} else {
+				delete(layouts.views, v)
+			}
+		}
+		wl.groups[g] = ss
+		wl.fixActive(g)
+	}
+	// remaining views in exist are new, keeping window order
+	for _, v := range views {
+		if exist[v] {
+			wl.groups[wl.active] = append(wl.groups[wl.active], viewSheet(v))
+		}
+	}
+	return wl
+}
+
+// Returns the text sheet of the view, layouts should be locked.
+func viewSheet(v *backend.View) *sheet.Sheet {
+	s, ok := layouts.views[v]
+	if !ok {
+		s = sheet.NewText(v)
+		layouts.views[v] = s
+	}
+	return s
+}
+
+// Makes sure the active sheet of the group is still in the group
+func (wl *windowLayout) fixActive(group int) {
+	if g, _ := wl.find(wl.actives[group]); g != group {
+		wl.actives[group] = nil
+		if ss := wl.groups[group]; len(ss) > 0 {
+			wl.actives[group] = ss[len(ss)-1]
+		}
+	}
+}
+
+func (wl *windowLayout) valid(group int) bool {
+	return group >= 0 && group < len(wl.groups)
+}
+
+func (wl *windowLayout) find(s *sheet.Sheet) (group, index int) {
+	if s == nil {
+		return -1, -1
+	}
+	for g := range wl.groups {
+		for i := range wl.groups[g] {
+			if wl.groups[g][i] == s {
+				return g, i
+			}
+		}
+	}
+	return -1, -1
+}
+
+func (wl *windowLayout) remove(s *sheet.Sheet) {
+	if g, i := wl.find(s); g != -1 {
+		wl.groups[g] = append(wl.groups[g][:i], wl.groups[g][i+1:]...)
+		wl.fixActive(g)
+	}
+}
+
+// Inserts the sheet at the index of the group and makes it the active sheet
+// of the group, the index is clamped to the group size.
+func (wl *windowLayout) insert(s *sheet.Sheet, group, index int) {
+	ss := wl.groups[group]
+	if index < 0 || index > len(ss) {
+		index = len(ss)
+	}
+	ss = append(ss, nil)
+	copy(ss[index+1:], ss[index:])
+	ss[index] = s
+	wl.groups[group] = ss
+	wl.actives[group] = s
+}
+
+// NumGroups returns the number of groups of the window.
+func NumGroups(w *backend.Window) int {
+	layouts.Lock()
+	defer layouts.Unlock()
+	return len(layoutOf(w).groups)
+}
+
+// ActiveGroup returns the index of the focused group.
+func ActiveGroup(w *backend.Window) int {
+	layouts.Lock()
+	defer layouts.Unlock()
+	return layoutOf(w).active
+}
+
+// FocusGroup makes the group active and focuses its active sheet.
+func FocusGroup(w *backend.Window, group int) {
+	layouts.Lock()
+	wl := layoutOf(w)
+	if !wl.valid(group) {
+		layouts.Unlock()
+		return
+	}
+	wl.active = group
+	s := wl.actives[group]
+	layouts.Unlock()
+
+	if s != nil {
+		FocusSheet(s)
+	}
+}
+
+// ViewsInGroup returns the views of the group in tab order.
+func ViewsInGroup(w *backend.Window, group int) []*backend.View {
+	var ret []*backend.View
+	for _, s := range SheetsInGroup(w, group) {
+		if v := s.View(); v != nil {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// ActiveViewInGroup returns the focused view of the group, nil if the group
+// is empty or its focused sheet isn't a text sheet.
+func ActiveViewInGroup(w *backend.Window, group int) *backend.View {
+	if s := ActiveSheetInGroup(w, group); s != nil {
+		return s.View()
+	}
+	return nil
+}
+
+// GetViewIndex returns the group of the view and its index in the group,
+// both are -1 if the view isn't in any group.
+func GetViewIndex(w *backend.Window, v *backend.View) (group, index int) {
+	layouts.Lock()
+	defer layouts.Unlock()
+	wl := layoutOf(w)
+	s, ok := layouts.views[v]
+	if !ok {
+		return -1, -1
+	}
+	return wl.find(s)
+}
+
+// SetViewIndex moves the view to the given group and index, the index is
+// clamped to the group size.
+func SetViewIndex(w *backend.Window, v *backend.View, group, index int) {
+	layouts.Lock()
+	layoutOf(w)
+	s, ok := layouts.views[v]
+	layouts.Unlock()
+	if ok {
+		SetSheetIndex(s, group, index)
+	}
+}
+
+// GetLayout returns the current layout of the window.
+func GetLayout(w *backend.Window) Layout {
+	layouts.Lock()
+	defer layouts.Unlock()
+	return layoutOf(w).layout
+}
+
+// SetLayout changes the layout of the window, if the new layout has fewer
+// groups the sheets of the removed groups are moved to the last group.
+func SetLayout(w *backend.Window, l Layout) error {
+	if err := l.validate(); err != nil {
+		return err
+	}
+	layouts.Lock()
+	wl := layoutOf(w)
+	n := len(l.Cells)
+	for len(wl.groups) > n {
+		last := len(wl.groups) - 1
+		wl.groups[n-1] = append(wl.groups[n-1], wl.groups[last]...)
+		if wl.actives[n-1] == nil {
+			wl.actives[n-1] = wl.actives[last]
+		}
+		wl.groups, wl.actives = wl.groups[:last], wl.actives[:last]
+	}
+	for len(wl.groups) < n {
+		wl.groups = append(wl.groups, nil)
+		wl.actives = append(wl.actives, nil)
+	}
+	if wl.active >= n {
+		wl.active = n - 1
+	}
+	wl.layout = l
+	layouts.Unlock()
+
+	if fe, ok := backend.GetEditor().Frontend().(LayoutFrontend); ok {
+		fe.SetLayout(w, l)
+	}
+	return nil
+}
+
+// Keeps the active group and the active sheet of the group in sync with the
+// focused view.
+func onLayoutActivated(v *backend.View) {
+	w := v.Window()
+	if w == nil {
+		return
+	}
+	layouts.Lock()
+	defer layouts.Unlock()
+	wl := layoutOf(w)
+	s := layouts.views[v]
+	if g, _ := wl.find(s); g != -1 {
+		wl.active = g
+		wl.actives[g] = s
+	}
+}
+
+// Removes the sheet of the closed view from the layouts, the layout of a
+// window is forgotten once it has no views or sheets left.
+func onLayoutClose(v *backend.View) {
+	layouts.Lock()
+	defer layouts.Unlock()
+	s, ok := layouts.views[v]
+	delete(layouts.views, v)
+	for w, wl := range layouts.m {
+		if ok {
+			wl.remove(s)
+		}
+		if wl.empty() && !hasOtherViews(w, v) {
+			delete(layouts.m, w)
+		}
+	}
+}
+
+func (wl *windowLayout) empty() bool {
+	for _, ss := range wl.groups {
+		if len(ss) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func hasOtherViews(w *backend.Window, v *backend.View) bool {
+	for _, v2 := range WindowViews(w) {
+		if v2 != v {
+			return true
+		}
+	}
+	return false
+}
+
+func (l Layout) toArgs() backend.Args {
+	cells := make(List, len(l.Cells))
+	for i, c := range l.Cells {
+		cells[i] = List{c[0], c[1], c[2], c[3]}
+	}
+	return backend.Args{
+		"cols":  l.Cols,
+		"rows":  l.Rows,
+		"cells": cells,
+	}
+}
+
+func layoutFromArgs(args backend.Args) (l Layout, err error) {
+	floats := func(key string) ([]float64, error) {
+		vs, ok := args[key].(List)
+		if !ok {
+			return nil, fmt.Errorf("Expected a list for layout %s", key)
+		}
+		ret := make([]float64, len(vs))
+		for i, v := range vs {
+			switch t := v.(type) {
+			case float64:
+				ret[i] = t
+			case int:
+				ret[i] = float64(t)
+			default:
+				return nil, fmt.Errorf("Expected numbers in layout %s", key)
+			}
+		}
+		return ret, nil
+	}
+	if l.Cols, err = floats("cols"); err != nil {
+		return
+	}
+	if l.Rows, err = floats("rows"); err != nil {
+		return
+	}
+	cells, ok := args["cells"].(List)
+	if !ok {
+		return l, fmt.Errorf("Expected a list for layout cells")
+	}
+	l.Cells = make([][4]int, len(cells))
+	for i, c := range cells {
+		c2, ok := c.(List)
+		if !ok || len(c2) != 4 {
+			return l, fmt.Errorf("Expected a list of 4 ints for layout cell %d", i)
+		}
+		for j := range c2 {
+			if l.Cells[i][j], ok = c2[j].(int); !ok {
+				return l, fmt.Errorf("Expected a list of 4 ints for layout cell %d", i)
+			}
+		}
+	}
+	return l, nil
+}
+
+func (o *Window) Py_num_groups() (py.Object, error) {
+	return toPython(NumGroups(o.data))
+}
+
+func (o *Window) Py_active_group() (py.Object, error) {
+	return toPython(ActiveGroup(o.data))
+}
+
+func (o *Window) Py_focus_group(tu *py.Tuple) (py.Object, error) {
+	group, err := pyIntArg(tu, nil, 0, "idx", 0)
+	if err != nil {
+		return nil, err
+	}
+	FocusGroup(o.data, group)
+	return toPython(nil)
+}
+
+func (o *Window) Py_views_in_group(tu *py.Tuple) (py.Object, error) {
+	group, err := pyIntArg(tu, nil, 0, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(ViewsInGroup(o.data, group))
+}
+
+func (o *Window) Py_active_view_in_group(tu *py.Tuple) (py.Object, error) {
+	group, err := pyIntArg(tu, nil, 0, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(ActiveViewInGroup(o.data, group))
+}
+
+func (o *Window) Py_get_view_index(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	v2, ok := v.(*View)
+	if !ok {
+		return nil, fmt.Errorf("Expected type View for get_view_index(), not %s", v.Type())
+	}
+	group, index := GetViewIndex(o.data, v2.data)
+	return toPython(Tuple{group, index})
+}
+
+func (o *Window) Py_set_view_index(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	v2, ok := v.(*View)
+	if !ok {
+		return nil, fmt.Errorf("Expected type View for set_view_index(), not %s", v.Type())
+	}
+	group, err := pyIntArg(tu, nil, 1, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	index, err := pyIntArg(tu, nil, 2, "idx", -1)
+	if err != nil {
+		return nil, err
+	}
+	SetViewIndex(o.data, v2.data, group, index)
+	return toPython(nil)
+}
+
+func (o *Window) Py_get_layout() (py.Object, error) {
+	return toPython(GetLayout(o.data).toArgs())
+}
+
+func (o *Window) Py_set_layout(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	args, ok := v2.(backend.Args)
+	if !ok {
+		return nil, fmt.Errorf("Expected type dict for set_layout(), not %s", v.Type())
+	}
+	l, err := layoutFromArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := SetLayout(o.data, l); err != nil {
+		return nil, err
+	}
+	return toPython(nil)
+}
+
+func init() {
+	backend.OnActivated.Add(onLayoutActivated)
+	backend.OnClose.Add(onLayoutClose)
+}
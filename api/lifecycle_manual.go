@@ -0,0 +1,237 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+)
+
+var (
+	// OnLoaded is called once the file of a view is loaded and decoded,
+	// python on_load listeners are called by it rather than by
+	// backend.OnLoad.
+	OnLoaded backend.ViewEvent
+
+	lifecycle = struct {
+		sync.Mutex
+		// closed views are kept by id so their memory can be freed
+		closed map[backend.Id]bool
+		// the number of files being opened by OpenFile in each window
+		opening  map[*backend.Window]int
+		loading  map[*backend.View]bool
+		readOnly map[*backend.View]bool
+	}{
+		closed:   make(map[backend.Id]bool),
+		opening:  make(map[*backend.Window]int),
+		loading:  make(map[*backend.View]bool),
+		readOnly: make(map[*backend.View]bool),
+	}
+)
+
+// IsValid reports whether the view is still open.
+func IsValid(v *backend.View) bool {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	return !lifecycle.closed[v.Id()]
+}
+
+// IsLoading reports whether the file of the view is still being loaded,
+// that is the view was created by OpenFile and its file wasn't loaded yet.
+func IsLoading(v *backend.View) bool {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	return lifecycle.loading[v]
+}
+
+// Marks the views the window creates until endOpening as loading.
+func startOpening(w *backend.Window) {
+	lifecycle.Lock()
+	lifecycle.opening[w]++
+	lifecycle.Unlock()
+}
+
+func endOpening(w *backend.Window) {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	if lifecycle.opening[w]--; lifecycle.opening[w] <= 0 {
+		delete(lifecycle.opening, w)
+	}
+}
+
+// IsReadOnly reports whether edits of the view are rejected.
+func IsReadOnly(v *backend.View) bool {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	return lifecycle.readOnly[v]
+}
+
+// SetReadOnly changes whether edits of the view are rejected.
+func SetReadOnly(v *backend.View, ro bool) {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	if ro {
+		lifecycle.readOnly[v] = true
+	} else {
+		delete(lifecycle.readOnly, v)
+	}
+}
+
+// CheckEdit returns an error when the view is read only. The backend has no
+// read only views, so python edits and the commands of this package editing
+// views call it before applying their edits.
+func CheckEdit(v *backend.View) error {
+	if IsReadOnly(v) {
+		return fmt.Errorf("View %d is read only", v.Id())
+	}
+	return nil
+}
+
+func onLifecycleNew(v *backend.View) {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	if w := v.Window(); w != nil && lifecycle.opening[w] > 0 {
+		lifecycle.loading[v] = true
+	}
+}
+
+func onLifecycleLoad(v *backend.View) {
+	onEncodingLoad(v)
+	lifecycle.Lock()
+	delete(lifecycle.loading, v)
+	lifecycle.Unlock()
+	OnLoaded.Call(v)
+}
+
+func onLifecycleClose(v *backend.View) {
+	lifecycle.Lock()
+	defer lifecycle.Unlock()
+	lifecycle.closed[v.Id()] = true
+	delete(lifecycle.loading, v)
+	delete(lifecycle.readOnly, v)
+}
+
+// Returns an error for handles of closed views, their state is gone.
+func (o *View) check() error {
+	if !IsValid(o.data) {
+		return fmt.Errorf("View %d is closed", o.data.Id())
+	}
+	return nil
+}
+
+// Returns an error when the view can't be edited.
+func (o *View) checkEdit() error {
+	return CheckEdit(o.data)
+}
+
+func (o *View) Py_insert(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	e, err := editArg(tu, 0)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := pyIntArg(tu, nil, 1, "pt", 0)
+	if err != nil {
+		return nil, err
+	}
+	s, err := pyStringArg(tu, nil, 2, "string", "")
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkEdit(); err != nil {
+		return nil, err
+	}
+	return toPython(o.data.Insert(e, pt, s))
+}
+
+func (o *View) Py_erase(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	e, err := editArg(tu, 0)
+	if err != nil {
+		return nil, err
+	}
+	r, err := regionArg(tu, 1, "region")
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkEdit(); err != nil {
+		return nil, err
+	}
+	o.data.Erase(e, r)
+	return toPython(nil)
+}
+
+func (o *View) Py_replace(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	e, err := editArg(tu, 0)
+	if err != nil {
+		return nil, err
+	}
+	r, err := regionArg(tu, 1, "region")
+	if err != nil {
+		return nil, err
+	}
+	s, err := pyStringArg(tu, nil, 2, "string", "")
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkEdit(); err != nil {
+		return nil, err
+	}
+	o.data.Replace(e, r, s)
+	return toPython(nil)
+}
+
+func (o *View) Py_set_read_only(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	v, ok := pyArg(tu, nil, 0, "value")
+	if !ok {
+		return nil, fmt.Errorf("Missing value argument")
+	}
+	SetReadOnly(o.data, v.IsTrue())
+	return toPython(nil)
+}
+
+func (o *View) Py_is_read_only() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(IsReadOnly(o.data))
+}
+
+func (o *View) Py_is_loading() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(IsLoading(o.data))
+}
+
+func (o *View) Py_is_valid() (py.Object, error) {
+	return toPython(IsValid(o.data))
+}
+
+func (o *View) Py_close() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(o.data.Close())
+}
+
+func init() {
+	backend.OnNew.Add(onLifecycleNew)
+	backend.OnLoad.Add(onLifecycleLoad)
+	backend.OnClose.Add(onLifecycleClose)
+}
@@ -0,0 +1,199 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/macro"
+	"github.com/limetext/sublime/resource"
+)
+
+// The command log, the commands the user runs are logged
+var cmdLog struct {
+	sync.Mutex
+	enabled bool
+}
+
+// Commands controlling the recording which aren't recorded themselves
+var recordCommands = map[string]bool{
+	"start_record_macro":  true,
+	"stop_record_macro":   true,
+	"toggle_record_macro": true,
+}
+
+// RunUserCommand runs the command the user chose with an input, like a
+// mouse binding, a menu item or a command palette entry. The command is
+// logged and recorded into the macro being recorded, unlike the commands
+// plugins and other commands run.
+func RunUserCommand(name string, args backend.Args) {
+	cmdLog.Lock()
+	enabled := cmdLog.enabled
+	cmdLog.Unlock()
+	if enabled {
+		log.Info("Command: %s %v", name, args)
+	}
+	if !recordCommands[name] {
+		macro.Record(name, args)
+	}
+	backend.GetEditor().RunCommand(name, args)
+}
+
+func sublime_LogCommands(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(*py.Bool)
+	if !ok {
+		return nil, fmt.Errorf("Expected type bool for log_commands() arg1, not %s", v.Type())
+	}
+	cmdLog.Lock()
+	cmdLog.enabled = b.Bool()
+	cmdLog.Unlock()
+	backend.GetEditor().LogCommands(b.Bool())
+	return toPython(nil)
+}
+
+func sublime_RunCommand(tu *py.Tuple) (py.Object, error) {
+	name, err := pyStringArg(tu, nil, 0, "cmd", "")
+	if err != nil {
+		return nil, err
+	}
+	args := make(backend.Args)
+	if v, ok := pyArg(tu, nil, 1, "args"); ok {
+		if a, err := fromPython(v); err != nil {
+			return nil, err
+		} else if a, ok := a.(backend.Args); !ok {
+			return nil, fmt.Errorf("Expected type dict for run_command() arg2, not %s", v.Type())
+		} else {
+			args = a
+		}
+	}
+	backend.GetEditor().RunCommand(name, args)
+	return toPython(nil)
+}
+
+func sublime_GetMacro() (py.Object, error) {
+	m := macro.Last()
+	ret := make(List, len(m))
+	for i, a := range m {
+		item := backend.Args{"command": a.Command}
+		if a.Args != nil {
+			item["args"] = backend.Args(a.Args)
+		}
+		ret[i] = item
+	}
+	return toPython(ret)
+}
+
+type (
+	// StartRecordMacroCommand starts recording the commands the user runs.
+	StartRecordMacroCommand struct {
+		backend.DefaultCommand
+	}
+
+	// StopRecordMacroCommand stops the recording, the recorded macro is
+	// played back with run_macro.
+	StopRecordMacroCommand struct {
+		backend.DefaultCommand
+	}
+
+	// ToggleRecordMacroCommand starts or stops recording.
+	ToggleRecordMacroCommand struct {
+		backend.DefaultCommand
+	}
+
+	// RunMacroCommand plays back the last recorded macro.
+	RunMacroCommand struct {
+		backend.DefaultCommand
+	}
+
+	// RunMacroFileCommand plays back the macro of a .sublime-macro file,
+	// file is the resource name of the macro like
+	// res://Packages/Default/Delete Line.sublime-macro.
+	RunMacroFileCommand struct {
+		backend.DefaultCommand
+		file string
+	}
+)
+
+func (c *StartRecordMacroCommand) Run(w *backend.Window) error {
+	macro.Start()
+	return nil
+}
+
+func (c *StopRecordMacroCommand) Run(w *backend.Window) error {
+	macro.Stop()
+	return nil
+}
+
+func (c *ToggleRecordMacroCommand) Run(w *backend.Window) error {
+	if macro.Recording() {
+		macro.Stop()
+	} else {
+		macro.Start()
+	}
+	return nil
+}
+
+func (c *RunMacroCommand) Run(w *backend.Window) error {
+	RunMacro(macro.Last())
+	return nil
+}
+
+func (c *RunMacroFileCommand) Init(args backend.Args) error {
+	file, ok := args["file"].(string)
+	if !ok {
+		return fmt.Errorf("run_macro_file: Missing file argument")
+	}
+	c.file = strings.TrimPrefix(file, "res://")
+	return nil
+}
+
+func (c *RunMacroFileCommand) Run(w *backend.Window) error {
+	m := macro.Get(c.file)
+	if m == nil {
+		data, err := resource.ReadFile(c.file)
+		if err != nil {
+			return err
+		}
+		if m, err = macro.Load(data); err != nil {
+			return err
+		}
+	}
+	RunMacro(m)
+	return nil
+}
+
+// RunMacro runs the commands of the macro in order, text commands run on the
+// active view.
+func RunMacro(m []macro.Action) {
+	ed := backend.GetEditor()
+	for _, a := range m {
+		ed.RunCommand(a.Command, backend.Args(a.Args))
+	}
+}
+
+func init() {
+	ch := backend.GetEditor().CommandHandler()
+	cmds := map[string]interface{}{
+		"start_record_macro":  &StartRecordMacroCommand{},
+		"stop_record_macro":   &StopRecordMacroCommand{},
+		"toggle_record_macro": &ToggleRecordMacroCommand{},
+		"run_macro":           &RunMacroCommand{},
+		"run_macro_file":      &RunMacroFileCommand{},
+	}
+	for name, cmd := range cmds {
+		if err := ch.Register(name, cmd); err != nil {
+			log.Warn("Failed to register command %s: %s", name, err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/macro"
+	"github.com/limetext/text"
+)
+
+func TestRunUserCommand(t *testing.T) {
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.NewFile()
+	defer func() {
+		v.SetScratch(true)
+		v.Close()
+	}()
+
+	RunUserCommand("start_record_macro", nil)
+	RunUserCommand("insert", backend.Args{"characters": "a"})
+	// commands run by other code than user input aren't recorded
+	backend.GetEditor().RunCommand("insert", backend.Args{"characters": "b"})
+	RunUserCommand("stop_record_macro", nil)
+
+	exp := []macro.Action{{Command: "insert", Args: map[string]interface{}{"characters": "a"}}}
+	if got := macro.Last(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected macro %v, but got %v", exp, got)
+	}
+
+	RunUserCommand("run_macro", nil)
+	if s := v.Substr(text.Region{A: 0, B: v.Size()}); s != "aba" {
+		t.Errorf("Expected aba after running the macro, but got %s", s)
+	}
+}
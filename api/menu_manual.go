@@ -0,0 +1,106 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/menu"
+)
+
+type (
+	// MenuItem is a menu entry with the state of its command, ready for
+	// frontends to render.
+	MenuItem struct {
+		Caption   string
+		Command   string
+		Args      backend.Args
+		Mnemonic  string
+		Checkbox  bool
+		Separator bool
+		Enabled   bool
+		Checked   bool
+		Children  []MenuItem
+	}
+
+	// PaletteItem is a command palette entry with the state of its command.
+	PaletteItem struct {
+		Caption string
+		Command string
+		Args    backend.Args
+		Enabled bool
+	}
+)
+
+// Returns the caption of the entry, commands could provide the caption with
+// their description
+func caption(caption, command string, args backend.Args) string {
+	if caption != "" || command == "" {
+		return caption
+	}
+	return CommandDescription(command, args)
+}
+
+func menuItems(items []*menu.Item) []MenuItem {
+	ret := make([]MenuItem, 0, len(items))
+	for _, it := range items {
+		args := backend.Args(it.Args)
+		mi := MenuItem{
+			Caption:   caption(it.Caption, it.Command, args),
+			Command:   it.Command,
+			Args:      args,
+			Mnemonic:  it.Mnemonic,
+			Checkbox:  it.Checkbox,
+			Separator: it.IsSeparator(),
+			Enabled:   true,
+		}
+		if it.Command != "" {
+			var visible bool
+			visible, mi.Enabled, mi.Checked = CommandState(it.Command, mi.Args)
+			if !visible {
+				continue
+			}
+		}
+		if len(it.Children) != 0 {
+			mi.Children = menuItems(it.Children)
+		}
+		ret = append(ret, mi)
+	}
+	return ret
+}
+
+// Menu returns the named menu, like Main or Context, merged from all the
+// packages. Items with invisible commands are left out. Frontends run the
+// command of the chosen item with RunUserCommand.
+func Menu(name string) []MenuItem {
+	return menuItems(menu.Get(name))
+}
+
+// MenuNames returns the names of the menus the packages define.
+func MenuNames() []string {
+	return menu.Names()
+}
+
+// Palette returns the command palette entries of all the packages, entries
+// with invisible commands are left out. Frontends run the command of the
+// chosen entry with RunUserCommand.
+func Palette() []PaletteItem {
+	cmds := menu.Commands()
+	ret := make([]PaletteItem, 0, len(cmds))
+	for _, c := range cmds {
+		args := backend.Args(c.Args)
+		pi := PaletteItem{
+			Caption: caption(c.Caption, c.Command, args),
+			Command: c.Command,
+			Args:    args,
+		}
+		visible, enabled, _ := CommandState(c.Command, pi.Args)
+		if !visible {
+			continue
+		}
+		pi.Enabled = enabled
+		ret = append(ret, pi)
+	}
+	return ret
+}
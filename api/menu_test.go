@@ -0,0 +1,108 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/menu"
+)
+
+type stateCommand struct {
+	backend.DefaultCommand
+	setting string
+}
+
+func (c *stateCommand) Init(args backend.Args) error {
+	c.setting, _ = args["setting"].(string)
+	return nil
+}
+
+func (c *stateCommand) IsVisible() bool {
+	return c.setting != "hidden"
+}
+
+func (c *stateCommand) IsEnabled() bool {
+	return c.setting != "disabled"
+}
+
+func (c *stateCommand) IsChecked(args backend.Args) bool {
+	return args["setting"] == "checked"
+}
+
+func (c *stateCommand) Description() string {
+	return "State " + c.setting
+}
+
+func (c *stateCommand) Run() error {
+	return nil
+}
+
+func TestMenu(t *testing.T) {
+	if err := RegisterCommand("test_menu_state", &stateCommand{}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterCommand("test_menu_state")
+
+	item := func(setting string) *menu.Item {
+		return &menu.Item{Command: "test_menu_state", Args: map[string]interface{}{"setting": setting}, Checkbox: true}
+	}
+	menu.Add("TestMenu", "Test", []*menu.Item{
+		{Caption: "Test", Children: []*menu.Item{
+			item("hidden"), item("disabled"), {Caption: "-"}, item("checked"),
+		}},
+	})
+	items := Menu("TestMenu")
+	if len(items) != 1 || len(items[0].Children) != 3 {
+		t.Fatalf("Expected one item with 3 visible children, but got %v", items)
+	}
+	children := items[0].Children
+	if c := children[0]; c.Enabled || c.Checked || c.Caption != "State disabled" {
+		t.Errorf("Expected disabled unchecked item with description caption, but got %+v", c)
+	}
+	if c := children[1]; !c.Separator {
+		t.Errorf("Expected separator, but got %+v", c)
+	}
+	if c := children[2]; !c.Enabled || !c.Checked {
+		t.Errorf("Expected enabled checked item, but got %+v", c)
+	}
+
+	menu.AddCommands("Test", []menu.Command{
+		{Caption: "Hidden", Command: "test_menu_state", Args: map[string]interface{}{"setting": "hidden"}},
+		{Caption: "Disabled", Command: "test_menu_state", Args: map[string]interface{}{"setting": "disabled"}},
+		{Caption: "Unknown", Command: "test_unknown_command"},
+	})
+	defer menu.RemoveCommands("Test")
+	var palette []PaletteItem
+	for _, p := range Palette() {
+		if p.Caption == "Hidden" || p.Caption == "Disabled" || p.Caption == "Unknown" {
+			palette = append(palette, p)
+		}
+	}
+	if len(palette) != 2 || palette[0].Enabled || !palette[1].Enabled {
+		t.Errorf("Expected disabled and enabled palette entries, but got %v", palette)
+	}
+}
+
+func TestCommandStateArgs(t *testing.T) {
+	cmd := &stateCommand{}
+	cmd.Init(backend.Args{"setting": "running"})
+	if err := RegisterCommand("test_state_args", cmd); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterCommand("test_state_args")
+
+	args := backend.Args{"setting": "disabled"}
+	if visible, enabled, _ := CommandState("test_state_args", args); !visible || enabled {
+		t.Errorf("Expected visible disabled command, but got %v %v", visible, enabled)
+	}
+	if d := CommandDescription("test_state_args", args); d != "State disabled" {
+		t.Errorf("Expected description State disabled, but got %s", d)
+	}
+	if cmd.setting != "running" {
+		t.Errorf("Expected the registered command to keep its arguments, but got %s", cmd.setting)
+	}
+}
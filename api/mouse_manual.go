@@ -0,0 +1,42 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/mouse"
+)
+
+// MouseEvent runs the command bound to the click e in v, press tells whether
+// the button was pressed or released. It returns false when there is no
+// binding for the click. The coordinates of the click are added to the
+// arguments as event, python commands only get it when their want_event
+// returns True.
+func MouseEvent(v *backend.View, e mouse.Event, press bool) bool {
+	b := mouse.Editor().Find(e)
+	if b == nil {
+		return false
+	}
+	cmd, args := b.Command, b.Args
+	if press {
+		cmd, args = b.PressCommand, b.PressArgs
+	}
+	if cmd == "" {
+		return true
+	}
+
+	a := make(backend.Args, len(args)+1)
+	for k, val := range args {
+		a[k] = val
+	}
+	a["event"] = backend.Args{"x": e.X, "y": e.Y}
+
+	// the clicked view gets the focus so the command runs on it
+	if w := v.Window(); w != nil {
+		w.SetActiveView(v)
+	}
+	RunUserCommand(cmd, a)
+	return true
+}
@@ -0,0 +1,63 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/mouse"
+)
+
+type clickCommand struct {
+	backend.DefaultCommand
+}
+
+var clickArgs backend.Args
+
+func (c *clickCommand) Init(args backend.Args) error {
+	clickArgs = args
+	return nil
+}
+
+func (c *clickCommand) Run(v *backend.View, e *backend.Edit) error {
+	return nil
+}
+
+func TestMouseEvent(t *testing.T) {
+	if err := RegisterCommand("test_click", &clickCommand{}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterCommand("test_click")
+
+	mb := mouse.Editor()
+	if err := json.Unmarshal([]byte(`[{
+		"button": "button1", "modifiers": ["ctrl"],
+		"press_command": "test_click", "press_args": {"by": "words"}
+	}]`), mb); err != nil {
+		t.Fatal(err)
+	}
+	defer json.Unmarshal([]byte(`[]`), mb)
+
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.NewFile()
+
+	if MouseEvent(v, mouse.Event{Button: "button1"}, true) {
+		t.Error("Expected no binding without modifiers")
+	}
+	clickArgs = nil
+	if !MouseEvent(v, mouse.Event{Button: "button1", Modifiers: []string{"ctrl"}, X: 10, Y: 20}, true) {
+		t.Fatal("Expected a binding with ctrl")
+	}
+	if clickArgs["by"] != "words" {
+		t.Errorf("Expected the press args, but got %v", clickArgs)
+	}
+	ev, ok := clickArgs["event"].(backend.Args)
+	if !ok || ev["x"] != 10.0 || ev["y"] != 20.0 {
+		t.Errorf("Expected event {x: 10, y: 20}, but got %v", clickArgs["event"])
+	}
+}
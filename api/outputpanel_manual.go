@@ -0,0 +1,356 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/text"
+)
+
+// Output panel names are prefixed with this when they are passed to
+// show_panel or returned from panels() and active_panel()
+const outputPrefix = "output."
+
+type (
+	// OutputPanelFrontend is implemented by frontends that are able to
+	// show output panels.
+	OutputPanelFrontend interface {
+		ShowPanel(w *backend.Window, name string, v *backend.View)
+		HidePanel(w *backend.Window)
+	}
+
+	outputPanel struct {
+		view     *backend.View
+		unlisted bool
+	}
+
+	// Output panels of a window, panels are backed by views of the window
+	// but they aren't listed in window views.
+	windowPanels struct {
+		panels map[string]*outputPanel
+		active string
+	}
+)
+
+var (
+	outputPanels = struct {
+		sync.Mutex
+		m map[*backend.Window]*windowPanels
+		// the windows creating a panel view
+		creating map[*backend.Window]bool
+	}{
+		m:        make(map[*backend.Window]*windowPanels),
+		creating: make(map[*backend.Window]bool),
+	}
+
+	// OnNewFile is called with the new views of windows except for the
+	// ones backing output panels, python on_new listeners are called by it
+	// rather than by backend.OnNew.
+	OnNewFile backend.ViewEvent
+)
+
+// Returns the output panels of the window, windows are only added once they
+// create a panel and removed once their panels are all closed.
+func panelsOf(w *backend.Window, create bool) *windowPanels {
+	wp, ok := outputPanels.m[w]
+	if !ok {
+		wp = &windowPanels{panels: make(map[string]*outputPanel)}
+		if create {
+			outputPanels.m[w] = wp
+		}
+	}
+	return wp
+}
+
+func outputName(name string) string {
+	return strings.TrimPrefix(name, outputPrefix)
+}
+
+// CreateOutputPanel returns the view of the named output panel, the panel
+// is created if it doesn't exist otherwise its content is cleared. The view
+// has the is_widget setting frontends don't show tabs for, it isn't
+// activated and doesn't reach OnNewFile.
+func CreateOutputPanel(w *backend.Window, name string, unlisted bool) *backend.View {
+	name = outputName(name)
+	outputPanels.Lock()
+	p, ok := panelsOf(w, false).panels[name]
+	outputPanels.Unlock()
+
+	if ok {
+		p.unlisted = unlisted
+		v := p.view
+		e := v.BeginEdit()
+		v.Erase(e, text.Region{A: 0, B: v.Size()})
+		v.EndEdit(e)
+		return v
+	}
+
+	// creating the view changes the active view of the window, even when it
+	// had none
+	active := w.ActiveView()
+	outputPanels.Lock()
+	outputPanels.creating[w] = true
+	outputPanels.Unlock()
+	v := w.NewFile()
+	v.SetScratch(true)
+	v.Settings().Set("is_widget", true)
+	w.SetActiveView(active)
+
+	outputPanels.Lock()
+	delete(outputPanels.creating, w)
+	panelsOf(w, true).panels[name] = &outputPanel{view: v, unlisted: unlisted}
+	outputPanels.Unlock()
+	return v
+}
+
+// FindOutputPanel returns the view of the named output panel or nil if
+// there is no such panel.
+func FindOutputPanel(w *backend.Window, name string) *backend.View {
+	outputPanels.Lock()
+	defer outputPanels.Unlock()
+	if p, ok := panelsOf(w, false).panels[outputName(name)]; ok {
+		return p.view
+	}
+	return nil
+}
+
+// DestroyOutputPanel closes the view of the named output panel.
+func DestroyOutputPanel(w *backend.Window, name string) {
+	name = outputName(name)
+	outputPanels.Lock()
+	wp := panelsOf(w, false)
+	p, ok := wp.panels[name]
+	hide := wp.active == outputPrefix+name
+	removePanel(w, name)
+	outputPanels.Unlock()
+
+	if !ok {
+		return
+	}
+	if hide {
+		HidePanel(w)
+	}
+	p.view.Close()
+}
+
+// Panels returns the names of the listed output panels of the window.
+func Panels(w *backend.Window) []string {
+	outputPanels.Lock()
+	defer outputPanels.Unlock()
+	ret := make([]string, 0)
+	for name, p := range panelsOf(w, false).panels {
+		if !p.unlisted {
+			ret = append(ret, outputPrefix+name)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// ActivePanel returns the name of the shown panel or an empty string if
+// there isn't any.
+func ActivePanel(w *backend.Window) string {
+	outputPanels.Lock()
+	defer outputPanels.Unlock()
+	return panelsOf(w, false).active
+}
+
+// ShowPanel makes the named panel visible.
+func ShowPanel(w *backend.Window, name string) error {
+	v := FindOutputPanel(w, name)
+	if v == nil {
+		return fmt.Errorf("No such panel: %s", name)
+	}
+	outputPanels.Lock()
+	panelsOf(w, true).active = outputPrefix + outputName(name)
+	outputPanels.Unlock()
+
+	if fe, ok := backend.GetEditor().Frontend().(OutputPanelFrontend); ok {
+		fe.ShowPanel(w, outputPrefix+outputName(name), v)
+	}
+	return nil
+}
+
+// HidePanel hides the shown panel of the window.
+func HidePanel(w *backend.Window) {
+	outputPanels.Lock()
+	panelsOf(w, false).active = ""
+	outputPanels.Unlock()
+
+	if fe, ok := backend.GetEditor().Frontend().(OutputPanelFrontend); ok {
+		fe.HidePanel(w)
+	}
+}
+
+// IsPanel reports whether the view is backing an output panel.
+func IsPanel(v *backend.View) bool {
+	outputPanels.Lock()
+	defer outputPanels.Unlock()
+	wp, ok := outputPanels.m[v.Window()]
+	if !ok {
+		return false
+	}
+	for _, p := range wp.panels {
+		if p.view == v {
+			return true
+		}
+	}
+	return false
+}
+
+// WindowViews returns the views of the window excluding the ones backing
+// output panels, it should be used instead of backend.Window.Views to list
+// the files of a window.
+func WindowViews(w *backend.Window) []*backend.View {
+	var ret []*backend.View
+	for _, v := range w.Views() {
+		if !IsPanel(v) {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// Removes the panel from the window, the window is forgotten once it has no
+// panels left. It must be called with outputPanels locked.
+func removePanel(w *backend.Window, name string) {
+	wp, ok := outputPanels.m[w]
+	if !ok {
+		return
+	}
+	delete(wp.panels, name)
+	if wp.active == outputPrefix+name {
+		wp.active = ""
+	}
+	if len(wp.panels) == 0 {
+		delete(outputPanels.m, w)
+	}
+}
+
+// Passes the new views on to OnNewFile unless they are created for a panel.
+func onPanelNew(v *backend.View) {
+	outputPanels.Lock()
+	panel := outputPanels.creating[v.Window()]
+	outputPanels.Unlock()
+	if !panel {
+		OnNewFile.Call(v)
+	}
+}
+
+// Panels are closed with the other views of their window, so this also
+// forgets the panels of closed windows.
+func onPanelClose(v *backend.View) {
+	outputPanels.Lock()
+	defer outputPanels.Unlock()
+	w := v.Window()
+	for name, p := range panelsOf(w, false).panels {
+		if p.view == v {
+			removePanel(w, name)
+		}
+	}
+}
+
+type (
+	ShowPanelCommand struct {
+		backend.DefaultCommand
+		panel  string
+		toggle bool
+	}
+
+	HidePanelCommand struct {
+		backend.DefaultCommand
+	}
+)
+
+func (c *ShowPanelCommand) Init(args backend.Args) error {
+	c.panel, _ = args["panel"].(string)
+	c.toggle, _ = args["toggle"].(bool)
+	return nil
+}
+
+func (c *ShowPanelCommand) Run(w *backend.Window) error {
+	if c.toggle && ActivePanel(w) == c.panel {
+		HidePanel(w)
+		return nil
+	}
+	return ShowPanel(w, c.panel)
+}
+
+func (c *HidePanelCommand) Run(w *backend.Window) error {
+	HidePanel(w)
+	return nil
+}
+
+func (o *Window) Py_views() (py.Object, error) {
+	return toPython(WindowViews(o.data))
+}
+
+func (o *Window) Py_create_output_panel(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	name, err := pyStringArg(tu, kw, 0, "name", "")
+	if err != nil {
+		return nil, err
+	}
+	var unlisted bool
+	if v, ok := pyArg(tu, kw, 1, "unlisted"); ok {
+		unlisted = v.Base().IsTrue()
+	}
+	return toPython(CreateOutputPanel(o.data, name, unlisted))
+}
+
+// get_output_panel is the deprecated name of create_output_panel
+func (o *Window) Py_get_output_panel(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	return o.Py_create_output_panel(tu, kw)
+}
+
+func (o *Window) Py_find_output_panel(tu *py.Tuple) (py.Object, error) {
+	name, err := pyStringArg(tu, nil, 0, "name", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(FindOutputPanel(o.data, name))
+}
+
+func (o *Window) Py_destroy_output_panel(tu *py.Tuple) (py.Object, error) {
+	name, err := pyStringArg(tu, nil, 0, "name", "")
+	if err != nil {
+		return nil, err
+	}
+	DestroyOutputPanel(o.data, name)
+	return toPython(nil)
+}
+
+func (o *Window) Py_panels() (py.Object, error) {
+	return toPython(Panels(o.data))
+}
+
+func (o *Window) Py_active_panel() (py.Object, error) {
+	if p := ActivePanel(o.data); p != "" {
+		return toPython(p)
+	}
+	return toPython(nil)
+}
+
+func init() {
+	backend.OnNew.Add(onPanelNew)
+	backend.OnClose.Add(onPanelClose)
+
+	ch := backend.GetEditor().CommandHandler()
+	cmds := map[string]interface{}{
+		"show_panel": &ShowPanelCommand{},
+		"hide_panel": &HidePanelCommand{},
+	}
+	for name, cmd := range cmds {
+		if err := ch.Register(name, cmd); err != nil {
+			log.Warn("Failed to register command %s: %s", name, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/limetext/backend"
+)
+
+func TestCreateOutputPanel(t *testing.T) {
+	var news []*backend.View
+	defer func(old backend.ViewEvent) { OnNewFile = old }(OnNewFile)
+	OnNewFile.Add(func(v *backend.View) {
+		news = append(news, v)
+	})
+
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	p := CreateOutputPanel(w, "test", false)
+	if av := w.ActiveView(); av != nil {
+		t.Errorf("Expected no active view, but got %s", av)
+	}
+	if len(WindowViews(w)) != 0 {
+		t.Errorf("Expected no window views, but got %v", WindowViews(w))
+	}
+	v := w.NewFile()
+	if len(news) != 1 || news[0] != v {
+		t.Errorf("Expected OnNewFile to be called with %s only, but got %v", v, news)
+	}
+	if av := w.ActiveView(); av != v {
+		t.Errorf("Expected %s to be active, but got %s", v, av)
+	}
+	if FindOutputPanel(w, "test") != p {
+		t.Error("Expected to find the panel")
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/render"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/minihtml"
+	"github.com/limetext/text"
+)
+
+// Layouts of phantoms
+const (
+	LAYOUT_INLINE = iota
+	LAYOUT_BELOW
+	LAYOUT_BLOCK
+)
+
+type (
+	// PhantomFrontend is implemented by frontends that are able to show
+	// phantoms, it's called with all the phantoms of the view whenever
+	// they change.
+	PhantomFrontend interface {
+		UpdatePhantoms(v *backend.View, phantoms []*ViewPhantom)
+	}
+
+	// ViewPhantom is a minihtml document shown inline in the view. The
+	// region is tracked by the view so it follows the edits of the buffer.
+	ViewPhantom struct {
+		View    *backend.View
+		ID      int
+		Key     string
+		Content string
+		Doc     *minihtml.Node
+		Layout  int
+		// optional, might be nil
+		onNavigate py.Object
+	}
+
+	// Phantom is the python description of a phantom handed to a
+	// PhantomSet.
+	Phantom struct {
+		py.BaseObject
+		region     text.Region
+		content    string
+		layout     int
+		onNavigate py.Object
+		id         int
+	}
+
+	// PhantomSet keeps the phantoms of a view under the same key, updating
+	// replaces the phantoms which changed.
+	PhantomSet struct {
+		py.BaseObject
+		view     *backend.View
+		key      string
+		phantoms []*Phantom
+	}
+)
+
+var (
+	phantoms = struct {
+		sync.Mutex
+		last int
+		m    map[*backend.View][]*ViewPhantom
+	}{m: make(map[*backend.View][]*ViewPhantom)}
+
+	_phantomClass = py.Class{
+		Name:    "sublime.Phantom",
+		Pointer: (*Phantom)(nil),
+	}
+	_phantomSetClass = py.Class{
+		Name:    "sublime.PhantomSet",
+		Pointer: (*PhantomSet)(nil),
+	}
+)
+
+// Region returns the current region of the phantom.
+func (p *ViewPhantom) Region() text.Region {
+	if rs := p.View.GetRegions(p.regionKey()); len(rs) > 0 {
+		return rs[0]
+	}
+	return text.Region{}
+}
+
+// Navigate reports the href of the link the user clicked.
+func (p *ViewPhantom) Navigate(href string) {
+	if p.onNavigate == nil {
+		return
+	}
+	l := py.NewLock()
+	defer l.Unlock()
+	pyCallback(p.onNavigate, href)
+}
+
+func (p *ViewPhantom) regionKey() string {
+	return fmt.Sprintf("__phantom_%d", p.ID)
+}
+
+func (p *ViewPhantom) release() {
+	p.View.EraseRegions(p.regionKey())
+	if p.onNavigate != nil {
+		l := py.NewLock()
+		p.onNavigate.Decref()
+		l.Unlock()
+	}
+}
+
+// Phantoms returns the phantoms of the view sorted by id.
+func Phantoms(v *backend.View) []*ViewPhantom {
+	phantoms.Lock()
+	defer phantoms.Unlock()
+	ret := make([]*ViewPhantom, len(phantoms.m[v]))
+	copy(ret, phantoms.m[v])
+	return ret
+}
+
+// AddPhantom adds the phantom to its view assigning it a new id.
+func AddPhantom(p *ViewPhantom, r text.Region) int {
+	phantoms.Lock()
+	phantoms.last++
+	p.ID = phantoms.last
+	phantoms.m[p.View] = append(phantoms.m[p.View], p)
+	phantoms.Unlock()
+	p.View.AddRegions(p.regionKey(), []text.Region{r}, "", "", render.HIDDEN)
+	updatePhantoms(p.View)
+	return p.ID
+}
+
+// ErasePhantoms erases the phantoms of the view for which erase returns
+// true.
+func ErasePhantoms(v *backend.View, erase func(*ViewPhantom) bool) {
+	phantoms.Lock()
+	var keep, erased []*ViewPhantom
+	for _, p := range phantoms.m[v] {
+		if erase(p) {
+			erased = append(erased, p)
+		} else {
+			keep = append(keep, p)
+		}
+	}
+	if len(keep) == 0 {
+		delete(phantoms.m, v)
+	} else {
+		phantoms.m[v] = keep
+	}
+	phantoms.Unlock()
+	if len(erased) == 0 {
+		return
+	}
+	for _, p := range erased {
+		p.release()
+	}
+	updatePhantoms(v)
+}
+
+// Returns the phantom of the view with the id, nil if there is none.
+func phantomByID(v *backend.View, id int) *ViewPhantom {
+	phantoms.Lock()
+	defer phantoms.Unlock()
+	ps := phantoms.m[v]
+	i := sort.Search(len(ps), func(i int) bool { return ps[i].ID >= id })
+	if i < len(ps) && ps[i].ID == id {
+		return ps[i]
+	}
+	return nil
+}
+
+func updatePhantoms(v *backend.View) {
+	if fe, ok := backend.GetEditor().Frontend().(PhantomFrontend); ok {
+		fe.UpdatePhantoms(v, Phantoms(v))
+	}
+}
+
+func onPhantomsClose(v *backend.View) {
+	ErasePhantoms(v, func(*ViewPhantom) bool { return true })
+}
+
+func (o *View) Py_add_phantom(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	var err error
+	p := &ViewPhantom{View: o.data}
+	if p.Key, err = pyStringArg(tu, kw, 0, "key", ""); err != nil {
+		return nil, err
+	}
+	v, ok := pyArg(tu, kw, 1, "region")
+	if !ok {
+		return nil, fmt.Errorf("add_phantom requires a region")
+	}
+	r, ok := v.(*Region)
+	if !ok {
+		return nil, fmt.Errorf("Expected type Region for region, not %s", v.Type())
+	}
+	if p.Content, err = pyStringArg(tu, kw, 2, "content", ""); err != nil {
+		return nil, err
+	}
+	if p.Doc, err = minihtml.Parse(p.Content); err != nil {
+		return nil, err
+	}
+	if p.Layout, err = pyIntArg(tu, kw, 3, "layout", LAYOUT_INLINE); err != nil {
+		return nil, err
+	}
+	if v, ok := pyArg(tu, kw, 4, "on_navigate"); ok {
+		p.onNavigate = v
+		p.onNavigate.Incref()
+	}
+	return toPython(AddPhantom(p, r.data))
+}
+
+func (o *View) Py_erase_phantoms(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	key, err := pyStringArg(tu, nil, 0, "key", "")
+	if err != nil {
+		return nil, err
+	}
+	ErasePhantoms(o.data, func(p *ViewPhantom) bool { return p.Key == key })
+	return toPython(nil)
+}
+
+func (o *View) Py_erase_phantom_by_id(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	id, err := pyIntArg(tu, nil, 0, "pid", 0)
+	if err != nil {
+		return nil, err
+	}
+	ErasePhantoms(o.data, func(p *ViewPhantom) bool { return p.ID == id })
+	return toPython(nil)
+}
+
+func (o *View) Py_query_phantom(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	id, err := pyIntArg(tu, nil, 0, "pid", 0)
+	if err != nil {
+		return nil, err
+	}
+	var ret []text.Region
+	if p := phantomByID(o.data, id); p != nil {
+		ret = append(ret, p.Region())
+	}
+	return regionsToPython(ret)
+}
+
+func (o *View) Py_query_phantoms(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	v, ok := pyArg(tu, nil, 0, "pids")
+	if !ok {
+		return nil, fmt.Errorf("query_phantoms requires a list of ids")
+	}
+	ids, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := ids.(List)
+	if !ok {
+		return nil, fmt.Errorf("Expected list of ids, not %s", v.Type())
+	}
+	var ret []text.Region
+	for _, id := range l {
+		i, ok := id.(int)
+		if !ok {
+			return nil, fmt.Errorf("Expected list of ids, not %v", l)
+		}
+		if p := phantomByID(o.data, i); p != nil {
+			ret = append(ret, p.Region())
+		}
+	}
+	return regionsToPython(ret)
+}
+
+func (o *Phantom) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	v, ok := pyArg(args, kwds, 0, "region")
+	if !ok {
+		return fmt.Errorf("Phantom requires a region")
+	}
+	r, ok := v.(*Region)
+	if !ok {
+		return fmt.Errorf("Expected type Region for region, not %s", v.Type())
+	}
+	o.region = r.data
+	var err error
+	if o.content, err = pyStringArg(args, kwds, 1, "content", ""); err != nil {
+		return err
+	}
+	if o.layout, err = pyIntArg(args, kwds, 2, "layout", LAYOUT_INLINE); err != nil {
+		return err
+	}
+	if v, ok := pyArg(args, kwds, 3, "on_navigate"); ok {
+		o.onNavigate = v
+		o.onNavigate.Incref()
+	}
+	return nil
+}
+
+// Phantoms are equal when they show the same content the same way at the
+// same region, the id and callback don't matter.
+func (o *Phantom) PyRichCompare(other py.Object, op py.Op) (py.Object, error) {
+	if op != py.EQ && op != py.NE {
+		return nil, fmt.Errorf("Can only do EQ and NE compares")
+	}
+	o2, ok := other.(*Phantom)
+	eq := ok && o.equal(o2)
+	if op == py.EQ {
+		return toPython(eq)
+	}
+	return toPython(!eq)
+}
+
+func (o *Phantom) equal(o2 *Phantom) bool {
+	return o.region == o2.region && o.content == o2.content && o.layout == o2.layout
+}
+
+func (o *Phantom) PyGet_region() (py.Object, error) {
+	return toPython(o.region)
+}
+
+func (o *Phantom) PyGet_content() (py.Object, error) {
+	return toPython(o.content)
+}
+
+func (o *Phantom) PyGet_layout() (py.Object, error) {
+	return toPython(o.layout)
+}
+
+func (o *Phantom) PyGet_on_navigate() (py.Object, error) {
+	if o.onNavigate == nil {
+		return toPython(nil)
+	}
+	o.onNavigate.Incref()
+	return o.onNavigate, nil
+}
+
+func (o *Phantom) PyGet_id() (py.Object, error) {
+	return toPython(o.id)
+}
+
+func (o *PhantomSet) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	v, ok := pyArg(args, kwds, 0, "view")
+	if !ok {
+		return fmt.Errorf("PhantomSet requires a view")
+	}
+	pv, ok := v.(*View)
+	if !ok {
+		return fmt.Errorf("Expected type View for view, not %s", v.Type())
+	}
+	o.view = pv.data
+	var err error
+	o.key, err = pyStringArg(args, kwds, 1, "key", "")
+	return err
+}
+
+// Py_update replaces the phantoms of the set. Phantoms equal to one already
+// shown are kept as they are, the regions of the shown phantoms are
+// compared as the view tracks them.
+func (o *PhantomSet) Py_update(tu *py.Tuple) (py.Object, error) {
+	v, ok := pyArg(tu, nil, 0, "new_phantoms")
+	if !ok {
+		return nil, fmt.Errorf("update requires a list of phantoms")
+	}
+	seq, ok := v.(*py.List)
+	if !ok {
+		return nil, fmt.Errorf("Expected type list for new_phantoms, not %s", v.Type())
+	}
+	var next []*Phantom
+	for _, it := range seq.Slice() {
+		p, ok := it.(*Phantom)
+		if !ok {
+			return nil, fmt.Errorf("Expected list of Phantoms, not %s", it.Type())
+		}
+		next = append(next, p)
+	}
+
+	for _, p := range o.phantoms {
+		if vp := phantomByID(o.view, p.id); vp != nil {
+			p.region = vp.Region()
+		}
+	}
+	kept := make(map[int]bool)
+	for _, p := range next {
+		for _, old := range o.phantoms {
+			if !kept[old.id] && old.equal(p) {
+				p.id = old.id
+				kept[old.id] = true
+				break
+			}
+		}
+	}
+	docs := make([]*minihtml.Node, len(next))
+	for i, p := range next {
+		if kept[p.id] {
+			continue
+		}
+		var err error
+		if docs[i], err = minihtml.Parse(p.content); err != nil {
+			return nil, err
+		}
+	}
+
+	ErasePhantoms(o.view, func(vp *ViewPhantom) bool {
+		return vp.Key == o.key && !kept[vp.ID]
+	})
+	for i, p := range next {
+		if kept[p.id] {
+			continue
+		}
+		vp := &ViewPhantom{View: o.view, Key: o.key, Content: p.content, Doc: docs[i], Layout: p.layout, onNavigate: p.onNavigate}
+		if vp.onNavigate != nil {
+			vp.onNavigate.Incref()
+		}
+		p.id = AddPhantom(vp, p.region)
+	}
+
+	for _, p := range o.phantoms {
+		p.Decref()
+	}
+	for _, p := range next {
+		p.Incref()
+	}
+	o.phantoms = next
+	return toPython(nil)
+}
+
+func init() {
+	backend.OnClose.Add(onPhantomsClose)
+}
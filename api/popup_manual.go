@@ -0,0 +1,287 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/minihtml"
+)
+
+// Flags accepted by show_popup
+const (
+	HTML = 1 << iota
+	COOPERATE_WITH_AUTO_COMPLETE
+	HIDE_ON_MOUSE_MOVE
+	HIDE_ON_MOUSE_MOVE_AWAY
+)
+
+// Zones reported to on_hover
+const (
+	HOVER_TEXT = 1 + iota
+	HOVER_GUTTER
+	HOVER_MARGIN
+)
+
+type (
+	// PopupFrontend is implemented by frontends that are able to show
+	// popups. Frontends report clicked links with Navigate and popups they
+	// close on their own, e.g. because of the hide flags, with Hide.
+	PopupFrontend interface {
+		ShowPopup(*Popup)
+		UpdatePopup(*Popup)
+		HidePopup(*Popup)
+	}
+
+	// Popup is a minihtml document shown over the view at a text point.
+	Popup struct {
+		View *backend.View
+		// The content as given by the plugin and its parsed document
+		Content   string
+		Doc       *minihtml.Node
+		Flags     int
+		Location  int
+		MaxWidth  int
+		MaxHeight int
+		lock      sync.Mutex
+		hidden    bool
+		// optional, might be nil
+		onNavigate py.Object
+		onHide     py.Object
+	}
+
+	// HoverEvent is called when the mouse rests over a point of the view.
+	HoverEvent []func(v *backend.View, pt, zone int)
+
+	// OnHoverGlue adds python on_hover listeners to OnHover.
+	OnHoverGlue struct {
+		py.BaseObject
+		inner py.Object
+	}
+)
+
+var (
+	// OnHover is called by Hover.
+	OnHover HoverEvent
+
+	popups = struct {
+		sync.Mutex
+		m map[*backend.View]*Popup
+	}{m: make(map[*backend.View]*Popup)}
+
+	_onHoverGlueClass = py.Class{
+		Name:    "sublime.OnHoverGlue",
+		Pointer: (*OnHoverGlue)(nil),
+	}
+)
+
+// Add adds the listener to the event.
+func (e *HoverEvent) Add(cb func(v *backend.View, pt, zone int)) {
+	*e = append(*e, cb)
+}
+
+// Call calls all the listeners of the event.
+func (e HoverEvent) Call(v *backend.View, pt, zone int) {
+	for _, cb := range e {
+		cb(v, pt, zone)
+	}
+}
+
+// Hover is called by frontends when the mouse rests over the point of the
+// view, zone is one of HOVER_TEXT, HOVER_GUTTER or HOVER_MARGIN.
+func Hover(v *backend.View, pt, zone int) {
+	log.Finest("Hover %d %d", pt, zone)
+	OnHover.Call(v, pt, zone)
+}
+
+// PopupOf returns the visible popup of the view, nil if there is none.
+func PopupOf(v *backend.View) *Popup {
+	popups.Lock()
+	defer popups.Unlock()
+	return popups.m[v]
+}
+
+// ShowPopup shows the popup replacing the current popup of its view.
+func ShowPopup(p *Popup) {
+	if old := PopupOf(p.View); old != nil {
+		old.Hide()
+	}
+	popups.Lock()
+	popups.m[p.View] = p
+	popups.Unlock()
+	if fe, ok := backend.GetEditor().Frontend().(PopupFrontend); ok {
+		fe.ShowPopup(p)
+	}
+}
+
+// Update replaces the content of the popup.
+func (p *Popup) Update(content string) error {
+	doc, err := minihtml.Parse(content)
+	if err != nil {
+		return err
+	}
+	p.lock.Lock()
+	p.Content, p.Doc = content, doc
+	p.lock.Unlock()
+	if fe, ok := backend.GetEditor().Frontend().(PopupFrontend); ok {
+		fe.UpdatePopup(p)
+	}
+	return nil
+}
+
+// Navigate reports the href of the link the user clicked.
+func (p *Popup) Navigate(href string) {
+	p.lock.Lock()
+	skip := p.hidden || p.onNavigate == nil
+	p.lock.Unlock()
+	if skip {
+		return
+	}
+
+	l := py.NewLock()
+	defer l.Unlock()
+	pyCallback(p.onNavigate, href)
+}
+
+// Hide closes the popup, the on_hide callback is called only once no
+// matter how many times Hide is called.
+func (p *Popup) Hide() {
+	p.lock.Lock()
+	if p.hidden {
+		p.lock.Unlock()
+		return
+	}
+	p.hidden = true
+	p.lock.Unlock()
+
+	popups.Lock()
+	if popups.m[p.View] == p {
+		delete(popups.m, p.View)
+	}
+	popups.Unlock()
+	if fe, ok := backend.GetEditor().Frontend().(PopupFrontend); ok {
+		fe.HidePopup(p)
+	}
+
+	l := py.NewLock()
+	defer l.Unlock()
+	if p.onHide != nil {
+		pyCallback(p.onHide)
+	}
+	for _, cb := range []py.Object{p.onNavigate, p.onHide} {
+		if cb != nil {
+			cb.Decref()
+		}
+	}
+}
+
+func onPopupClose(v *backend.View) {
+	if p := PopupOf(v); p != nil {
+		p.Hide()
+	}
+}
+
+func (o *View) Py_show_popup(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	var err error
+	p := &Popup{View: o.data}
+	if p.Content, err = pyStringArg(tu, kw, 0, "content", ""); err != nil {
+		return nil, err
+	}
+	if p.Doc, err = minihtml.Parse(p.Content); err != nil {
+		return nil, err
+	}
+	if p.Flags, err = pyIntArg(tu, kw, 1, "flags", 0); err != nil {
+		return nil, err
+	}
+	if p.Location, err = pyIntArg(tu, kw, 2, "location", -1); err != nil {
+		return nil, err
+	}
+	if p.Location == -1 {
+		if rs := o.data.Sel().Regions(); len(rs) > 0 {
+			p.Location = rs[0].B
+		}
+	}
+	if p.MaxWidth, err = pyIntArg(tu, kw, 3, "max_width", 320); err != nil {
+		return nil, err
+	}
+	if p.MaxHeight, err = pyIntArg(tu, kw, 4, "max_height", 240); err != nil {
+		return nil, err
+	}
+	if v, ok := pyArg(tu, kw, 5, "on_navigate"); ok {
+		p.onNavigate = v
+		p.onNavigate.Incref()
+	}
+	if v, ok := pyArg(tu, kw, 6, "on_hide"); ok {
+		p.onHide = v
+		p.onHide.Incref()
+	}
+
+	ShowPopup(p)
+	return toPython(nil)
+}
+
+func (o *View) Py_update_popup(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	content, err := pyStringArg(tu, nil, 0, "content", "")
+	if err != nil {
+		return nil, err
+	}
+	if p := PopupOf(o.data); p != nil {
+		if err := p.Update(content); err != nil {
+			return nil, err
+		}
+	}
+	return toPython(nil)
+}
+
+func (o *View) Py_hide_popup() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	onPopupClose(o.data)
+	return toPython(nil)
+}
+
+func (o *View) Py_is_popup_visible() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(PopupOf(o.data) != nil)
+}
+
+func (c *OnHoverGlue) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	if args.Size() != 1 {
+		return fmt.Errorf("Expected only 1 argument not %d", args.Size())
+	}
+	if v, err := args.GetItem(0); err != nil {
+		return err
+	} else {
+		c.inner = v
+	}
+	c.inner.Incref()
+	c.Incref()
+
+	OnHover.Add(c.onHover)
+	return nil
+}
+
+func (c *OnHoverGlue) onHover(v *backend.View, pt, zone int) {
+	l := py.NewLock()
+	defer l.Unlock()
+	pyCallback(c.inner, v, pt, zone)
+}
+
+func init() {
+	backend.OnClose.Add(onPopupClose)
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+	"github.com/limetext/text"
+)
+
+// Headless frontend clicking every link of the popups it shows
+type popupFrontend struct {
+	backend.DummyFrontend
+	shown, updated, hidden int
+	phantoms               []*ViewPhantom
+}
+
+func (f *popupFrontend) ShowPopup(p *Popup) {
+	f.shown++
+	for _, href := range p.Doc.Links() {
+		p.Navigate(href)
+	}
+}
+
+func (f *popupFrontend) UpdatePopup(p *Popup) {
+	f.updated++
+}
+
+func (f *popupFrontend) HidePopup(p *Popup) {
+	f.hidden++
+}
+
+func (f *popupFrontend) UpdatePhantoms(v *backend.View, phantoms []*ViewPhantom) {
+	f.phantoms = phantoms
+}
+
+func TestShowPopup(t *testing.T) {
+	l := py.NewLock()
+	defer l.Unlock()
+
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.NewFile()
+	pv, err := toPython(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pv.Decref()
+	o := pv.(*View)
+
+	nav, onNavigate := collector(t)
+	hide, app := collector(t)
+	app.Base().CallFunctionObjArgs(py.None)
+	onHide, err := hide.Base().GetAttrString("pop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := toPython(`<a href="one">1</a> <a href="two">2</a>`)
+	flags, _ := toPython(HIDE_ON_MOUSE_MOVE)
+	loc, _ := toPython(-1)
+	w1, _ := toPython(100)
+	h1, _ := toPython(50)
+	tu, err := py.PackTuple(content, flags, loc, w1, h1, onNavigate, onHide)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fe := &popupFrontend{}
+	withFrontend(fe, func() {
+		if _, err := o.Py_show_popup(tu, nil); err != nil {
+			t.Fatal(err)
+		}
+		p := PopupOf(v)
+		if p == nil {
+			t.Fatal("Expected a visible popup")
+		}
+		if p.Flags != HIDE_ON_MOUSE_MOVE || p.MaxWidth != 100 || p.MaxHeight != 50 {
+			t.Errorf("Unexpected popup %+v", p)
+		}
+		if err := p.Update("<b>"); err != nil {
+			t.Error(err)
+		}
+		if err := p.Update("</b>"); err == nil {
+			t.Error("Expected an error updating with invalid html")
+		}
+		p.Hide()
+		p.Hide()
+		p.Navigate("three")
+	})
+	if got, exp := collected(t, nav), (List{"one", "two"}); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected on_navigate calls %v, but got %v", exp, got)
+	}
+	if got := collected(t, hide); len(got) != 0 {
+		t.Error("Expected on_hide to be called")
+	}
+	if fe.shown != 1 || fe.updated != 1 || fe.hidden != 1 {
+		t.Errorf("Expected the popup to be shown, updated and hidden once, but got %+v", fe)
+	}
+	if PopupOf(v) != nil {
+		t.Error("Expected no visible popup")
+	}
+}
+
+func TestHover(t *testing.T) {
+	var got []int
+	defer func(old HoverEvent) { OnHover = old }(OnHover)
+	OnHover.Add(func(v *backend.View, pt, zone int) {
+		got = append(got, pt, zone)
+	})
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.NewFile()
+	Hover(v, 10, HOVER_GUTTER)
+	if exp := []int{10, HOVER_GUTTER}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected hover %v, but got %v", exp, got)
+	}
+}
+
+func TestPhantoms(t *testing.T) {
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.NewFile()
+
+	fe := &popupFrontend{}
+	withFrontend(fe, func() {
+		a := AddPhantom(&ViewPhantom{View: v, Key: "lint", Content: "a"}, text.Region{A: 0, B: 0})
+		b := AddPhantom(&ViewPhantom{View: v, Key: "doc", Content: "b"}, text.Region{A: 0, B: 0})
+		if len(fe.phantoms) != 2 || fe.phantoms[0].ID != a || fe.phantoms[1].ID != b {
+			t.Errorf("Expected phantoms %d and %d, but got %v", a, b, fe.phantoms)
+		}
+		if phantomByID(v, b) == nil || phantomByID(v, b+1) != nil {
+			t.Error("Unexpected phantom lookup")
+		}
+		ErasePhantoms(v, func(p *ViewPhantom) bool { return p.Key == "lint" })
+		if len(fe.phantoms) != 1 || fe.phantoms[0].ID != b {
+			t.Errorf("Expected phantom %d, but got %v", b, fe.phantoms)
+		}
+		v.Close()
+	})
+	if ps := Phantoms(v); len(ps) != 0 {
+		t.Errorf("Expected no phantoms after closing, but got %v", ps)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/project"
+)
+
+var projects = struct {
+	sync.Mutex
+	m map[*backend.Window]*project.Project
+}{m: make(map[*backend.Window]*project.Project)}
+
+// ProjectOf returns the project of the window, nil if the window has no
+// project. The project file opened by the backend is loaded on first call.
+func ProjectOf(w *backend.Window) *project.Project {
+	projects.Lock()
+	p, ok := projects.m[w]
+	projects.Unlock()
+	if ok {
+		return p
+	}
+	if bp := w.Project(); bp != nil && bp.FileName() != "" {
+		if err := OpenProject(w, bp.FileName()); err != nil {
+			log.Warn("Error loading project %s: %s", bp.FileName(), err)
+			return nil
+		}
+		projects.Lock()
+		defer projects.Unlock()
+		return projects.m[w]
+	}
+	return nil
+}
+
+// OpenProject loads the sublime-project file as the window project.
+func OpenProject(w *backend.Window, filename string) error {
+	p, err := project.Load(filename)
+	if err != nil {
+		return err
+	}
+	setProject(w, p)
+	return nil
+}
+
+// SetProjectData replaces the window project content, the window gets a
+// project without a file if it doesn't have any.
+func SetProjectData(w *backend.Window, data map[string]interface{}) error {
+	p := ProjectOf(w)
+	if p == nil {
+		p = project.New()
+	}
+	old := p.Settings()
+	oldFolders := p.Folders()
+	err := p.SetData(data)
+	setProject(w, p)
+	// clearing keys removed from project settings
+	if bp := w.Project(); bp != nil {
+		set := p.Settings()
+		for k := range old {
+			if _, ok := set[k]; !ok {
+				bp.Settings().Erase(k)
+			}
+		}
+		for _, f := range oldFolders {
+			if !hasFolder(p.Folders(), f.Path) {
+				bp.RemoveFolder(f.Path)
+			}
+		}
+	}
+	return err
+}
+
+func hasFolder(folders []project.Folder, path string) bool {
+	for _, f := range folders {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Syncs the backend project of the window with the project. Project settings
+// are kept in the backend project settings which are the parent of window
+// settings.
+func setProject(w *backend.Window, p *project.Project) {
+	projects.Lock()
+	projects.m[w] = p
+	projects.Unlock()
+
+	bp := w.Project()
+	if bp == nil {
+		return
+	}
+	for k, v := range p.Settings() {
+		bp.Settings().Set(k, fromJSON(v))
+	}
+	folders := bp.Folders()
+	for _, f := range p.Folders() {
+		if !hasString(folders, f.Path) {
+			bp.AddFolder(f.Path)
+		}
+	}
+}
+
+func hasString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Converts values decoded from json to the types toPython understands.
+// Numbers without fraction become ints.
+func fromJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < math.MaxInt32 {
+			return int(t)
+		}
+		return t
+	case map[string]interface{}:
+		ret := make(backend.Args, len(t))
+		for k, v := range t {
+			ret[k] = fromJSON(v)
+		}
+		return ret
+	case []interface{}:
+		ret := make(List, len(t))
+		for i, v := range t {
+			ret[i] = fromJSON(v)
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+// Folders returns the paths of the window project folders.
+func Folders(w *backend.Window) []string {
+	ret := make([]string, 0)
+	if p := ProjectOf(w); p != nil {
+		for _, f := range p.Folders() {
+			ret = append(ret, f.Path)
+		}
+	}
+	return ret
+}
+
+// ExtractVariables returns the variables of the window which are used in
+// build systems and snippets like $file and $project_path.
+func ExtractVariables(w *backend.Window) map[string]string {
+	ed := backend.GetEditor()
+	vars := map[string]string{
+		"packages": ed.PackagesPath(),
+		"platform": ed.Plat(),
+	}
+	splitPath := func(prefix, fn string) {
+		if fn == "" {
+			return
+		}
+		base := filepath.Base(fn)
+		ext := filepath.Ext(base)
+		vars[prefix] = fn
+		vars[prefix+"_path"] = filepath.Dir(fn)
+		vars[prefix+"_name"] = base
+		vars[prefix+"_base_name"] = strings.TrimSuffix(base, ext)
+		vars[prefix+"_extension"] = strings.TrimPrefix(ext, ".")
+	}
+	if v := w.ActiveView(); v != nil {
+		splitPath("file", v.FileName())
+	}
+	if p := ProjectOf(w); p != nil {
+		splitPath("project", p.FileName())
+	}
+	if folders := Folders(w); len(folders) > 0 {
+		vars["folder"] = folders[0]
+	}
+	for k, v := range vars {
+		if v == "" {
+			delete(vars, k)
+		}
+	}
+	return vars
+}
+
+func (o *Window) Py_folders() (py.Object, error) {
+	return toPython(Folders(o.data))
+}
+
+func (o *Window) Py_project_file_name() (py.Object, error) {
+	if p := ProjectOf(o.data); p != nil && p.FileName() != "" {
+		return toPython(p.FileName())
+	}
+	return toPython(nil)
+}
+
+func (o *Window) Py_project_data() (py.Object, error) {
+	p := ProjectOf(o.data)
+	if p == nil {
+		return toPython(nil)
+	}
+	return toPython(fromJSON(p.Data()))
+}
+
+func (o *Window) Py_set_project_data(tu *py.Tuple) (py.Object, error) {
+	v, err := tu.GetItem(0)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := v2.(backend.Args)
+	if !ok {
+		return nil, fmt.Errorf("Expected type dict for set_project_data(), not %s", v.Type())
+	}
+	if err := SetProjectData(o.data, data); err != nil {
+		return nil, err
+	}
+	return toPython(nil)
+}
+
+func (o *Window) Py_extract_variables() (py.Object, error) {
+	vars := make(backend.Args)
+	for k, v := range ExtractVariables(o.data) {
+		vars[k] = v
+	}
+	return toPython(vars)
+}
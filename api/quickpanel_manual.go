@@ -0,0 +1,350 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+)
+
+// Flags accepted by show_quick_panel
+const (
+	MONOSPACE_FONT = 1 << iota
+	KEEP_OPEN_ON_FOCUS_LOST
+)
+
+type (
+	// PanelFrontend is implemented by frontends that are able to show quick
+	// and input panels. The frontend answers a request by calling the
+	// request methods, it doesn't matter if it does so synchronously or
+	// later from another goroutine.
+	PanelFrontend interface {
+		ShowQuickPanel(*QuickPanel)
+		ShowInputPanel(*InputPanel)
+	}
+
+	// QuickPanel is a request for showing a list of items and letting the
+	// user pick one of them.
+	QuickPanel struct {
+		Window *backend.Window
+		// Each item has at least one row, the first row is the one that
+		// should be used for filtering.
+		Items    [][]string
+		Flags    int
+		Selected int
+		lock     sync.Mutex
+		done     bool
+		onSelect py.Object
+		// optional, might be nil
+		onHighlight py.Object
+	}
+
+	// InputPanel is a request for letting the user enter a line of text.
+	InputPanel struct {
+		Window   *backend.Window
+		Caption  string
+		Initial  string
+		lock     sync.Mutex
+		done     bool
+		onDone   py.Object
+		onChange py.Object
+		onCancel py.Object
+	}
+)
+
+// Select reports the index of the chosen item, -1 means the panel was
+// cancelled. The on_select callback is called only once no matter how many
+// times Select or Cancel are called.
+func (q *QuickPanel) Select(index int) {
+	q.lock.Lock()
+	if q.done {
+		q.lock.Unlock()
+		return
+	}
+	q.done = true
+	q.lock.Unlock()
+
+	if index < -1 || index >= len(q.Items) {
+		log.Warn("Quick panel selection out of range: %d", index)
+		index = -1
+	}
+
+	l := py.NewLock()
+	defer l.Unlock()
+	pyCallback(q.onSelect, index)
+	q.onSelect.Decref()
+	if q.onHighlight != nil {
+		q.onHighlight.Decref()
+	}
+}
+
+// Cancel closes the panel without choosing any item.
+func (q *QuickPanel) Cancel() {
+	q.Select(-1)
+}
+
+// Highlight reports the item currently highlighted by the user.
+func (q *QuickPanel) Highlight(index int) {
+	q.lock.Lock()
+	skip := q.done || q.onHighlight == nil
+	q.lock.Unlock()
+	if skip {
+		return
+	}
+
+	l := py.NewLock()
+	defer l.Unlock()
+	pyCallback(q.onHighlight, index)
+}
+
+// Done reports the text the user entered.
+func (p *InputPanel) Done(text string) {
+	if !p.finish() {
+		return
+	}
+
+	l := py.NewLock()
+	defer l.Unlock()
+	pyCallback(p.onDone, text)
+	p.release()
+}
+
+// Change reports the current text on each modification.
+func (p *InputPanel) Change(text string) {
+	p.lock.Lock()
+	skip := p.done || p.onChange == nil
+	p.lock.Unlock()
+	if skip {
+		return
+	}
+
+	l := py.NewLock()
+	defer l.Unlock()
+	pyCallback(p.onChange, text)
+}
+
+// Cancel closes the panel without entering any text.
+func (p *InputPanel) Cancel() {
+	if !p.finish() {
+		return
+	}
+
+	l := py.NewLock()
+	defer l.Unlock()
+	if p.onCancel != nil {
+		pyCallback(p.onCancel)
+	}
+	p.release()
+}
+
+func (p *InputPanel) finish() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.done {
+		return false
+	}
+	p.done = true
+	return true
+}
+
+func (p *InputPanel) release() {
+	for _, cb := range []py.Object{p.onDone, p.onChange, p.onCancel} {
+		if cb != nil {
+			cb.Decref()
+		}
+	}
+}
+
+// Sends the panel to the frontend, if the frontend can't show panels the
+// request is cancelled right away.
+func showQuickPanel(q *QuickPanel) {
+	if fe, ok := backend.GetEditor().Frontend().(PanelFrontend); ok {
+		fe.ShowQuickPanel(q)
+		return
+	}
+	log.Fine("Frontend doesn't support quick panels, cancelling")
+	q.Cancel()
+}
+
+func showInputPanel(p *InputPanel) {
+	if fe, ok := backend.GetEditor().Frontend().(PanelFrontend); ok {
+		fe.ShowInputPanel(p)
+		return
+	}
+	log.Fine("Frontend doesn't support input panels, cancelling")
+	p.Cancel()
+}
+
+// Calls the python callable with the given arguments, errors are only logged
+// since there is no python code waiting for them.
+func pyCallback(cb py.Object, args ...interface{}) {
+	pyargs := make([]py.Object, 0, len(args))
+	defer func() {
+		for _, a := range pyargs {
+			a.Decref()
+		}
+	}()
+	for _, a := range args {
+		if v, err := toPython(a); err != nil {
+			log.Error(err)
+			return
+		} else {
+			pyargs = append(pyargs, v)
+		}
+	}
+	if ret, err := callPython(cb, pyargs...); err != nil {
+		reportError(err)
+	} else if ret != nil {
+		ret.Decref()
+	}
+}
+
+// Returns the argument at position i or the keyword argument with the given
+// name, ok is false if neither of them were given or the value is None.
+func pyArg(tu *py.Tuple, kw *py.Dict, i int64, name string) (v py.Object, ok bool) {
+	if tu != nil && i < tu.Size() {
+		if item, err := tu.GetItem(i); err == nil {
+			v, ok = item, true
+		}
+	} else if kw != nil {
+		if ms, err := kw.MapString(); err == nil {
+			v, ok = ms[name]
+		}
+	}
+	if _, none := v.(*py.NoneObject); none {
+		return nil, false
+	}
+	return
+}
+
+func pyIntArg(tu *py.Tuple, kw *py.Dict, i int64, name string, def int) (int, error) {
+	v, ok := pyArg(tu, kw, i, name)
+	if !ok {
+		return def, nil
+	}
+	if v2, ok := v.(*py.Long); !ok {
+		return def, fmt.Errorf("Expected type int for %s, not %s", name, v.Type())
+	} else {
+		return int(v2.Int64()), nil
+	}
+}
+
+func pyStringArg(tu *py.Tuple, kw *py.Dict, i int64, name string, def string) (string, error) {
+	v, ok := pyArg(tu, kw, i, name)
+	if !ok {
+		return def, nil
+	}
+	if v2, ok := v.(*py.Unicode); !ok {
+		return def, fmt.Errorf("Expected type str for %s, not %s", name, v.Type())
+	} else {
+		return v2.String(), nil
+	}
+}
+
+func quickPanelItems(v py.Object) ([][]string, error) {
+	v2, err := fromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	var items []interface{}
+	switch t := v2.(type) {
+	case List:
+		items = t
+	case Tuple:
+		items = t
+	default:
+		return nil, fmt.Errorf("Expected a list of items for show_quick_panel, not %s", v.Type())
+	}
+
+	ret := make([][]string, len(items))
+	for i, item := range items {
+		switch t := item.(type) {
+		case string:
+			ret[i] = []string{t}
+		case List:
+			for _, row := range t {
+				if s, ok := row.(string); !ok {
+					return nil, fmt.Errorf("Expected only strings in quick panel item %d", i)
+				} else {
+					ret[i] = append(ret[i], s)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("Expected string or list of strings for quick panel item %d", i)
+		}
+	}
+	return ret, nil
+}
+
+func (o *Window) Py_show_quick_panel(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	q := &QuickPanel{Window: o.data}
+	if v, ok := pyArg(tu, kw, 0, "items"); !ok {
+		return nil, fmt.Errorf("show_quick_panel requires a list of items")
+	} else if items, err := quickPanelItems(v); err != nil {
+		return nil, err
+	} else {
+		q.Items = items
+	}
+	if v, ok := pyArg(tu, kw, 1, "on_select"); !ok {
+		return nil, fmt.Errorf("show_quick_panel requires an on_select callback")
+	} else {
+		q.onSelect = v
+	}
+	var err error
+	if q.Flags, err = pyIntArg(tu, kw, 2, "flags", 0); err != nil {
+		return nil, err
+	}
+	if q.Selected, err = pyIntArg(tu, kw, 3, "selected_index", -1); err != nil {
+		return nil, err
+	}
+	if v, ok := pyArg(tu, kw, 4, "on_highlight"); ok {
+		q.onHighlight = v
+		q.onHighlight.Incref()
+	}
+	q.onSelect.Incref()
+
+	showQuickPanel(q)
+	return toPython(nil)
+}
+
+func (o *Window) Py_show_input_panel(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	var err error
+	p := &InputPanel{Window: o.data}
+	if p.Caption, err = pyStringArg(tu, kw, 0, "caption", ""); err != nil {
+		return nil, err
+	}
+	if p.Initial, err = pyStringArg(tu, kw, 1, "initial_text", ""); err != nil {
+		return nil, err
+	}
+	callbacks := []struct {
+		name string
+		cb   *py.Object
+	}{
+		{"on_done", &p.onDone},
+		{"on_change", &p.onChange},
+		{"on_cancel", &p.onCancel},
+	}
+	for i, c := range callbacks {
+		if v, ok := pyArg(tu, kw, int64(i+2), c.name); ok {
+			*c.cb = v
+		}
+	}
+	if p.onDone == nil {
+		return nil, fmt.Errorf("show_input_panel requires an on_done callback")
+	}
+	for _, c := range callbacks {
+		if *c.cb != nil {
+			(*c.cb).Incref()
+		}
+	}
+
+	showInputPanel(p)
+	// TODO: sublime returns the view of the input panel
+	return toPython(nil)
+}
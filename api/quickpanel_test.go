@@ -0,0 +1,176 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+)
+
+// Headless frontend answering panels with scripted values
+type panelFrontend struct {
+	backend.DummyFrontend
+	highlights []int
+	selects    []int
+	changes    []string
+	inputs     []string
+}
+
+func (f *panelFrontend) ShowQuickPanel(q *QuickPanel) {
+	for _, i := range f.highlights {
+		q.Highlight(i)
+	}
+	if len(f.selects) == 0 {
+		q.Cancel()
+		return
+	}
+	q.Select(f.selects[0])
+	f.selects = f.selects[1:]
+}
+
+func (f *panelFrontend) ShowInputPanel(p *InputPanel) {
+	for _, s := range f.changes {
+		p.Change(s)
+	}
+	if len(f.inputs) == 0 {
+		p.Cancel()
+		return
+	}
+	p.Done(f.inputs[0])
+	f.inputs = f.inputs[1:]
+}
+
+func withFrontend(fe backend.Frontend, f func()) {
+	ed := backend.GetEditor()
+	old := ed.Frontend()
+	ed.SetFrontend(fe)
+	defer ed.SetFrontend(old)
+	f()
+}
+
+// Returns a python list and its append method which could be used as a
+// callback for collecting the results
+func collector(t *testing.T) (*py.List, py.Object) {
+	l, err := py.NewList(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := l.Base().GetAttrString("append")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, app
+}
+
+func collected(t *testing.T, l *py.List) List {
+	v, err := fromPython(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.(List)
+}
+
+func activeWindow(t *testing.T) *Window {
+	w, err := toPython(backend.GetEditor().ActiveWindow())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.(*Window)
+}
+
+func TestShowQuickPanel(t *testing.T) {
+	l := py.NewLock()
+	defer l.Unlock()
+
+	tests := []struct {
+		fe  *panelFrontend
+		sel List
+		hl  List
+	}{
+		{&panelFrontend{selects: []int{1}}, List{1}, List{}},
+		{&panelFrontend{}, List{-1}, List{}},
+		{&panelFrontend{selects: []int{5}}, List{-1}, List{}},
+		{&panelFrontend{highlights: []int{0, 1}, selects: []int{0}}, List{0}, List{0, 1}},
+	}
+	w := activeWindow(t)
+	defer w.Decref()
+	for i, test := range tests {
+		sel, onSelect := collector(t)
+		hl, onHighlight := collector(t)
+		items, _ := toPython(List{"a", List{"b", "b's description"}})
+		flags, _ := toPython(MONOSPACE_FONT)
+		idx, _ := toPython(-1)
+		tu, err := py.PackTuple(items, onSelect, flags, idx, onHighlight)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		withFrontend(test.fe, func() {
+			if _, err := w.Py_show_quick_panel(tu, nil); err != nil {
+				t.Errorf("Test %d: %s", i, err)
+			}
+		})
+		if got := collected(t, sel); !reflect.DeepEqual(got, test.sel) {
+			t.Errorf("Test %d: Expected on_select calls %v, but got %v", i, test.sel, got)
+		}
+		if got := collected(t, hl); !reflect.DeepEqual(got, test.hl) {
+			t.Errorf("Test %d: Expected on_highlight calls %v, but got %v", i, test.hl, got)
+		}
+	}
+}
+
+func TestShowInputPanel(t *testing.T) {
+	l := py.NewLock()
+	defer l.Unlock()
+
+	tests := []struct {
+		fe        *panelFrontend
+		done      List
+		change    List
+		cancelled bool
+	}{
+		{&panelFrontend{inputs: []string{"hello"}}, List{"hello"}, List{}, false},
+		{&panelFrontend{changes: []string{"h", "he"}, inputs: []string{"he"}}, List{"he"}, List{"h", "he"}, false},
+		{&panelFrontend{}, List{}, List{}, true},
+	}
+	w := activeWindow(t)
+	defer w.Decref()
+	for i, test := range tests {
+		done, onDone := collector(t)
+		change, onChange := collector(t)
+		// on_cancel takes no arguments so we use pop of a list with one
+		// item, the list will be empty after cancelling
+		cancel, app := collector(t)
+		app.Base().CallFunctionObjArgs(py.None)
+		onCancel, err := cancel.Base().GetAttrString("pop")
+		if err != nil {
+			t.Fatal(err)
+		}
+		caption, _ := toPython("caption")
+		initial, _ := toPython("")
+		tu, err := py.PackTuple(caption, initial, onDone, onChange, onCancel)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		withFrontend(test.fe, func() {
+			if _, err := w.Py_show_input_panel(tu, nil); err != nil {
+				t.Errorf("Test %d: %s", i, err)
+			}
+		})
+		if got := collected(t, done); !reflect.DeepEqual(got, test.done) {
+			t.Errorf("Test %d: Expected on_done calls %v, but got %v", i, test.done, got)
+		}
+		if got := collected(t, change); !reflect.DeepEqual(got, test.change) {
+			t.Errorf("Test %d: Expected on_change calls %v, but got %v", i, test.change, got)
+		}
+		if got := len(collected(t, cancel)) == 0; got != test.cancelled {
+			t.Errorf("Test %d: Expected cancelled %v, but got %v", i, test.cancelled, got)
+		}
+	}
+}
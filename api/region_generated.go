@@ -28,47 +28,9 @@ var _regionClass = py.Class{
 type Region struct {
 	py.BaseObject
 	data text.Region
+	xpos int
 }
 
-func (o *Region) PyInit(args *py.Tuple, kwds *py.Dict) error {
-	if args.Size() > 2 {
-		return fmt.Errorf("Expected at most 2 arguments")
-	}
-
-	if args.Size() > 0 {
-		if v, err := args.GetItem(0); err != nil {
-			return err
-		} else {
-			if v3, err2 := fromPython(v); err2 != nil {
-				return err2
-			} else {
-				if v2, ok := v3.(int); !ok {
-					return fmt.Errorf("Expected type int for Region.A, not %s", v.Type())
-				} else {
-					o.data.A = v2
-				}
-			}
-		}
-	}
-
-	if args.Size() > 1 {
-		if v, err := args.GetItem(1); err != nil {
-			return err
-		} else {
-			if v3, err2 := fromPython(v); err2 != nil {
-				return err2
-			} else {
-				if v2, ok := v3.(int); !ok {
-					return fmt.Errorf("Expected type int for Region.B, not %s", v.Type())
-				} else {
-					o.data.B = v2
-				}
-			}
-		}
-	}
-
-	return nil
-}
 func (o *Region) Py_begin() (py.Object, error) {
 	ret0 := o.data.Begin()
 	var err error
@@ -81,34 +43,6 @@ func (o *Region) Py_begin() (py.Object, error) {
 	return pyret0, err
 }
 
-func (o *Region) Py_contains(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 int
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(int); !ok {
-				return nil, fmt.Errorf("Expected type int for text.Region.Contains() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	ret0 := o.data.Contains(arg1)
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
-		return nil, err
-	}
-	return pyret0, err
-}
-
 func (o *Region) Py_cover(tu *py.Tuple) (py.Object, error) {
 	var (
 		arg1 text.Region
@@ -161,62 +95,6 @@ func (o *Region) Py_end() (py.Object, error) {
 	return pyret0, err
 }
 
-func (o *Region) Py_intersection(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 text.Region
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.Region); !ok {
-				return nil, fmt.Errorf("Expected type text.Region for text.Region.Intersection() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	ret0 := o.data.Intersection(arg1)
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
-		return nil, err
-	}
-	return pyret0, err
-}
-
-func (o *Region) Py_intersects(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 text.Region
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.Region); !ok {
-				return nil, fmt.Errorf("Expected type text.Region for text.Region.Intersects() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	ret0 := o.data.Intersects(arg1)
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
-		return nil, err
-	}
-	return pyret0, err
-}
-
 func (o *Region) Py_size() (py.Object, error) {
 	ret0 := o.data.Size()
 	var err error
@@ -11,35 +11,165 @@ import (
 	"github.com/limetext/text"
 )
 
+// Converts a Region or an (a, b) tuple or list of ints to a text.Region.
+func regionFromPython(o py.Object) (text.Region, error) {
+	if r, ok := o.(*Region); ok {
+		return r.data, nil
+	}
+	v, err := fromPython(o)
+	if err != nil {
+		return text.Region{}, err
+	}
+	var items []interface{}
+	switch t := v.(type) {
+	case Tuple:
+		items = t
+	case List:
+		items = t
+	default:
+		return text.Region{}, fmt.Errorf("Expected a Region or a tuple of two ints, not %s", o.Type())
+	}
+	if len(items) != 2 {
+		return text.Region{}, fmt.Errorf("Invalid tuple size: %d != 2", len(items))
+	}
+	a, ok := items[0].(int)
+	b, ok2 := items[1].(int)
+	if !ok || !ok2 {
+		return text.Region{}, fmt.Errorf("Expected a Region or a tuple of two ints, not %s", o.Type())
+	}
+	return text.Region{A: a, B: b}, nil
+}
+
+func regionArg(tu *py.Tuple, i int64, name string) (text.Region, error) {
+	v, ok := pyArg(tu, nil, i, name)
+	if !ok {
+		return text.Region{}, fmt.Errorf("Missing %s argument", name)
+	}
+	return regionFromPython(v)
+}
+
+// Reports whether the regions overlap or are the same, regions only
+// touching each other don't intersect.
+func regionIntersects(r, r2 text.Region) bool {
+	b, e, b2, e2 := r.Begin(), r.End(), r2.Begin(), r2.End()
+	return (b == b2 && e == e2) ||
+		(b2 > b && b2 < e) || (e2 > b && e2 < e) ||
+		(b > b2 && b < e2) || (e > b2 && e < e2)
+}
+
+// Returns the part of the region also in r2, the empty region at 0 when
+// there isn't one.
+func regionIntersection(r, r2 text.Region) text.Region {
+	if r.End() <= r2.Begin() || r.Begin() >= r2.End() {
+		return text.Region{}
+	}
+	ret := text.Region{A: r.Begin(), B: r.End()}
+	if b2 := r2.Begin(); b2 > ret.A {
+		ret.A = b2
+	}
+	if e2 := r2.End(); e2 < ret.B {
+		ret.B = e2
+	}
+	return ret
+}
+
+func (o *Region) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	if args.Size() > 3 {
+		return fmt.Errorf("Expected at most 3 arguments")
+	}
+	var err error
+	if o.data.A, err = pyIntArg(args, kwds, 0, "a", 0); err != nil {
+		return err
+	}
+	if o.data.B, err = pyIntArg(args, kwds, 1, "b", 0); err != nil {
+		return err
+	}
+	o.xpos, err = pyIntArg(args, kwds, 2, "xpos", -1)
+	return err
+}
+
+func (o *Region) PyGet_xpos() (py.Object, error) {
+	return toPython(o.xpos)
+}
+
+func (o *Region) PySet_xpos(v py.Object) error {
+	x, err := fromPython(v)
+	if err != nil {
+		return err
+	}
+	xpos, ok := x.(int)
+	if !ok {
+		return fmt.Errorf("Expected type int for Region.xpos, not %s", v.Type())
+	}
+	o.xpos = xpos
+	return nil
+}
+
+func (o *Region) Py_contains(tu *py.Tuple) (py.Object, error) {
+	v, ok := pyArg(tu, nil, 0, "x")
+	if !ok {
+		return nil, fmt.Errorf("Missing x argument")
+	}
+	if r, ok := v.(*Region); ok {
+		return toPython(o.data.Begin() <= r.data.Begin() && r.data.End() <= o.data.End())
+	}
+	pt, err := pyIntArg(tu, nil, 0, "x", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(o.data.Begin() <= pt && pt <= o.data.End())
+}
+
+func (o *Region) Py_intersects(tu *py.Tuple) (py.Object, error) {
+	r, err := regionArg(tu, 0, "region")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(regionIntersects(o.data, r))
+}
+
+func (o *Region) Py_intersection(tu *py.Tuple) (py.Object, error) {
+	r, err := regionArg(tu, 0, "region")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(regionIntersection(o.data, r))
+}
+
+func (o *Region) PySeqLen() int64 {
+	return int64(o.data.Size())
+}
+
 func (o *Region) PyRichCompare(other py.Object, op py.Op) (py.Object, error) {
-	if op != py.EQ && op != py.NE {
-		return nil, fmt.Errorf("Can only do EQ and NE compares")
-	}
-	var o2 text.Region
-	switch t := other.(type) {
-	case *Region:
-		o2 = t.data
-	case *py.Tuple:
-		if s := t.Size(); s != 2 {
-			return nil, fmt.Errorf("Invalid tuple size: %d != 2", s)
-		}
-		if a, err := t.GetItem(0); err != nil {
-			return nil, err
-		} else if b, err := t.GetItem(1); err != nil {
-			return nil, err
-		} else if a2, ok := a.(*py.Long); !ok {
-			return nil, fmt.Errorf("Can only compare with int tuples and other regions")
-		} else if b2, ok := b.(*py.Long); !ok {
-			return nil, fmt.Errorf("Can only compare with int tuples and other regions")
-		} else {
-			o2 = text.Region{A: int(a2.Int64()), B: int(b2.Int64())}
+	o2, err := regionFromPython(other)
+	if err != nil {
+		// other types are never equal to regions
+		switch op {
+		case py.EQ:
+			return toPython(false)
+		case py.NE:
+			return toPython(true)
 		}
-	default:
 		return nil, fmt.Errorf("Can only compare with int tuples and other regions")
 	}
-	if op == py.EQ {
+	// regions are ordered by their beginning, then by their end
+	cmp := o.data.Begin() - o2.Begin()
+	if cmp == 0 {
+		cmp = o.data.End() - o2.End()
+	}
+	switch op {
+	case py.EQ:
 		return toPython(o.data == o2)
-	} else {
+	case py.NE:
 		return toPython(o.data != o2)
+	case py.LT:
+		return toPython(cmp < 0)
+	case py.LE:
+		return toPython(cmp <= 0)
+	case py.GT:
+		return toPython(cmp > 0)
+	case py.GE:
+		return toPython(cmp >= 0)
 	}
+	return nil, fmt.Errorf("Unknown compare operator %d", op)
 }
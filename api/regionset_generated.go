@@ -33,81 +33,11 @@ type RegionSet struct {
 func (o *RegionSet) PyInit(args *py.Tuple, kwds *py.Dict) error {
 	return fmt.Errorf("Can't initialize type RegionSet")
 }
-func (o *RegionSet) Py_add(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 text.Region
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.Region); !ok {
-				return nil, fmt.Errorf("Expected type text.Region for text.RegionSet.Add() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	o.data.Add(arg1)
-	return toPython(nil)
-}
-
-func (o *RegionSet) Py_add_all(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 []text.Region
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.([]text.Region); !ok {
-				return nil, fmt.Errorf("Expected type []text.Region for text.RegionSet.AddAll() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	o.data.AddAll(arg1)
-	return toPython(nil)
-}
-
 func (o *RegionSet) Py_clear() (py.Object, error) {
 	o.data.Clear()
 	return toPython(nil)
 }
 
-func (o *RegionSet) Py_contains(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 text.Region
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.Region); !ok {
-				return nil, fmt.Errorf("Expected type text.Region for text.RegionSet.Contains() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	ret0 := o.data.Contains(arg1)
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
-		return nil, err
-	}
-	return pyret0, err
-}
-
 func (o *RegionSet) PySeqGet(arg0 int64) (py.Object, error) {
 	var (
 		pyret0 py.Object
@@ -129,24 +59,3 @@ func (o *RegionSet) PySeqGet(arg0 int64) (py.Object, error) {
 func (o *RegionSet) PySeqLen() int64 {
 	return int64(o.data.Len())
 }
-
-func (o *RegionSet) Py_subtract(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 text.Region
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.Region); !ok {
-				return nil, fmt.Errorf("Expected type text.Region for text.RegionSet.Subtract() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	o.data.Subtract(arg1)
-	return toPython(nil)
-}
@@ -0,0 +1,165 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/limetext/gopy"
+	"github.com/limetext/text"
+)
+
+// Adds the region to the set like sublime's Selection does, merging it with
+// the regions it overlaps and the ones it's adjacent to.
+func addRegion(rs *text.RegionSet, r text.Region) {
+	var keep []text.Region
+	merged := false
+	for _, r2 := range rs.Regions() {
+		if r2.Begin() <= r.End() && r.Begin() <= r2.End() {
+			r = r.Cover(r2)
+			merged = true
+		} else {
+			keep = append(keep, r2)
+		}
+	}
+	if !merged {
+		rs.Add(r)
+		return
+	}
+	rs.Clear()
+	rs.AddAll(append(keep, r))
+}
+
+// Returns the regions of a RegionSet, or of a list or tuple of regions and
+// (a, b) tuples.
+func regionListFromPython(o py.Object) ([]text.Region, error) {
+	var items []py.Object
+	switch t := o.(type) {
+	case *RegionSet:
+		return t.data.Regions(), nil
+	case *py.List:
+		items = t.Slice()
+	case *py.Tuple:
+		items = t.Slice()
+	default:
+		return nil, fmt.Errorf("Expected a list of regions, not %s", o.Type())
+	}
+	ret := make([]text.Region, len(items))
+	for i, item := range items {
+		r, err := regionFromPython(item)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = r
+	}
+	return ret, nil
+}
+
+func (o *RegionSet) Py_add(tu *py.Tuple) (py.Object, error) {
+	r, err := regionArg(tu, 0, "x")
+	if err != nil {
+		return nil, err
+	}
+	addRegion(o.data, r)
+	return toPython(nil)
+}
+
+func (o *RegionSet) Py_add_all(tu *py.Tuple) (py.Object, error) {
+	v, ok := pyArg(tu, nil, 0, "regions")
+	if !ok {
+		return nil, fmt.Errorf("Missing regions argument")
+	}
+	regions, err := regionListFromPython(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range regions {
+		addRegion(o.data, r)
+	}
+	return toPython(nil)
+}
+
+func (o *RegionSet) Py_subtract(tu *py.Tuple) (py.Object, error) {
+	r, err := regionArg(tu, 0, "region")
+	if err != nil {
+		return nil, err
+	}
+	o.data.Subtract(r)
+	return toPython(nil)
+}
+
+func (o *RegionSet) Py_contains(tu *py.Tuple) (py.Object, error) {
+	r, err := regionArg(tu, 0, "region")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(o.data.Contains(r))
+}
+
+func (o *RegionSet) PySeqContains(v py.Object) (bool, error) {
+	r, err := regionFromPython(v)
+	if err != nil {
+		return false, err
+	}
+	return o.data.Contains(r), nil
+}
+
+func (o *RegionSet) PyMapLen() int64 {
+	return o.PySeqLen()
+}
+
+// Indexes the set with negative indices counting from the end, or slices it
+// into a list of regions.
+func (o *RegionSet) PyMapGet(key py.Object) (py.Object, error) {
+	l := o.data.Len()
+	if _, ok := key.(*py.Long); ok {
+		i, err := fromPython(key)
+		if err != nil {
+			return nil, err
+		}
+		idx := i.(int)
+		if idx < 0 {
+			idx += l
+		}
+		return o.PySeqGet(int64(idx))
+	}
+	// slices clip their bounds to the length themselves
+	ind, err := key.Base().CallMethodObjArgs("indices", py.NewLong(int64(l)))
+	if err != nil {
+		return nil, py.NewError(py.TypeError, "RegionSet indices must be integers or slices, not %s", key.Type())
+	}
+	defer ind.Decref()
+	v, err := fromPython(ind)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := v.(Tuple)
+	if !ok || len(t) != 3 {
+		return nil, fmt.Errorf("Unexpected slice indices %v", v)
+	}
+	start, stop, step := t[0].(int), t[1].(int), t[2].(int)
+	var ret []text.Region
+	for i := start; (step > 0 && i < stop) || (step < 0 && i > stop); i += step {
+		ret = append(ret, o.data.Get(i))
+	}
+	return regionsToPython(ret)
+}
+
+func (o *RegionSet) PyRichCompare(other py.Object, op py.Op) (py.Object, error) {
+	if op != py.EQ && op != py.NE {
+		return nil, fmt.Errorf("Can only do EQ and NE compares")
+	}
+	regions, err := regionListFromPython(other)
+	if err != nil {
+		// other types are never equal to sets
+		return toPython(op == py.NE)
+	}
+	rs := o.data.Regions()
+	eq := len(rs) == len(regions)
+	for i := 0; eq && i < len(rs); i++ {
+		eq = rs[i] == regions[i]
+	}
+	return toPython(eq == (op == py.EQ))
+}
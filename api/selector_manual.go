@@ -0,0 +1,103 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/selector"
+	"github.com/limetext/util"
+)
+
+// ScoreSelector returns the score of the selector for the scope at the
+// point of the view, zero if it doesn't match.
+func ScoreSelector(v *backend.View, pt int, sel string) int {
+	return selector.Score(sel, v.ScopeName(pt))
+}
+
+// MatchSelector reports whether the selector matches the scope at the point
+// of the view.
+func MatchSelector(v *backend.View, pt int, sel string) bool {
+	return ScoreSelector(v, pt, sel) > 0
+}
+
+// Handles the "selector" key matching the operand against the scope of the
+// first selection, or of every selection with match_all.
+func onSelectorQueryContext(v *backend.View, key string, op util.Op, operand interface{}, matchAll bool) backend.QueryContextReturn {
+	if key != "selector" {
+		return backend.Unknown
+	}
+	sel, ok := operand.(string)
+	if !ok {
+		return backend.Unknown
+	}
+	rs := v.Sel().Regions()
+	if len(rs) == 0 {
+		return backend.False
+	}
+	if !matchAll {
+		rs = rs[:1]
+	}
+	val := true
+	for _, r := range rs {
+		if !MatchSelector(v, r.B, sel) {
+			val = false
+			break
+		}
+	}
+	switch op {
+	case util.OpEqual:
+		return queryContextReturn(val)
+	case util.OpNotEqual:
+		return queryContextReturn(!val)
+	}
+	return backend.Unknown
+}
+
+func sublime_ScoreSelector(tu *py.Tuple) (py.Object, error) {
+	scope, err := pyStringArg(tu, nil, 0, "scope", "")
+	if err != nil {
+		return nil, err
+	}
+	sel, err := pyStringArg(tu, nil, 1, "selector", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(selector.Score(sel, scope))
+}
+
+func (o *View) Py_score_selector(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	pt, err := pyIntArg(tu, nil, 0, "pt", 0)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := pyStringArg(tu, nil, 1, "selector", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(ScoreSelector(o.data, pt, sel))
+}
+
+func (o *View) Py_match_selector(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	pt, err := pyIntArg(tu, nil, 0, "pt", 0)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := pyStringArg(tu, nil, 1, "selector", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(MatchSelector(o.data, pt, sel))
+}
+
+func init() {
+	backend.OnQueryContext.Add(onSelectorQueryContext)
+}
@@ -33,62 +33,6 @@ type Settings struct {
 func (o *Settings) PyInit(args *py.Tuple, kwds *py.Dict) error {
 	return fmt.Errorf("Can't initialize type Settings")
 }
-func (o *Settings) Py_add_on_change(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 string
-		arg2 text.OnChangeCallback
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(string); !ok {
-				return nil, fmt.Errorf("Expected type string for text.Settings.AddOnChange() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	if v, err := tu.GetItem(1); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.OnChangeCallback); !ok {
-				return nil, fmt.Errorf("Expected type text.OnChangeCallback for text.Settings.AddOnChange() arg2, not %s", v.Type())
-			} else {
-				arg2 = v2
-			}
-		}
-	}
-	o.data.AddOnChange(arg1, arg2)
-	return toPython(nil)
-}
-
-func (o *Settings) Py_clear_on_change(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 string
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(string); !ok {
-				return nil, fmt.Errorf("Expected type string for text.Settings.ClearOnChange() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	o.data.ClearOnChange(arg1)
-	return toPython(nil)
-}
-
 func (o *Settings) Py_erase(tu *py.Tuple) (py.Object, error) {
 	var (
 		arg1 string
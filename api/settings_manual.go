@@ -6,10 +6,19 @@ package api
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/limetext/gopy"
+	"github.com/limetext/text"
 )
 
+// The python callbacks added by add_on_change by settings and key, they are
+// released when clear_on_change removes them.
+var onChangeCallbacks = struct {
+	sync.Mutex
+	m map[*text.Settings]map[string][]py.Object
+}{m: make(map[*text.Settings]map[string][]py.Object)}
+
 func (o *Settings) Py_get(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
 	var (
 		arg1 string
@@ -59,3 +68,47 @@ func (o *Settings) Py_set(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
 	}
 	return toPython(nil)
 }
+
+func (o *Settings) Py_add_on_change(tu *py.Tuple) (py.Object, error) {
+	key, err := pyStringArg(tu, nil, 0, "key", "")
+	if err != nil {
+		return nil, err
+	}
+	cb, ok := pyArg(tu, nil, 1, "on_change")
+	if !ok {
+		return nil, fmt.Errorf("add_on_change requires an on_change callback")
+	}
+	cb.Incref()
+	onChangeCallbacks.Lock()
+	if onChangeCallbacks.m[o.data] == nil {
+		onChangeCallbacks.m[o.data] = make(map[string][]py.Object)
+	}
+	onChangeCallbacks.m[o.data][key] = append(onChangeCallbacks.m[o.data][key], cb)
+	onChangeCallbacks.Unlock()
+	o.data.AddOnChange(key, func(name string) {
+		l := py.NewLock()
+		defer l.Unlock()
+		pyCallback(cb)
+	})
+	return toPython(nil)
+}
+
+func (o *Settings) Py_clear_on_change(tu *py.Tuple) (py.Object, error) {
+	key, err := pyStringArg(tu, nil, 0, "key", "")
+	if err != nil {
+		return nil, err
+	}
+	o.data.ClearOnChange(key)
+
+	onChangeCallbacks.Lock()
+	cbs := onChangeCallbacks.m[o.data][key]
+	delete(onChangeCallbacks.m[o.data], key)
+	if len(onChangeCallbacks.m[o.data]) == 0 {
+		delete(onChangeCallbacks.m, o.data)
+	}
+	onChangeCallbacks.Unlock()
+	for _, cb := range cbs {
+		cb.Decref()
+	}
+	return toPython(nil)
+}
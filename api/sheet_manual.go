@@ -0,0 +1,369 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/sheet"
+)
+
+// Flags accepted by open_file
+const (
+	ENCODED_POSITION = 1 << iota
+	_
+	TRANSIENT
+	FORCE_GROUP
+)
+
+// SheetFrontend is implemented by frontends that are able to show image and
+// html sheets.
+type SheetFrontend interface {
+	FocusSheet(w *backend.Window, s *sheet.Sheet)
+}
+
+var _sheetClass = py.Class{
+	Name:    "sublime.Sheet",
+	Pointer: (*Sheet)(nil),
+}
+
+type Sheet struct {
+	py.BaseObject
+	data *sheet.Sheet
+}
+
+// SheetOf returns the text sheet of the view.
+func SheetOf(v *backend.View) *sheet.Sheet {
+	layouts.Lock()
+	defer layouts.Unlock()
+	if w := v.Window(); w != nil {
+		layoutOf(w)
+	}
+	return viewSheet(v)
+}
+
+// ActiveSheet returns the focused sheet of the window.
+func ActiveSheet(w *backend.Window) *sheet.Sheet {
+	layouts.Lock()
+	defer layouts.Unlock()
+	wl := layoutOf(w)
+	return wl.actives[wl.active]
+}
+
+// Sheets returns the sheets of all the groups of the window.
+func Sheets(w *backend.Window) []*sheet.Sheet {
+	layouts.Lock()
+	defer layouts.Unlock()
+	var ret []*sheet.Sheet
+	for _, ss := range layoutOf(w).groups {
+		ret = append(ret, ss...)
+	}
+	return ret
+}
+
+// SheetsInGroup returns the sheets of the group in tab order.
+func SheetsInGroup(w *backend.Window, group int) []*sheet.Sheet {
+	layouts.Lock()
+	defer layouts.Unlock()
+	wl := layoutOf(w)
+	if !wl.valid(group) {
+		return nil
+	}
+	ret := make([]*sheet.Sheet, len(wl.groups[group]))
+	copy(ret, wl.groups[group])
+	return ret
+}
+
+// ActiveSheetInGroup returns the focused sheet of the group, nil if the
+// group is empty.
+func ActiveSheetInGroup(w *backend.Window, group int) *sheet.Sheet {
+	layouts.Lock()
+	defer layouts.Unlock()
+	wl := layoutOf(w)
+	if !wl.valid(group) {
+		return nil
+	}
+	return wl.actives[group]
+}
+
+// TransientSheetInGroup returns the preview sheet of the group, nil if there
+// isn't any.
+func TransientSheetInGroup(w *backend.Window, group int) *sheet.Sheet {
+	for _, s := range SheetsInGroup(w, group) {
+		if s.IsTransient() {
+			return s
+		}
+	}
+	return nil
+}
+
+// TransientViewInGroup returns the view of the preview sheet of the group,
+// nil if there isn't any or it isn't a text sheet.
+func TransientViewInGroup(w *backend.Window, group int) *backend.View {
+	if s := TransientSheetInGroup(w, group); s != nil {
+		return s.View()
+	}
+	return nil
+}
+
+// GetSheetIndex returns the group of the sheet and its index in the group,
+// both are -1 if the sheet isn't in any group.
+func GetSheetIndex(s *sheet.Sheet) (group, index int) {
+	w := s.Window()
+	if w == nil {
+		return -1, -1
+	}
+	layouts.Lock()
+	defer layouts.Unlock()
+	return layoutOf(w).find(s)
+}
+
+// SetSheetIndex moves the sheet to the given group and index, the index is
+// clamped to the group size.
+func SetSheetIndex(s *sheet.Sheet, group, index int) {
+	w := s.Window()
+	if w == nil {
+		return
+	}
+	layouts.Lock()
+	defer layouts.Unlock()
+	wl := layoutOf(w)
+	if !wl.valid(group) {
+		return
+	}
+	wl.remove(s)
+	wl.insert(s, group, index)
+}
+
+// FocusSheet makes the sheet and its group active.
+func FocusSheet(s *sheet.Sheet) {
+	w := s.Window()
+	if w == nil {
+		return
+	}
+	layouts.Lock()
+	wl := layoutOf(w)
+	g, _ := wl.find(s)
+	if g != -1 {
+		wl.active = g
+		wl.actives[g] = s
+	}
+	layouts.Unlock()
+	if g == -1 {
+		return
+	}
+
+	if v := s.View(); v != nil {
+		w.SetActiveView(v)
+	} else if fe, ok := backend.GetEditor().Frontend().(SheetFrontend); ok {
+		fe.FocusSheet(w, s)
+	}
+}
+
+// AddSheet adds an image or html sheet to the active group of its window and
+// focuses it, text sheets are added by creating their views. A transient
+// sheet replaces the transient sheet of the group.
+func AddSheet(s *sheet.Sheet, transient bool) {
+	w := s.Window()
+	if w == nil || s.View() != nil {
+		return
+	}
+	layouts.Lock()
+	wl := layoutOf(w)
+	wl.insert(s, wl.active, -1)
+	layouts.Unlock()
+
+	if transient {
+		setTransient(s)
+	}
+	FocusSheet(s)
+}
+
+// CloseSheet closes the sheet, closing a text sheet closes its view.
+func CloseSheet(s *sheet.Sheet) {
+	if v := s.View(); v != nil {
+		v.Close()
+		return
+	}
+	w := s.Window()
+	if w == nil {
+		return
+	}
+	layouts.Lock()
+	defer layouts.Unlock()
+	layoutOf(w).remove(s)
+}
+
+// Makes the sheet the transient sheet of its group closing the previous one
+func setTransient(s *sheet.Sheet) {
+	g, _ := GetSheetIndex(s)
+	if g == -1 {
+		return
+	}
+	old := TransientSheetInGroup(s.Window(), g)
+	s.SetTransient(true)
+	if old != nil && old != s {
+		CloseSheet(old)
+	}
+}
+
+// OpenFile opens the file in the window same as backend.Window.OpenFile but
+// also handles the TRANSIENT flag. The file is only opened as a transient
+// sheet if it isn't already open. The view is loading until its file is
+// loaded.
+func OpenFile(w *backend.Window, name string, flags int) *backend.View {
+	var open bool
+	if abs, err := filepath.Abs(name); err == nil {
+		for _, v := range WindowViews(w) {
+			if v.FileName() == abs {
+				open = true
+			}
+		}
+	}
+	startOpening(w)
+	v := w.OpenFile(name, flags&^TRANSIENT)
+	endOpening(w)
+	if v != nil && flags&TRANSIENT != 0 && !open {
+		setTransient(SheetOf(v))
+	}
+	return v
+}
+
+// A transient sheet becomes a normal sheet once it is modified
+func onTransientModified(v *backend.View) {
+	layouts.Lock()
+	s, ok := layouts.views[v]
+	layouts.Unlock()
+	if ok {
+		s.SetTransient(false)
+	}
+}
+
+func (o *Sheet) PyInit(args *py.Tuple, kwds *py.Dict) error {
+	return fmt.Errorf("Can't initialize type Sheet")
+}
+
+func (o *Sheet) Py_id() (py.Object, error) {
+	return toPython(o.data.Id())
+}
+
+func (o *Sheet) Py_window() (py.Object, error) {
+	return toPython(o.data.Window())
+}
+
+func (o *Sheet) Py_view() (py.Object, error) {
+	return toPython(o.data.View())
+}
+
+func (o *Sheet) PyRichCompare(other py.Object, op py.Op) (py.Object, error) {
+	if op != py.EQ && op != py.NE {
+		return nil, fmt.Errorf("Can only do EQ and NE compares")
+	}
+	o2, ok := other.(*Sheet)
+	if !ok {
+		// other types are never equal to sheets
+		return toPython(op == py.NE)
+	}
+	return toPython((o.data == o2.data) == (op == py.EQ))
+}
+
+func (o *Sheet) PyStr() string {
+	return o.data.String()
+}
+
+// Returns the sheet argument at position i
+func pySheetArg(tu *py.Tuple, i int64, name string) (*sheet.Sheet, error) {
+	v, err := tu.GetItem(i)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(*Sheet)
+	if !ok {
+		return nil, fmt.Errorf("Expected type Sheet for %s, not %s", name, v.Type())
+	}
+	return s.data, nil
+}
+
+func (o *Window) Py_active_sheet() (py.Object, error) {
+	return toPython(ActiveSheet(o.data))
+}
+
+func (o *Window) Py_sheets() (py.Object, error) {
+	return toPython(Sheets(o.data))
+}
+
+func (o *Window) Py_focus_sheet(tu *py.Tuple) (py.Object, error) {
+	s, err := pySheetArg(tu, 0, "sheet")
+	if err != nil {
+		return nil, err
+	}
+	FocusSheet(s)
+	return toPython(nil)
+}
+
+func (o *Window) Py_active_sheet_in_group(tu *py.Tuple) (py.Object, error) {
+	group, err := pyIntArg(tu, nil, 0, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(ActiveSheetInGroup(o.data, group))
+}
+
+func (o *Window) Py_sheets_in_group(tu *py.Tuple) (py.Object, error) {
+	group, err := pyIntArg(tu, nil, 0, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(SheetsInGroup(o.data, group))
+}
+
+func (o *Window) Py_transient_sheet_in_group(tu *py.Tuple) (py.Object, error) {
+	group, err := pyIntArg(tu, nil, 0, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(TransientSheetInGroup(o.data, group))
+}
+
+func (o *Window) Py_transient_view_in_group(tu *py.Tuple) (py.Object, error) {
+	group, err := pyIntArg(tu, nil, 0, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(TransientViewInGroup(o.data, group))
+}
+
+func (o *Window) Py_get_sheet_index(tu *py.Tuple) (py.Object, error) {
+	s, err := pySheetArg(tu, 0, "sheet")
+	if err != nil {
+		return nil, err
+	}
+	group, index := GetSheetIndex(s)
+	return toPython(Tuple{group, index})
+}
+
+func (o *Window) Py_set_sheet_index(tu *py.Tuple) (py.Object, error) {
+	s, err := pySheetArg(tu, 0, "sheet")
+	if err != nil {
+		return nil, err
+	}
+	group, err := pyIntArg(tu, nil, 1, "group", 0)
+	if err != nil {
+		return nil, err
+	}
+	index, err := pyIntArg(tu, nil, 2, "idx", -1)
+	if err != nil {
+		return nil, err
+	}
+	SetSheetIndex(s, group, index)
+	return toPython(nil)
+}
+
+func init() {
+	backend.OnModified.Add(onTransientModified)
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/sheet"
+)
+
+func TestTransientSheets(t *testing.T) {
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+
+	s1 := sheet.NewImage(w, "a.png")
+	AddSheet(s1, true)
+	if ActiveSheet(w) != s1 {
+		t.Errorf("Expected %s to be the active sheet, but got %s", s1, ActiveSheet(w))
+	}
+	if got := TransientSheetInGroup(w, 0); got != s1 {
+		t.Errorf("Expected %s to be the transient sheet, but got %s", s1, got)
+	}
+
+	// the next transient sheet replaces the previous one
+	s2 := sheet.NewHTML(w, "<p>preview</p>")
+	AddSheet(s2, true)
+	if got := TransientSheetInGroup(w, 0); got != s2 {
+		t.Errorf("Expected %s to be the transient sheet, but got %s", s2, got)
+	}
+	if g, i := GetSheetIndex(s1); g != -1 || i != -1 {
+		t.Errorf("Expected %s to be closed, but it's at (%d, %d)", s1, g, i)
+	}
+
+	s3 := sheet.NewImage(w, "b.png")
+	AddSheet(s3, false)
+	if got := SheetsInGroup(w, 0); len(got) != 2 || got[0] != s2 || got[1] != s3 {
+		t.Errorf("Expected sheets [%s %s], but got %v", s2, s3, got)
+	}
+	if got := TransientSheetInGroup(w, 0); got != s2 {
+		t.Errorf("Expected %s to stay the transient sheet, but got %s", s2, got)
+	}
+
+	CloseSheet(s2)
+	CloseSheet(s3)
+	if got := Sheets(w); len(got) != 0 {
+		t.Errorf("Expected no sheets, but got %v", got)
+	}
+}
+
+func TestLayoutClose(t *testing.T) {
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.NewFile()
+	if NumGroups(w) != 1 {
+		t.Fatalf("Expected 1 group, but got %d", NumGroups(w))
+	}
+	v.SetScratch(true)
+	v.Close()
+
+	layouts.Lock()
+	_, sheetOk := layouts.views[v]
+	_, layoutOk := layouts.m[w]
+	layouts.Unlock()
+	if sheetOk {
+		t.Error("Expected the sheet of the closed view to be forgotten")
+	}
+	if layoutOk {
+		t.Error("Expected the layout of the window without views to be forgotten")
+	}
+}
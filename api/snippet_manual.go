@@ -0,0 +1,436 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/sublime/resource"
+	"github.com/limetext/sublime/snippet"
+	"github.com/limetext/text"
+	"github.com/limetext/util"
+)
+
+// Fields of the snippet being edited in a view. The field regions are kept up
+// to date by observing the view buffer, unlike other regions the current
+// field grows when text is inserted at its edges.
+type snippetFields struct {
+	lock sync.Mutex
+	view *backend.View
+	// regions of the fields in the tab order, $0 is the last one
+	fields     [][]text.Region
+	indices    []int
+	transforms []snippet.Transform
+	current    int
+}
+
+var fields = struct {
+	sync.Mutex
+	m map[*backend.View]*snippetFields
+}{m: make(map[*backend.View]*snippetFields)}
+
+func fieldsOf(v *backend.View) *snippetFields {
+	fields.Lock()
+	defer fields.Unlock()
+	return fields.m[v]
+}
+
+// ClearFields stops tracking the snippet fields of the view.
+func ClearFields(v *backend.View) {
+	fields.Lock()
+	f := fields.m[v]
+	delete(fields.m, v)
+	fields.Unlock()
+	if f != nil {
+		v.RemoveObserver(f)
+	}
+}
+
+// SnippetsFor returns the snippets having a tab trigger starting with prefix
+// whose scope matches the point of the view, best matches first.
+func SnippetsFor(v *backend.View, pt int, prefix string) []*snippet.Snippet {
+	return snippet.Find(prefix, func(sel string) int {
+		return ScoreSelector(v, pt, sel)
+	})
+}
+
+// Returns the TM_* variables of a snippet inserted at r
+func snippetVariables(v *backend.View, r text.Region) map[string]string {
+	row, col := v.RowCol(r.Begin())
+	set := v.Settings()
+	vars := map[string]string{
+		"TM_SELECTED_TEXT": v.Substr(r),
+		"TM_CURRENT_LINE":  v.Substr(v.Line(r.Begin())),
+		"TM_CURRENT_WORD":  v.Substr(v.Word(r.Begin())),
+		"TM_LINE_INDEX":    strconv.Itoa(col),
+		"TM_LINE_NUMBER":   strconv.Itoa(row + 1),
+		"TM_TAB_SIZE":      strconv.Itoa(set.Int("tab_size", 4)),
+		"TM_SOFT_TABS":     "NO",
+	}
+	vars["SELECTION"] = vars["TM_SELECTED_TEXT"]
+	if set.Bool("translate_tabs_to_spaces", false) {
+		vars["TM_SOFT_TABS"] = "YES"
+	}
+	if fn := v.FileName(); fn != "" {
+		vars["TM_FILENAME"] = filepath.Base(fn)
+		vars["TM_FILEPATH"] = fn
+		vars["TM_DIRECTORY"] = filepath.Dir(fn)
+	}
+	return vars
+}
+
+// Returns the leading white space of the line containing pt
+func lineIndent(v *backend.View, pt int) string {
+	l := v.Line(pt)
+	s := v.Substr(text.Region{A: l.A, B: pt})
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+type (
+	// InsertSnippetCommand inserts a snippet at every selection, the
+	// snippet is either given by its contents or by the resource name of a
+	// .sublime-snippet file. The rest of the arguments are snippet
+	// variables.
+	InsertSnippetCommand struct {
+		backend.DefaultCommand
+		contents string
+		name     string
+		vars     map[string]string
+	}
+
+	// NextFieldCommand selects the next field of the snippet.
+	NextFieldCommand struct {
+		backend.DefaultCommand
+	}
+
+	// PrevFieldCommand selects the previous field of the snippet.
+	PrevFieldCommand struct {
+		backend.DefaultCommand
+	}
+
+	// ClearFieldsCommand stops the snippet field navigation.
+	ClearFieldsCommand struct {
+		backend.DefaultCommand
+	}
+)
+
+func (c *InsertSnippetCommand) Init(args backend.Args) error {
+	c.contents, _ = args["contents"].(string)
+	c.name, _ = args["name"].(string)
+	c.vars = make(map[string]string)
+	for k, v := range args {
+		if k != "contents" && k != "name" {
+			c.vars[k] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// Returns the content of the snippet to insert
+func (c *InsertSnippetCommand) content() (string, error) {
+	if c.name == "" {
+		return c.contents, nil
+	}
+	if s := snippet.Get(c.name); s != nil {
+		return s.Content, nil
+	}
+	data, err := resource.ReadFile(c.name)
+	if err != nil {
+		return "", err
+	}
+	s, err := snippet.Load(data)
+	if err != nil {
+		return "", err
+	}
+	return s.Content, nil
+}
+
+func (c *InsertSnippetCommand) Run(v *backend.View, e *backend.Edit) error {
+	content, err := c.content()
+	if err != nil {
+		return err
+	}
+	return InsertSnippet(v, e, content, c.vars)
+}
+
+// InsertSnippet replaces the selections of the view with the snippet and
+// selects its first field, the given variables override the TM_* ones.
+func InsertSnippet(v *backend.View, e *backend.Edit, content string, extra map[string]string) error {
+	if err := CheckEdit(v); err != nil {
+		return err
+	}
+	ClearFields(v)
+
+	tab := "\t"
+	if set := v.Settings(); set.Bool("translate_tabs_to_spaces", false) {
+		tab = strings.Repeat(" ", set.Int("tab_size", 4))
+	}
+	regions := make(map[int][]text.Region)
+	var transforms []snippet.Transform
+	move := func(r text.Region, d int) text.Region {
+		return text.Region{A: r.A + d, B: r.B + d}
+	}
+	offset := 0
+	for _, r := range v.Sel().Regions() {
+		r = text.Region{A: r.Begin() + offset, B: r.End() + offset}
+		vars := snippetVariables(v, r)
+		for k, val := range extra {
+			vars[k] = val
+		}
+		x, err := snippet.Expand(content, vars)
+		if err != nil {
+			return err
+		}
+		x.Indent(lineIndent(v, r.Begin()), tab)
+		v.Replace(e, r, x.Text)
+
+		for _, f := range x.Fields {
+			for _, fr := range f.Regions {
+				regions[f.Index] = append(regions[f.Index], move(fr, r.Begin()))
+			}
+		}
+		for _, t := range x.Transforms {
+			t.Region = move(t.Region, r.Begin())
+			transforms = append(transforms, t)
+		}
+		offset += utf8.RuneCountInString(x.Text) - r.Size()
+	}
+
+	f := &snippetFields{view: v, transforms: transforms}
+	for i := range regions {
+		if i != 0 {
+			f.indices = append(f.indices, i)
+		}
+	}
+	sort.Ints(f.indices)
+	f.indices = append(f.indices, 0)
+	for _, i := range f.indices {
+		f.fields = append(f.fields, regions[i])
+	}
+	if len(f.fields) > 1 {
+		fields.Lock()
+		fields.m[v] = f
+		fields.Unlock()
+		v.AddObserver(f)
+	}
+	f.selectField(v)
+	return nil
+}
+
+func (c *NextFieldCommand) Run(v *backend.View, e *backend.Edit) error {
+	return moveField(v, e, 1)
+}
+
+func (c *PrevFieldCommand) Run(v *backend.View, e *backend.Edit) error {
+	return moveField(v, e, -1)
+}
+
+func (c *ClearFieldsCommand) Run(v *backend.View, e *backend.Edit) error {
+	ClearFields(v)
+	return nil
+}
+
+func moveField(v *backend.View, e *backend.Edit, dir int) error {
+	f := fieldsOf(v)
+	if f == nil {
+		return nil
+	}
+	f.applyTransforms(v, e)
+	f.lock.Lock()
+	if i := f.current + dir; i >= 0 && i < len(f.fields) {
+		f.current = i
+	}
+	last := f.current == len(f.fields)-1
+	f.lock.Unlock()
+	f.selectField(v)
+	// navigation ends on $0
+	if last {
+		ClearFields(v)
+	}
+	return nil
+}
+
+// Selects the regions of the current field
+func (f *snippetFields) selectField(v *backend.View) {
+	f.lock.Lock()
+	regions := append([]text.Region(nil), f.fields[f.current]...)
+	f.lock.Unlock()
+	v.Sel().Clear()
+	v.Sel().AddAll(regions)
+}
+
+func (f *snippetFields) hasNext() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.current < len(f.fields)-1
+}
+
+func (f *snippetFields) hasPrev() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.current > 0
+}
+
+// Updates the transformations of the current field with its text
+func (f *snippetFields) applyTransforms(v *backend.View, e *backend.Edit) {
+	f.lock.Lock()
+	index := f.indices[f.current]
+	f.lock.Unlock()
+	for i := 0; ; i++ {
+		f.lock.Lock()
+		if i >= len(f.transforms) {
+			f.lock.Unlock()
+			return
+		}
+		t := f.transforms[i]
+		field := f.fields[f.current][0]
+		f.lock.Unlock()
+		if t.Index != index {
+			continue
+		}
+		s := t.Apply(v.Substr(field))
+		if s == v.Substr(t.Region) {
+			continue
+		}
+		a := t.Region.Begin()
+		v.Replace(e, t.Region, s)
+		f.lock.Lock()
+		f.transforms[i].Region = text.Region{A: a, B: a + utf8.RuneCountInString(s)}
+		f.lock.Unlock()
+	}
+}
+
+// Moves the regions after an insertion at p, the regions of the current
+// field grow when p is at their edges.
+func (f *snippetFields) Inserted(b text.Buffer, r text.Region, data []rune) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	p, d := r.Begin(), len(data)
+	insert := func(r *text.Region, current bool) {
+		switch {
+		case r.A <= p && p <= r.B && (current || r.A < p):
+			r.B += d
+		case r.A >= p:
+			r.A += d
+			r.B += d
+		}
+	}
+	for i := range f.fields {
+		for j := range f.fields[i] {
+			insert(&f.fields[i][j], i == f.current)
+		}
+	}
+	for i := range f.transforms {
+		insert(&f.transforms[i].Region, false)
+	}
+}
+
+// Moves the regions after an erase, erased parts of the regions are removed.
+func (f *snippetFields) Erased(b text.Buffer, r text.Region, data []rune) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	erase := func(p int) int {
+		switch {
+		case p >= r.End():
+			return p - r.Size()
+		case p > r.Begin():
+			return r.Begin()
+		}
+		return p
+	}
+	for i := range f.fields {
+		for j, fr := range f.fields[i] {
+			f.fields[i][j] = text.Region{A: erase(fr.A), B: erase(fr.B)}
+		}
+	}
+	for i := range f.transforms {
+		tr := f.transforms[i].Region
+		f.transforms[i].Region = text.Region{A: erase(tr.A), B: erase(tr.B)}
+	}
+}
+
+// Field navigation stops when the selection leaves the current field
+func onFieldsSelectionModified(v *backend.View) {
+	f := fieldsOf(v)
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	current := f.fields[f.current]
+	f.lock.Unlock()
+	for _, r := range v.Sel().Regions() {
+		in := false
+		for _, fr := range current {
+			if fr.Covers(r) {
+				in = true
+				break
+			}
+		}
+		if !in {
+			ClearFields(v)
+			return
+		}
+	}
+}
+
+// Answers the has_next_field and has_prev_field context keys
+func onFieldsQueryContext(v *backend.View, key string, op util.Op, operand interface{}, matchAll bool) backend.QueryContextReturn {
+	var val bool
+	switch key {
+	case "has_next_field":
+		f := fieldsOf(v)
+		val = f != nil && f.hasNext()
+	case "has_prev_field":
+		f := fieldsOf(v)
+		val = f != nil && f.hasPrev()
+	default:
+		return backend.Unknown
+	}
+	exp, ok := operand.(bool)
+	if !ok {
+		exp = true
+	}
+	switch op {
+	case util.OpEqual:
+		return queryContextReturn(val == exp)
+	case util.OpNotEqual:
+		return queryContextReturn(val != exp)
+	}
+	return backend.Unknown
+}
+
+func queryContextReturn(b bool) backend.QueryContextReturn {
+	if b {
+		return backend.True
+	}
+	return backend.False
+}
+
+func init() {
+	backend.OnSelectionModified.Add(onFieldsSelectionModified)
+	backend.OnClose.Add(ClearFields)
+	backend.OnQueryContext.Add(onFieldsQueryContext)
+
+	ch := backend.GetEditor().CommandHandler()
+	cmds := map[string]interface{}{
+		"insert_snippet": &InsertSnippetCommand{},
+		"next_field":     &NextFieldCommand{},
+		"prev_field":     &PrevFieldCommand{},
+		"clear_fields":   &ClearFieldsCommand{},
+	}
+	for name, cmd := range cmds {
+		if err := ch.Register(name, cmd); err != nil {
+			log.Warn("Failed to register command %s: %s", name, err)
+		}
+	}
+}
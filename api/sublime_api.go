@@ -22,13 +22,18 @@ var classes = []struct {
 	{"RegionSet", &_region_setClass},
 	{"View", &_viewClass},
 	{"Window", &_windowClass},
+	{"Sheet", &_sheetClass},
 	{"Edit", &_editClass},
 	{"Settings", &_settingsClass},
 	{"WindowCommandGlue", &_windowCommandGlueClass},
 	{"TextCommandGlue", &_textCommandGlueClass},
 	{"ApplicationCommandGlue", &_applicationCommandGlueClass},
 	{"OnQueryContextGlue", &_onQueryContextGlueClass},
+	{"OnQueryCompletionsGlue", &_onQueryCompletionsGlueClass},
 	{"ViewEventGlue", &_viewEventGlueClass},
+	{"OnHoverGlue", &_onHoverGlueClass},
+	{"Phantom", &_phantomClass},
+	{"PhantomSet", &_phantomSetClass},
 }
 
 var constants = []struct {
@@ -41,10 +46,10 @@ var constants = []struct {
 	{"OP_NOT_REGEX_MATCH", int(util.OpNotRegexMatch)},
 	{"OP_REGEX_CONTAINS", int(util.OpRegexContains)},
 	{"OP_NOT_REGEX_CONTAINS", int(util.OpNotRegexContains)},
-	{"INHIBIT_WORD_COMPLETIONS", 0},
-	{"INHIBIT_EXPLICIT_COMPLETIONS", 0},
-	{"LITERAL", int(backend.IGNORECASE)},
-	{"IGNORECASE", int(backend.LITERAL)},
+	{"INHIBIT_WORD_COMPLETIONS", INHIBIT_WORD_COMPLETIONS},
+	{"INHIBIT_EXPLICIT_COMPLETIONS", INHIBIT_EXPLICIT_COMPLETIONS},
+	{"LITERAL", int(backend.LITERAL)},
+	{"IGNORECASE", int(backend.IGNORECASE)},
 	{"CLASS_WORD_START", int(backend.CLASS_WORD_START)},
 	{"CLASS_WORD_END", int(backend.CLASS_WORD_END)},
 	{"CLASS_PUNCTUATION_START", int(backend.CLASS_PUNCTUATION_START)},
@@ -69,6 +74,21 @@ var constants = []struct {
 	{"DRAW_SQUIGGLY_UNDERLINE", int(render.DRAW_SQUIGGLY_UNDERLINE)},
 	{"PERSISTENT", int(render.PERSISTENT)},
 	{"HIDDEN", int(render.HIDDEN)},
+	{"MONOSPACE_FONT", MONOSPACE_FONT},
+	{"KEEP_OPEN_ON_FOCUS_LOST", KEEP_OPEN_ON_FOCUS_LOST},
+	{"ENCODED_POSITION", ENCODED_POSITION},
+	{"TRANSIENT", TRANSIENT},
+	{"FORCE_GROUP", FORCE_GROUP},
+	{"HTML", HTML},
+	{"COOPERATE_WITH_AUTO_COMPLETE", COOPERATE_WITH_AUTO_COMPLETE},
+	{"HIDE_ON_MOUSE_MOVE", HIDE_ON_MOUSE_MOVE},
+	{"HIDE_ON_MOUSE_MOVE_AWAY", HIDE_ON_MOUSE_MOVE_AWAY},
+	{"HOVER_TEXT", HOVER_TEXT},
+	{"HOVER_GUTTER", HOVER_GUTTER},
+	{"HOVER_MARGIN", HOVER_MARGIN},
+	{"LAYOUT_INLINE", LAYOUT_INLINE},
+	{"LAYOUT_BELOW", LAYOUT_BELOW},
+	{"LAYOUT_BLOCK", LAYOUT_BLOCK},
 }
 
 func init() {
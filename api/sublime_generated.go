@@ -56,27 +56,6 @@ func sublime_GetClipboard() (py.Object, error) {
 	return pyret0, err
 }
 
-func sublime_LogCommands(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 bool
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(bool); !ok {
-				return nil, fmt.Errorf("Expected type bool for backend.Editor.LogCommands() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	backend.GetEditor().LogCommands(arg1)
-	return toPython(nil)
-}
-
 func sublime_LogInput(tu *py.Tuple) (py.Object, error) {
 	var (
 		arg1 bool
@@ -122,40 +101,6 @@ func sublime_Platform() (py.Object, error) {
 	return pyret0, err
 }
 
-func sublime_RunCommand(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 string
-		arg2 backend.Args
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(string); !ok {
-				return nil, fmt.Errorf("Expected type string for backend.Editor.RunCommand() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	arg2 = make(backend.Args)
-	if v, err := tu.GetItem(1); err == nil {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(backend.Args); !ok {
-				return nil, fmt.Errorf("Expected type backend.Args for backend.Editor.RunCommand() arg2, not %s", v.Type())
-			} else {
-				arg2 = v2
-			}
-		}
-	}
-	backend.GetEditor().RunCommand(arg1, arg2)
-	return toPython(nil)
-}
-
 func sublime_SetClipboard(tu *py.Tuple) (py.Object, error) {
 	var (
 		arg1 string
@@ -202,16 +147,12 @@ func sublime_Windows() (py.Object, error) {
 }
 
 var generated_methods = []py.Method{
-	{Name: "register", Func: sublime_Register},
-	{Name: "unregister", Func: sublime_Unregister},
 	{Name: "active_window", Func: sublime_ActiveWindow},
 	{Name: "arch", Func: sublime_Arch},
 	{Name: "get_clipboard", Func: sublime_GetClipboard},
-	{Name: "log_commands", Func: sublime_LogCommands},
 	{Name: "log_input", Func: sublime_LogInput},
 	{Name: "packages_path", Func: sublime_PackagesPath},
 	{Name: "platform", Func: sublime_Platform},
-	{Name: "run_command", Func: sublime_RunCommand},
 	{Name: "set_clipboard", Func: sublime_SetClipboard},
 	{Name: "version", Func: sublime_Version},
 	{Name: "windows", Func: sublime_Windows},
@@ -11,6 +11,8 @@ import (
 	"github.com/limetext/backend"
 	"github.com/limetext/backend/log"
 	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/resource"
+	"github.com/limetext/sublime/settings"
 )
 
 func sublime_ErrorMessage(tu *py.Tuple) (py.Object, error) {
@@ -155,8 +157,8 @@ func sublime_SetTimeOut(tu *py.Tuple, kwargs *py.Dict) (py.Object, error) {
 			l := py.NewLock()
 			defer l.Unlock()
 			defer pyarg.Decref()
-			if ret, err := pyarg.Base().CallFunctionObjArgs(); err != nil {
-				log.Debug("Error in callback: %v", err)
+			if ret, err := callPython(pyarg); err != nil {
+				reportError(err)
 			} else {
 				ret.Decref()
 			}
@@ -165,6 +167,78 @@ func sublime_SetTimeOut(tu *py.Tuple, kwargs *py.Dict) (py.Object, error) {
 	return toPython(nil)
 }
 
+func sublime_FindResources(tu *py.Tuple) (py.Object, error) {
+	pattern, err := pyStringArg(tu, nil, 0, "pattern", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(resource.Find(pattern))
+}
+
+func sublime_LoadResource(tu *py.Tuple) (py.Object, error) {
+	name, err := pyStringArg(tu, nil, 0, "name", "")
+	if err != nil {
+		return nil, err
+	}
+	data, err := resource.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return toPython(data)
+}
+
+func sublime_LoadBinaryResource(tu *py.Tuple) (py.Object, error) {
+	name, err := pyStringArg(tu, nil, 0, "name", "")
+	if err != nil {
+		return nil, err
+	}
+	data, err := resource.LoadBinary(name)
+	if err != nil {
+		return nil, err
+	}
+	// each byte is mapped to the rune with the same value so encoding the
+	// string with latin-1 gives us the bytes back
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	str, err := py.NewUnicode(string(runes))
+	if err != nil {
+		return nil, err
+	}
+	defer str.Decref()
+	enc, err := str.Base().GetAttrString("encode")
+	if err != nil {
+		return nil, err
+	}
+	defer enc.Decref()
+	latin1, err := py.NewUnicode("latin-1")
+	if err != nil {
+		return nil, err
+	}
+	defer latin1.Decref()
+	return enc.Base().CallFunctionObjArgs(latin1)
+}
+
+func sublime_LoadSettings(tu *py.Tuple) (py.Object, error) {
+	name, err := pyStringArg(tu, nil, 0, "base_name", "")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(settings.Load(name))
+}
+
+func sublime_SaveSettings(tu *py.Tuple) (py.Object, error) {
+	name, err := pyStringArg(tu, nil, 0, "base_name", "")
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.Save(name); err != nil {
+		return nil, err
+	}
+	return toPython(nil)
+}
+
 var manual_methods = []py.Method{
 	{Name: "console", Func: sublime_Console},
 	{Name: "set_timeout", Func: sublime_SetTimeOut},
@@ -173,4 +247,16 @@ var manual_methods = []py.Method{
 	{Name: "message_dialog", Func: sublime_MessageDialog},
 	{Name: "ok_cancel_dialog", Func: sublime_OkCancelDialog},
 	{Name: "status_message", Func: sublime_StatusMessage},
+	{Name: "find_resources", Func: sublime_FindResources},
+	{Name: "load_resource", Func: sublime_LoadResource},
+	{Name: "load_binary_resource", Func: sublime_LoadBinaryResource},
+	{Name: "load_settings", Func: sublime_LoadSettings},
+	{Name: "save_settings", Func: sublime_SaveSettings},
+	{Name: "expand_variables", Func: sublime_ExpandVariables},
+	{Name: "register", Func: sublime_Register},
+	{Name: "unregister", Func: sublime_Unregister},
+	{Name: "log_commands", Func: sublime_LogCommands},
+	{Name: "run_command", Func: sublime_RunCommand},
+	{Name: "get_macro", Func: sublime_GetMacro},
+	{Name: "score_selector", Func: sublime_ScoreSelector},
 }
@@ -0,0 +1,263 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/gopy"
+	"github.com/limetext/sublime/project"
+	"github.com/limetext/sublime/symbol"
+	"github.com/limetext/text"
+	"github.com/quarnster/parser"
+)
+
+var (
+	// loaded syntaxes by their paths, used for finding the syntax of files
+	// which aren't open
+	syntaxes = struct {
+		sync.Mutex
+		m map[string]backend.Syntax
+	}{m: make(map[string]backend.Syntax)}
+
+	// symbol indexes of the window projects
+	indexes = struct {
+		sync.Mutex
+		m map[*backend.Window]*symbol.Index
+	}{m: make(map[*backend.Window]*symbol.Index)}
+)
+
+// AddSyntax makes the syntax available for indexing the files with its file
+// types.
+func AddSyntax(path string, s backend.Syntax) {
+	syntaxes.Lock()
+	defer syntaxes.Unlock()
+	syntaxes.m[path] = s
+}
+
+// Returns the syntax of the file by its extension
+func syntaxForFile(filename string) backend.Syntax {
+	ext := strings.TrimPrefix(filepath.Ext(filename), ".")
+	base := filepath.Base(filename)
+	syntaxes.Lock()
+	defer syntaxes.Unlock()
+	paths := make([]string, 0, len(syntaxes.m))
+	for p := range syntaxes.m {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		for _, ft := range syntaxes.m[p].FileTypes() {
+			if ft == ext || ft == base {
+				return syntaxes.m[p]
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the scope tree of the data parsed with the syntax
+func parse(syn backend.Syntax, data string) (*parser.Node, error) {
+	p, err := syn.Parser(data)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse()
+}
+
+// The text of a view for the nodes of its scope tree
+type viewData struct {
+	v *backend.View
+}
+
+func (d viewData) Data(a, b int) string {
+	return d.v.Substr(text.Region{A: a, B: b})
+}
+
+// Returns the scope tree of the view built from the scopes the view keeps
+// for its syntax highlighting, the text isn't parsed again. Adjacent scopes
+// with the same name become a single node.
+func viewTree(v *backend.View) *parser.Node {
+	root := &parser.Node{Range: text.Region{A: 0, B: v.Size()}, P: viewData{v}}
+	stack := []*parser.Node{root}
+	for p := 0; p < root.Range.B; p++ {
+		names := strings.Fields(v.ScopeName(p))
+		// the first name is the scope of the syntax which the root has
+		if p == 0 && len(names) != 0 {
+			root.Name = names[0]
+		}
+		if len(names) != 0 && names[0] == root.Name {
+			names = names[1:]
+		}
+		i := 0
+		for i < len(names) && i+1 < len(stack) && stack[i+1].Name == names[i] {
+			i++
+		}
+		for _, n := range stack[i+1:] {
+			n.Range.B = p
+		}
+		stack = stack[:i+1]
+		for _, name := range names[i:] {
+			n := &parser.Node{Name: name, Range: text.Region{A: p, B: p}, P: root.P}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, n)
+			stack = append(stack, n)
+		}
+	}
+	for _, n := range stack[1:] {
+		n.Range.B = root.Range.B
+	}
+	return root
+}
+
+func viewSymbols(v *backend.View, index bool) []symbol.Symbol {
+	return symbol.Extract(viewTree(v), index)
+}
+
+// Symbols returns the symbols of the view for the symbol list.
+func Symbols(v *backend.View) []symbol.Symbol {
+	return viewSymbols(v, false)
+}
+
+// IndexOf returns the symbol index of the window project folders. The
+// index is built in the background from the first call, lookups return the
+// symbols indexed so far, and it's kept up to date as views are saved.
+func IndexOf(w *backend.Window) *symbol.Index {
+	indexes.Lock()
+	idx, ok := indexes.m[w]
+	indexes.Unlock()
+	if ok {
+		return idx
+	}
+	p := ProjectOf(w)
+
+	indexes.Lock()
+	defer indexes.Unlock()
+	if idx, ok := indexes.m[w]; ok {
+		return idx
+	}
+	idx = symbol.NewIndex()
+	indexes.m[w] = idx
+	if p != nil {
+		go indexFolders(idx, p.Folders())
+	}
+	return idx
+}
+
+func indexFolders(idx *symbol.Index, folders []project.Folder) {
+	for _, f := range folders {
+		files, err := f.Files()
+		if err != nil {
+			log.Warn("Error listing folder %s: %s", f.Path, err)
+		}
+		for _, path := range files {
+			indexFile(idx, path)
+		}
+	}
+}
+
+func indexFile(idx *symbol.Index, path string) {
+	syn := syntaxForFile(path)
+	if syn == nil {
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Warn("Error indexing %s: %s", path, err)
+		return
+	}
+	root, err := parse(syn, string(data))
+	if err != nil {
+		log.Warn("Error indexing %s: %s", path, err)
+		return
+	}
+	idx.Update(path, string(data), symbol.Extract(root, true))
+}
+
+// Updates the project index with the symbols of the saved view
+func onSymbolsPostSave(v *backend.View) {
+	w := v.Window()
+	if w == nil || v.FileName() == "" {
+		return
+	}
+	indexes.Lock()
+	idx, ok := indexes.m[w]
+	indexes.Unlock()
+	if !ok {
+		return
+	}
+	idx.Update(v.FileName(), v.Substr(text.Region{A: 0, B: v.Size()}), viewSymbols(v, true))
+}
+
+// LookupSymbolInOpenFiles returns the locations of the symbol in the views
+// of the window.
+func LookupSymbolInOpenFiles(w *backend.Window, sym string) []symbol.Location {
+	idx := symbol.NewIndex()
+	for _, v := range WindowViews(w) {
+		name := v.FileName()
+		if name == "" {
+			name = fmt.Sprintf("untitled %d", v.Id())
+		}
+		idx.Update(name, v.Substr(text.Region{A: 0, B: v.Size()}), viewSymbols(v, true))
+	}
+	return idx.Lookup(sym)
+}
+
+// Returns the locations as (path, display name, (row, col)) tuples, the
+// display name is the path relative to the project folder containing it.
+func locationsToPython(w *backend.Window, locs []symbol.Location) (py.Object, error) {
+	folders := Folders(w)
+	ret := make(List, len(locs))
+	for i, l := range locs {
+		display := l.Path
+		for _, f := range folders {
+			if rel, err := filepath.Rel(f, l.Path); err == nil && !strings.HasPrefix(rel, "..") {
+				display = filepath.Join(filepath.Base(f), rel)
+				break
+			}
+		}
+		ret[i] = Tuple{l.Path, display, Tuple{l.Row, l.Col}}
+	}
+	return toPython(ret)
+}
+
+func (o *View) Py_symbols() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	syms := Symbols(o.data)
+	ret := make(List, len(syms))
+	for i, s := range syms {
+		ret[i] = Tuple{s.Region, s.Name}
+	}
+	return toPython(ret)
+}
+
+func (o *Window) Py_lookup_symbol_in_index(tu *py.Tuple) (py.Object, error) {
+	sym, err := pyStringArg(tu, nil, 0, "symbol", "")
+	if err != nil {
+		return nil, err
+	}
+	return locationsToPython(o.data, IndexOf(o.data).Lookup(sym))
+}
+
+func (o *Window) Py_lookup_symbol_in_open_files(tu *py.Tuple) (py.Object, error) {
+	sym, err := pyStringArg(tu, nil, 0, "symbol", "")
+	if err != nil {
+		return nil, err
+	}
+	return locationsToPython(o.data, LookupSymbolInOpenFiles(o.data, sym))
+}
+
+func init() {
+	backend.OnPostSave.Add(onSymbolsPostSave)
+}
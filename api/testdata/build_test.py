@@ -0,0 +1,14 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    vars = {"file": "/tmp/main.go", "file_name": "main.go"}
+    assert sublime.expand_variables("go run $file", vars) == "go run /tmp/main.go"
+    assert sublime.expand_variables("${file_name/\\.go$/.o/}", vars) == "main.o"
+    assert sublime.expand_variables("${none:default}", vars) == "default"
+    assert sublime.expand_variables({"cmd": ["go", "run", "$file"]}, vars) == {"cmd": ["go", "run", "/tmp/main.go"]}
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,27 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    e = v.begin_edit()
+    v.insert(e, 0, "completion complete compiler\nco")
+    v.end_edit(e)
+    assert v.extract_completions("comp") == ["compiler", "complete", "completion"]
+    assert v.extract_completions("xyz") == []
+
+    def on_query_completions(view, prefix, locations):
+        if prefix == "co":
+            return ([["con\tconst", "const ${1:x}"]], sublime.INHIBIT_WORD_COMPLETIONS)
+        return None
+    sublime.OnQueryCompletionsGlue(on_query_completions)
+
+    v.sel().clear()
+    v.sel().add(sublime.Region(v.size(), v.size()))
+    v.run_command("insert_best_completion")
+    assert v.substr(v.line(v.size())) == "const x"
+    assert v.sel()[0] == sublime.Region(35, 36)
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
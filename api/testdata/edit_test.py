@@ -0,0 +1,24 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    assert not v.is_in_edit()
+    e = v.begin_edit()
+    assert v.is_in_edit()
+    v.insert(e, 0, "hello")
+    v.end_edit(e)
+    assert not v.is_in_edit()
+
+    for f, args in ((v.insert, (e, 0, "x")), (v.erase, (e, sublime.Region(0, 1))), (v.end_edit, (e,))):
+        try:
+            f(*args)
+            assert False
+        except ValueError:
+            pass
+    assert v.substr(sublime.Region(0, v.size())) == "hello"
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,28 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    assert v.encoding() == "Undefined"
+    assert v.line_endings() in ("Unix", "Windows")
+
+    v.set_encoding("Western (Windows 1252)")
+    assert v.encoding() == "Western (Windows 1252)"
+    v.set_line_endings("Windows")
+    assert v.line_endings() == "Windows"
+
+    for f, arg in ((v.set_encoding, "EBCDIC"), (v.set_line_endings, "Mac")):
+        try:
+            f(arg)
+            assert False
+        except AssertionError:
+            raise
+        except:
+            pass
+    assert v.encoding() == "Western (Windows 1252)"
+    assert v.line_endings() == "Windows"
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
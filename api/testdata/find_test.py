@@ -0,0 +1,32 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    v.run_command("insert", {"characters": "foo(1) Foo(22) f.o(3)"})
+
+    assert v.find_all("f.o") == [sublime.Region(0, 3), sublime.Region(15, 18)]
+    assert v.find_all("f.o", sublime.LITERAL) == [sublime.Region(15, 18)]
+    assert v.find_all("foo", sublime.IGNORECASE) == [sublime.Region(0, 3), sublime.Region(7, 10)]
+
+    extractions = []
+    regions = v.find_all(r"(\w+)\((\d+)\)", 0, "$2=$1", extractions)
+    assert regions == [sublime.Region(0, 6), sublime.Region(7, 14), sublime.Region(17, 21)]
+    assert extractions == ["1=foo", "22=Foo", "3=o"]
+
+    extractions = []
+    v.find_all("oo", sublime.IGNORECASE, r"\U$0", extractions)
+    assert extractions == ["OO", "OO"]
+
+    extractions = []
+    v.find_all(r"(\w)\((\d)\)", 0, r"\1\2", extractions)
+    assert extractions == ["o3"]
+
+    # find_all matches the patterns find does
+    pattern = r"(?<=F)oo"
+    assert v.find_all(pattern) == [v.find(pattern, 0)]
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,33 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    v.settings().set("tab_size", 4)
+    v.run_command("insert", {"characters": "def a():\n    if b:\n        c\n    d\ne\n"})
+
+    assert v.indentation_level(0) == 0
+    assert v.indentation_level(12) == 1
+    assert v.indentation_level(25) == 2
+    assert v.indented_region(12) == sublime.Region(9, 35)
+    assert v.indented_region(2) == sublime.Region(2, 2)
+
+    assert v.fold(sublime.Region(18, 31))
+    assert not v.fold(sublime.Region(20, 25))
+    assert v.is_folded(sublime.Region(20, 25))
+    assert v.folded_regions() == [sublime.Region(18, 31)]
+
+    # folds move with the text before them
+    v.sel().clear()
+    v.sel().add(sublime.Region(0, 0))
+    v.run_command("insert", {"characters": "#\n"})
+    assert v.folded_regions() == [sublime.Region(20, 33)]
+
+    assert v.unfold(sublime.Region(0, 1)) == []
+    assert v.unfold([sublime.Region(25, 25)]) == [sublime.Region(20, 33)]
+    assert v.folded_regions() == []
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
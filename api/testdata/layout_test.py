@@ -0,0 +1,46 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    w = sublime.active_window()
+    assert w.num_groups() == 1
+    assert w.active_group() == 0
+    layout = w.get_layout()
+    assert layout["cols"] == [0.0, 1.0]
+    assert layout["rows"] == [0.0, 1.0]
+    assert layout["cells"] == [[0, 0, 1, 1]]
+
+    v = w.new_file()
+    assert w.get_view_index(v) == (0, len(w.views_in_group(0)) - 1)
+    assert w.views_in_group(0)[-1].id() == v.id()
+
+    w.set_layout({"cols": [0, 0.5, 1], "rows": [0, 1], "cells": [[0, 0, 1, 1], [1, 0, 2, 1]]})
+    assert w.num_groups() == 2
+    assert w.get_layout()["cols"] == [0.0, 0.5, 1.0]
+    assert w.views_in_group(1) == []
+    assert w.active_view_in_group(1) is None
+
+    w.set_view_index(v, 1, 0)
+    assert w.get_view_index(v) == (1, 0)
+    assert w.active_view_in_group(1).id() == v.id()
+    w.focus_group(1)
+    assert w.active_group() == 1
+
+    failed = False
+    try:
+        w.set_layout({"cols": [0, 1], "rows": [0, 1], "cells": [[0, 0, 2, 1]]})
+    except Exception:
+        failed = True
+    assert failed
+    assert w.num_groups() == 2
+
+    # views of removed groups end up in the last remaining group
+    w.set_layout({"cols": [0, 1], "rows": [0, 1], "cells": [[0, 0, 1, 1]]})
+    assert w.num_groups() == 1
+    assert w.active_group() == 0
+    assert w.get_view_index(v)[0] == 0
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,53 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    def raises(f, *args):
+        try:
+            f(*args)
+        except:
+            return True
+        return False
+
+    v = sublime.active_window().new_file()
+    assert v.is_valid()
+    assert not v.is_loading()
+    assert not v.is_read_only()
+
+    e = v.begin_edit()
+    v.insert(e, 0, "hello")
+    v.set_read_only(True)
+    assert v.is_read_only()
+    assert raises(v.insert, e, 0, "x")
+    assert raises(v.erase, e, sublime.Region(0, 1))
+    assert raises(v.replace, e, sublime.Region(0, 1), "x")
+    assert v.substr(sublime.Region(0, v.size())) == "hello"
+    v.set_read_only(False)
+    v.replace(e, sublime.Region(0, 1), "j")
+    v.end_edit(e)
+    assert v.substr(sublime.Region(0, v.size())) == "jello"
+
+    # nor can the commands of the api
+    v.set_read_only(True)
+    v.sel().clear()
+    v.sel().add(sublime.Region(0, 0))
+    v.run_command("insert_snippet", {"contents": "x"})
+    assert v.substr(sublime.Region(0, v.size())) == "jello"
+    v.set_read_only(False)
+
+    missing = sublime.active_window().open_file("/nonexistent/lifecycle.txt")
+    assert not missing.is_loading()
+    missing.close()
+
+    v.set_scratch(True)
+    vid = v.id()
+    v.close()
+    assert not v.is_valid()
+    assert v.id() == vid
+    assert raises(v.size)
+    assert raises(v.set_read_only, True)
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
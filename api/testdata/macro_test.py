@@ -0,0 +1,16 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    w = sublime.active_window()
+    v = w.new_file()
+    w.run_command("start_record_macro")
+    v.run_command("insert", {"characters": "a"})
+    w.run_command("stop_record_macro")
+    # commands plugins run aren't user input and aren't recorded
+    assert sublime.get_macro() == []
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
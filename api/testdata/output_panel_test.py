@@ -0,0 +1,42 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    w = sublime.active_window()
+    n = len(w.views())
+    p = w.create_output_panel("test")
+    assert p is not None
+    assert len(w.views()) == n
+    assert w.find_output_panel("test").id() == p.id()
+    assert w.find_output_panel("output.test").id() == p.id()
+    assert "output.test" in w.panels()
+
+    e = p.begin_edit()
+    p.insert(e, 0, "hello")
+    p.end_edit(e)
+    assert p.size() == 5
+    # creating it again clears the content
+    assert w.create_output_panel("test").size() == 0
+
+    w.run_command("show_panel", {"panel": "output.test"})
+    assert w.active_panel() == "output.test"
+    w.run_command("hide_panel")
+    assert w.active_panel() is None
+    w.run_command("show_panel", {"panel": "output.test", "toggle": True})
+    assert w.active_panel() == "output.test"
+    w.run_command("show_panel", {"panel": "output.test", "toggle": True})
+    assert w.active_panel() is None
+
+    w.create_output_panel("unlisted", True)
+    assert "output.unlisted" not in w.panels()
+    assert w.find_output_panel("unlisted") is not None
+
+    w.destroy_output_panel("test")
+    w.destroy_output_panel("unlisted")
+    assert w.find_output_panel("test") is None
+    assert "output.test" not in w.panels()
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,61 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    v.run_command("insert", {"characters": "hello world\n"})
+
+    hidden = []
+    assert not v.is_popup_visible()
+    v.show_popup("<b>hi</b>", sublime.HIDE_ON_MOUSE_MOVE_AWAY, 3, on_hide=lambda: hidden.append(1))
+    assert v.is_popup_visible()
+    v.update_popup("<i>there</i>")
+    try:
+        v.update_popup("<i>broken</b>")
+        assert False
+    except AssertionError:
+        raise
+    except:
+        pass
+    v.hide_popup()
+    assert not v.is_popup_visible()
+    assert hidden == [1]
+
+    # showing a popup replaces the visible one
+    v.show_popup("one", on_hide=lambda: hidden.append(2))
+    v.show_popup("two")
+    assert hidden == [1, 2]
+    v.hide_popup()
+
+    pid = v.add_phantom("lint", sublime.Region(6, 11), "<span>error</span>", sublime.LAYOUT_BELOW)
+    assert v.query_phantom(pid) == [sublime.Region(6, 11)]
+    v.sel().clear()
+    v.sel().add(sublime.Region(0, 0))
+    v.run_command("insert", {"characters": ">> "})
+    assert v.query_phantom(pid) == [sublime.Region(9, 14)]
+    v.erase_phantoms("lint")
+    assert v.query_phantom(pid) == []
+
+    ps = sublime.PhantomSet(v, "doc")
+    a = sublime.Phantom(sublime.Region(0, 0), "a", sublime.LAYOUT_INLINE)
+    b = sublime.Phantom(sublime.Region(1, 1), "b", sublime.LAYOUT_BLOCK)
+    assert a.region == sublime.Region(0, 0) and a.content == "a" and a.layout == sublime.LAYOUT_INLINE
+    assert a == sublime.Phantom(sublime.Region(0, 0), "a", sublime.LAYOUT_INLINE)
+    assert a != b
+
+    ps.update([a, b])
+    assert v.query_phantoms([a.id, b.id]) == [sublime.Region(0, 0), sublime.Region(1, 1)]
+    ids = (a.id, b.id)
+    a2 = sublime.Phantom(sublime.Region(0, 0), "a", sublime.LAYOUT_INLINE)
+    c = sublime.Phantom(sublime.Region(2, 2), "c", sublime.LAYOUT_INLINE)
+    ps.update([a2, c])
+    assert a2.id == ids[0]
+    assert c.id not in ids
+    assert v.query_phantom(ids[1]) == []
+    ps.update([])
+    assert v.query_phantoms([a2.id, c.id]) == []
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,22 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    w = sublime.active_window()
+    assert w.project_file_name() is None
+    assert "platform" in w.extract_variables()
+
+    w.set_project_data({"folders": [{"path": "/tmp"}], "settings": {"project_test": 1}})
+    assert w.project_data()["folders"][0]["path"] == "/tmp"
+    assert w.folders() == ["/tmp"]
+    assert w.extract_variables()["folder"] == "/tmp"
+    assert w.settings().get("project_test") == 1
+
+    w.set_project_data({"folders": []})
+    assert w.folders() == []
+    assert w.settings().get("project_test") is None
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -33,6 +33,23 @@ try:
 
     r3 = sublime.Region(3, 2)
     assert r3.begin() == 2 and r3.end() == 3
+
+    assert sublime.Region(1, 2).xpos == -1
+    r3 = sublime.Region(1, 2, 5)
+    assert r3.xpos == 5
+    r3.xpos = 7
+    assert r3.xpos == 7
+
+    assert r.contains(sublime.Region(2, 3)) and not r.contains(sublime.Region(2, 4))
+    assert r.intersects(sublime.Region(2, 5)) and not r.intersects(sublime.Region(3, 5))
+    assert sublime.Region(2, 2).intersects(sublime.Region(2, 2))
+    assert r.intersection(sublime.Region(2, 5)) == (2, 3)
+    assert r.intersection(sublime.Region(3, 5)) == (0, 0)
+    assert r.intersection((5, 0)) == (1, 3)
+    assert len(r) == 2
+    assert sublime.Region(1, 3) < sublime.Region(2, 3) and sublime.Region(1, 2) < (1, 3)
+    assert not sublime.Region(1, 2) == None and sublime.Region(1, 2) != "a"
+    assert sublime.Region(1, 2) not in [None, "a"]
 except:
     print(sys.exc_info()[1])
     traceback.print_exc()
@@ -9,6 +9,37 @@ try:
     except:
         ok = True
     assert ok
+
+    v = sublime.active_window().new_file()
+    s = v.sel()
+    s.clear()
+    s.add_all([(0, 2), sublime.Region(5, 6), (8, 8)])
+    assert len(s) == 3
+    assert s[0] == (0, 2) and s[-1] == (8, 8)
+    assert s[1:] == [sublime.Region(5, 6), sublime.Region(8, 8)]
+    assert s[::2] == [(0, 2), (8, 8)]
+    assert [r.a for r in s] == [0, 5, 8]
+    assert sublime.Region(5, 6) in s and (0, 1) in s and (3, 4) not in s
+
+    # adjacent regions merge
+    s.add((2, 5))
+    assert s == [(0, 6), (8, 8)]
+    s.add(sublime.Region(8, 8))
+    assert len(s) == 2
+
+    s.subtract((0, 6))
+    assert s == [(8, 8)]
+    try:
+        s[1]
+        assert False
+    except IndexError:
+        pass
+
+    e = v.begin_edit()
+    v.insert(e, 0, "ab\ncd\n\nef")
+    v.end_edit(e)
+    assert v.split_by_newlines(sublime.Region(1, 9)) == [(1, 2), (3, 5), (6, 6), (7, 9)]
+    assert not v.sel() == None and v.sel() != ["a"]
 except:
     print(sys.exc_info()[1])
     traceback.print_exc()
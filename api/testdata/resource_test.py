@@ -0,0 +1,17 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    assert sublime.find_resources("no such file") == []
+    for load in (sublime.load_resource, sublime.load_binary_resource):
+        failed = False
+        try:
+            load("Packages/No Such Package/file")
+        except Exception:
+            failed = True
+        assert failed
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
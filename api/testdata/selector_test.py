@@ -0,0 +1,22 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    scope = "source.python meta.function string.quoted"
+    assert sublime.score_selector(scope, "comment") == 0
+    assert sublime.score_selector(scope, "") == 1
+    assert sublime.score_selector(scope, "source") > 0
+    assert sublime.score_selector(scope, "string") > sublime.score_selector(scope, "source")
+    assert sublime.score_selector(scope, "string.quoted") > sublime.score_selector(scope, "string")
+    assert sublime.score_selector(scope, "source - string") == 0
+    assert sublime.score_selector(scope, "source - (comment | constant)") > 0
+    assert sublime.score_selector(scope, "text, meta & string") > 0
+
+    v = sublime.active_window().new_file()
+    assert v.match_selector(0, "") == True
+    assert v.score_selector(0, "") == 1
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,28 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    s = sublime.load_settings("Python Test.sublime-settings")
+    assert s.get("key") is None
+    changes = []
+    s.add_on_change("test", lambda: changes.append(True))
+    s.set("key", "value")
+    assert s.get("key") == "value"
+    assert sublime.load_settings("Python Test.sublime-settings").get("key") == "value"
+    assert len(changes) == 1
+    s.clear_on_change("test")
+    s.set("key", "other")
+    assert len(changes) == 1
+
+    def on_change():
+        pass
+    refs = sys.getrefcount(on_change)
+    s.add_on_change("refs", on_change)
+    assert sys.getrefcount(on_change) == refs + 1
+    s.clear_on_change("refs")
+    assert sys.getrefcount(on_change) == refs
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
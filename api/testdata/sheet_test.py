@@ -0,0 +1,32 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    w = sublime.active_window()
+    v = w.new_file()
+    s = w.active_sheet()
+    assert s is not None
+    assert s.view().id() == v.id()
+    assert s.window().id() == w.id()
+    assert s in w.sheets()
+    assert s == w.active_sheet_in_group(0)
+    assert w.sheets_in_group(0)[-1] == s
+    assert not s == None and s != v and s in [v, s]
+
+    group, index = w.get_sheet_index(s)
+    assert group == 0
+    assert index == len(w.sheets_in_group(0)) - 1
+    w.set_sheet_index(s, 0, 0)
+    assert w.get_sheet_index(s) == (0, 0)
+    assert w.get_view_index(v) == (0, 0)
+
+    w.focus_sheet(s)
+    assert w.active_view().id() == v.id()
+
+    assert w.transient_sheet_in_group(0) is None
+    assert w.transient_view_in_group(0) is None
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
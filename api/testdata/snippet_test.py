@@ -0,0 +1,25 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    v.run_command("insert_snippet", {"contents": "for ${1:i} in ${2:$NAME}: $1${1/i/j/}$0", "NAME": "range"})
+    assert v.substr(sublime.Region(0, v.size())) == "for i in range: ij"
+    assert list(v.sel()) == [sublime.Region(4, 5), sublime.Region(16, 17)]
+
+    v.run_command("insert", {"characters": "x"})
+    assert v.substr(sublime.Region(0, v.size())) == "for x in range: xj"
+    v.run_command("next_field")
+    assert v.substr(sublime.Region(0, v.size())) == "for x in range: xx"
+    assert list(v.sel()) == [sublime.Region(9, 14)]
+
+    v.run_command("prev_field")
+    assert list(v.sel()) == [sublime.Region(4, 5), sublime.Region(16, 17)]
+    v.run_command("next_field")
+    v.run_command("next_field")
+    assert list(v.sel()) == [sublime.Region(18, 18)]
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -0,0 +1,38 @@
+import sys
+import traceback
+try:
+    import sublime
+
+    v = sublime.active_window().new_file()
+    v.settings().set("tab_size", 4)
+    v.run_command("insert", {"characters": "ab\tc\nxyz"})
+
+    # headless views have fixed metrics
+    assert v.line_height() == 16.0
+    assert v.em_width() == 8.0
+    assert v.viewport_extent() == (800.0, 600.0)
+    assert v.layout_extent() == (40.0, 32.0)
+
+    assert v.text_to_layout(0) == (0.0, 0.0)
+    assert v.text_to_layout(3) == (32.0, 0.0)
+    assert v.text_to_layout(7) == (16.0, 16.0)
+    assert v.layout_to_text((33, 0)) == 3
+    assert v.layout_to_text((13.0, 0.0)) == 2
+    assert v.layout_to_text((0, 100)) == 5
+    assert v.layout_to_text((-10, -10)) == 0
+
+    assert v.viewport_position() == (0.0, 0.0)
+    v.set_viewport_position((10, 8), False)
+    assert v.viewport_position() == (10.0, 8.0)
+    assert v.window_to_text((6, 8)) == 7
+    v.set_viewport_position((-5, 1000))
+    assert v.viewport_position() == (0.0, 32.0)
+
+    v.show_at_center(0)
+    assert v.viewport_position() == (0.0, 0.0)
+    v.show_at_center(sublime.Region(7, 8))
+    assert v.viewport_position() == (0.0, 0.0)
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
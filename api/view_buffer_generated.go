@@ -20,19 +20,10 @@ var (
 	_ = fmt.Errorf
 )
 
-func (o *View) Py_change_count() (py.Object, error) {
-	ret0 := o.data.ChangeCount()
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
+func (o *View) Py_file_name() (py.Object, error) {
+	if err := o.check(); err != nil {
 		return nil, err
 	}
-	return pyret0, err
-}
-
-func (o *View) Py_file_name() (py.Object, error) {
 	ret0 := o.data.FileName()
 	var err error
 	var pyret0 py.Object
@@ -45,6 +36,9 @@ func (o *View) Py_file_name() (py.Object, error) {
 }
 
 func (o *View) fullline(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 	)
@@ -73,6 +67,9 @@ func (o *View) fullline(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) fullliner(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 text.Region
 	)
@@ -101,6 +98,9 @@ func (o *View) fullliner(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_buffer_id() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := o.data.Id()
 	var err error
 	var pyret0 py.Object
@@ -113,6 +113,9 @@ func (o *View) Py_buffer_id() (py.Object, error) {
 }
 
 func (o *View) line(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 	)
@@ -141,6 +144,9 @@ func (o *View) line(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) liner(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 text.Region
 	)
@@ -169,6 +175,9 @@ func (o *View) liner(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_lines(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 text.Region
 	)
@@ -197,6 +206,9 @@ func (o *View) Py_lines(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_name() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := o.data.Name()
 	var err error
 	var pyret0 py.Object
@@ -209,6 +221,9 @@ func (o *View) Py_name() (py.Object, error) {
 }
 
 func (o *View) Py_rowcol(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 	)
@@ -244,6 +259,9 @@ func (o *View) Py_rowcol(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_set_name(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 	)
@@ -268,6 +286,9 @@ func (o *View) Py_set_name(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_size() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := o.data.Size()
 	var err error
 	var pyret0 py.Object
@@ -280,6 +301,9 @@ func (o *View) Py_size() (py.Object, error) {
 }
 
 func (o *View) Py_text_point(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 		arg2 int
@@ -322,6 +346,9 @@ func (o *View) Py_text_point(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) word(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 	)
@@ -350,6 +377,9 @@ func (o *View) word(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) wordr(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 text.Region
 	)
@@ -33,19 +33,10 @@ type View struct {
 func (o *View) PyInit(args *py.Tuple, kwds *py.Dict) error {
 	return fmt.Errorf("Can't initialize type View")
 }
-func (o *View) Py_begin_edit() (py.Object, error) {
-	ret0 := o.data.BeginEdit()
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
+func (o *View) Py_classify(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
 		return nil, err
 	}
-	return pyret0, err
-}
-
-func (o *View) Py_classify(tu *py.Tuple) (py.Object, error) {
 	var (
 		arg1 int
 	)
@@ -73,63 +64,10 @@ func (o *View) Py_classify(tu *py.Tuple) (py.Object, error) {
 	return pyret0, err
 }
 
-func (o *View) Py_end_edit(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 *backend.Edit
-	)
-	if v, err := tu.GetItem(0); err != nil {
+func (o *View) Py_erase_regions(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
 		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(*backend.Edit); !ok {
-				return nil, fmt.Errorf("Expected type *backend.Edit for backend.View.EndEdit() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
 	}
-	o.data.EndEdit(arg1)
-	return toPython(nil)
-}
-
-func (o *View) Py_erase(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 *backend.Edit
-		arg2 text.Region
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(*backend.Edit); !ok {
-				return nil, fmt.Errorf("Expected type *backend.Edit for backend.View.Erase() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	if v, err := tu.GetItem(1); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.Region); !ok {
-				return nil, fmt.Errorf("Expected type text.Region for backend.View.Erase() arg2, not %s", v.Type())
-			} else {
-				arg2 = v2
-			}
-		}
-	}
-	o.data.Erase(arg1, arg2)
-	return toPython(nil)
-}
-
-func (o *View) Py_erase_regions(tu *py.Tuple) (py.Object, error) {
 	var (
 		arg1 string
 	)
@@ -151,6 +89,9 @@ func (o *View) Py_erase_regions(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_erase_status(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 	)
@@ -172,6 +113,9 @@ func (o *View) Py_erase_status(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_extract_scope(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 	)
@@ -200,6 +144,9 @@ func (o *View) Py_extract_scope(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_find_by_class(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 		arg2 bool
@@ -256,6 +203,9 @@ func (o *View) Py_find_by_class(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_get_regions(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 	)
@@ -284,6 +234,9 @@ func (o *View) Py_get_regions(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_get_status(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 	)
@@ -323,63 +276,10 @@ func (o *View) Py_id() (py.Object, error) {
 	return pyret0, err
 }
 
-func (o *View) Py_insert(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 *backend.Edit
-		arg2 int
-		arg3 string
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(*backend.Edit); !ok {
-				return nil, fmt.Errorf("Expected type *backend.Edit for backend.View.Insert() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	if v, err := tu.GetItem(1); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(int); !ok {
-				return nil, fmt.Errorf("Expected type int for backend.View.Insert() arg2, not %s", v.Type())
-			} else {
-				arg2 = v2
-			}
-		}
-	}
-	if v, err := tu.GetItem(2); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(string); !ok {
-				return nil, fmt.Errorf("Expected type string for backend.View.Insert() arg3, not %s", v.Type())
-			} else {
-				arg3 = v2
-			}
-		}
-	}
-	ret0 := o.data.Insert(arg1, arg2, arg3)
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
+func (o *View) Py_is_dirty() (py.Object, error) {
+	if err := o.check(); err != nil {
 		return nil, err
 	}
-	return pyret0, err
-}
-
-func (o *View) Py_is_dirty() (py.Object, error) {
 	ret0 := o.data.IsDirty()
 	var err error
 	var pyret0 py.Object
@@ -392,6 +292,9 @@ func (o *View) Py_is_dirty() (py.Object, error) {
 }
 
 func (o *View) Py_is_scratch() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := o.data.IsScratch()
 	var err error
 	var pyret0 py.Object
@@ -404,6 +307,9 @@ func (o *View) Py_is_scratch() (py.Object, error) {
 }
 
 func (o *View) Py_overwrite_status() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := o.data.OverwriteStatus()
 	var err error
 	var pyret0 py.Object
@@ -415,56 +321,10 @@ func (o *View) Py_overwrite_status() (py.Object, error) {
 	return pyret0, err
 }
 
-func (o *View) Py_replace(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 *backend.Edit
-		arg2 text.Region
-		arg3 string
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(*backend.Edit); !ok {
-				return nil, fmt.Errorf("Expected type *backend.Edit for backend.View.Replace() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	if v, err := tu.GetItem(1); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(text.Region); !ok {
-				return nil, fmt.Errorf("Expected type text.Region for backend.View.Replace() arg2, not %s", v.Type())
-			} else {
-				arg2 = v2
-			}
-		}
-	}
-	if v, err := tu.GetItem(2); err != nil {
+func (o *View) Py_scope_name(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
 		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(string); !ok {
-				return nil, fmt.Errorf("Expected type string for backend.View.Replace() arg3, not %s", v.Type())
-			} else {
-				arg3 = v2
-			}
-		}
 	}
-	o.data.Replace(arg1, arg2, arg3)
-	return toPython(nil)
-}
-
-func (o *View) Py_scope_name(tu *py.Tuple) (py.Object, error) {
 	var (
 		arg1 int
 	)
@@ -492,49 +352,10 @@ func (o *View) Py_scope_name(tu *py.Tuple) (py.Object, error) {
 	return pyret0, err
 }
 
-func (o *View) Py_score_selector(tu *py.Tuple) (py.Object, error) {
-	var (
-		arg1 int
-		arg2 string
-	)
-	if v, err := tu.GetItem(0); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(int); !ok {
-				return nil, fmt.Errorf("Expected type int for backend.View.ScoreSelector() arg1, not %s", v.Type())
-			} else {
-				arg1 = v2
-			}
-		}
-	}
-	if v, err := tu.GetItem(1); err != nil {
-		return nil, err
-	} else {
-		if v3, err2 := fromPython(v); err2 != nil {
-			return nil, err2
-		} else {
-			if v2, ok := v3.(string); !ok {
-				return nil, fmt.Errorf("Expected type string for backend.View.ScoreSelector() arg2, not %s", v.Type())
-			} else {
-				arg2 = v2
-			}
-		}
-	}
-	ret0 := o.data.ScoreSelector(arg1, arg2)
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
+func (o *View) Py_sel() (py.Object, error) {
+	if err := o.check(); err != nil {
 		return nil, err
 	}
-	return pyret0, err
-}
-
-func (o *View) Py_sel() (py.Object, error) {
 	ret0 := o.data.Sel()
 	var err error
 	var pyret0 py.Object
@@ -547,6 +368,9 @@ func (o *View) Py_sel() (py.Object, error) {
 }
 
 func (o *View) Py_set_overwrite_status(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 bool
 	)
@@ -568,6 +392,9 @@ func (o *View) Py_set_overwrite_status(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_set_scratch(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 bool
 	)
@@ -589,6 +416,9 @@ func (o *View) Py_set_scratch(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_set_status(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 		arg2 string
@@ -624,6 +454,9 @@ func (o *View) Py_set_status(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_settings() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := o.data.Settings()
 	var err error
 	var pyret0 py.Object
@@ -640,6 +473,9 @@ func (o *View) PyStr() string {
 }
 
 func (o *View) Py_window() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := o.data.Window()
 	var err error
 	var pyret0 py.Object
@@ -24,6 +24,9 @@ func (v *View) Py_has_non_empty_selection_region() (py.Object, error) {
 }
 
 func (view *View) Py_show(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := view.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 text.Region
 	)
@@ -46,6 +49,9 @@ func (view *View) Py_show(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
 }
 
 func (o *View) Py_substr(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 text.Region
 	)
@@ -76,6 +82,9 @@ func (o *View) Py_substr(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_add_regions(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 		arg2 []text.Region
@@ -116,6 +125,9 @@ func (o *View) Py_add_regions(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
 }
 
 func (o *View) Py_command_history(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 int
 		arg2 bool
@@ -173,6 +185,9 @@ func (o *View) Py_command_history(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_run_command(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 		arg2 backend.Args
@@ -203,6 +218,9 @@ func (o *View) Py_run_command(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_visible_region() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	ret0 := backend.GetEditor().Frontend().VisibleRegion(o.data)
 	var err error
 	var pyret0 py.Object
@@ -213,7 +231,7 @@ func (o *View) Py_visible_region() (py.Object, error) {
 	} else if v2, ok := pyret0.(*Region); !ok {
 		return nil, fmt.Errorf("Unable to convert return value to the right type?!: %s", pyret0.Type())
 	} else {
-		v2.data = ret0
+		v2.data, v2.xpos = ret0, -1
 	}
 	if err != nil {
 		return nil, err
@@ -222,6 +240,9 @@ func (o *View) Py_visible_region() (py.Object, error) {
 }
 
 func (o *View) Py_line(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	v, err := tu.GetItem(0)
 	if err != nil {
 		return nil, err
@@ -234,6 +255,9 @@ func (o *View) Py_line(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_full_line(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	v, err := tu.GetItem(0)
 	if err != nil {
 		return nil, err
@@ -245,6 +269,9 @@ func (o *View) Py_full_line(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_word(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	v, err := tu.GetItem(0)
 	if err != nil {
 		return nil, err
@@ -256,6 +283,9 @@ func (o *View) Py_word(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_set_syntax_file(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	v, err := tu.GetItem(0)
 	if err != nil {
 		return nil, err
@@ -267,6 +297,9 @@ func (o *View) Py_set_syntax_file(tu *py.Tuple) (py.Object, error) {
 }
 
 func (o *View) Py_expand_by_class(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 text.Region
 		arg2 int
@@ -313,6 +346,9 @@ func (o *View) Py_expand_by_class(tu *py.Tuple, kw *py.Dict) (py.Object, error)
 }
 
 func (o *View) Py_find(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
 	var (
 		arg1 string
 		arg2 int
@@ -365,3 +401,32 @@ func (o *View) Py_find(tu *py.Tuple) (py.Object, error) {
 	}
 	return pyret0, err
 }
+
+// SplitByNewlines returns the parts of the region on each line, without the
+// newlines.
+func SplitByNewlines(v *backend.View, r text.Region) []text.Region {
+	lines := v.Lines(r)
+	if len(lines) == 0 {
+		return []text.Region{{A: r.Begin(), B: r.Begin()}}
+	}
+	ret := make([]text.Region, len(lines))
+	for i, l := range lines {
+		ret[i] = regionIntersection(l, r)
+		if ret[i].Empty() {
+			// empty lines and lines only touching the region
+			ret[i] = text.Region{A: l.Begin(), B: l.Begin()}
+		}
+	}
+	return ret
+}
+
+func (o *View) Py_split_by_newlines(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	r, err := regionArg(tu, 0, "region")
+	if err != nil {
+		return nil, err
+	}
+	return regionsToPython(SplitByNewlines(o.data, r))
+}
@@ -0,0 +1,328 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/gopy"
+	"github.com/limetext/text"
+)
+
+type (
+	// Metrics are the sizes of a view in pixels as reported by the
+	// frontend. Layout coordinates start at the top left of the text and
+	// the viewport is the visible part of the layout.
+	Metrics struct {
+		LineHeight float64
+		EmWidth    float64
+		// Position of the viewport in layout coordinates and its size
+		X, Y          float64
+		Width, Height float64
+	}
+
+	// MetricsFrontend is implemented by frontends that are able to report
+	// the geometry of views. Frontends without it are treated as headless
+	// and use FixedMetrics.
+	MetricsFrontend interface {
+		Metrics(v *backend.View) Metrics
+		// SetViewportPosition scrolls the view so the layout point is at
+		// the top left of the viewport.
+		SetViewportPosition(v *backend.View, x, y float64, animate bool)
+	}
+
+	// FixedMetrics is a MetricsFrontend for headless use, all the views have
+	// the same sizes and the viewport only moves when it's set.
+	FixedMetrics struct {
+		LineHeight    float64
+		EmWidth       float64
+		Width, Height float64
+		lock          sync.Mutex
+		positions     map[*backend.View][2]float64
+	}
+)
+
+var headless = &FixedMetrics{LineHeight: 16, EmWidth: 8, Width: 800, Height: 600}
+
+func (f *FixedMetrics) Metrics(v *backend.View) Metrics {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	pos := f.positions[v]
+	return Metrics{
+		LineHeight: f.LineHeight,
+		EmWidth:    f.EmWidth,
+		X:          pos[0],
+		Y:          pos[1],
+		Width:      f.Width,
+		Height:     f.Height,
+	}
+}
+
+func (f *FixedMetrics) SetViewportPosition(v *backend.View, x, y float64, animate bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.positions == nil {
+		f.positions = make(map[*backend.View][2]float64)
+	}
+	f.positions[v] = [2]float64{x, y}
+}
+
+func onFixedMetricsClose(v *backend.View) {
+	headless.lock.Lock()
+	defer headless.lock.Unlock()
+	delete(headless.positions, v)
+}
+
+func metricsFrontend() MetricsFrontend {
+	if fe, ok := backend.GetEditor().Frontend().(MetricsFrontend); ok {
+		return fe
+	}
+	return headless
+}
+
+// MetricsOf returns the current metrics of the view.
+func MetricsOf(v *backend.View) Metrics {
+	return metricsFrontend().Metrics(v)
+}
+
+// Returns the number of columns the text takes with tabs expanded.
+func columns(s string, tabSize int) int {
+	col := 0
+	for _, r := range s {
+		if r == '\t' && tabSize > 0 {
+			col += tabSize - col%tabSize
+		} else {
+			col++
+		}
+	}
+	return col
+}
+
+// TextToLayout returns the layout coordinates of the top left of the text
+// point.
+func TextToLayout(v *backend.View, pt int) (x, y float64) {
+	m := MetricsOf(v)
+	row, _ := v.RowCol(pt)
+	line := v.Line(pt)
+	col := columns(v.Substr(text.Region{A: line.Begin(), B: pt}), tabSize(v))
+	return float64(col) * m.EmWidth, float64(row) * m.LineHeight
+}
+
+// LayoutToText returns the text point closest to the layout coordinates.
+func LayoutToText(v *backend.View, x, y float64) int {
+	m := MetricsOf(v)
+	last, _ := v.RowCol(v.Size())
+	row := 0
+	if m.LineHeight > 0 {
+		row = int(math.Floor(y / m.LineHeight))
+	}
+	if row < 0 {
+		row = 0
+	} else if row > last {
+		row = last
+	}
+	line := v.Line(v.TextPoint(row, 0))
+	pt, col, ts := line.Begin(), 0, tabSize(v)
+	for _, r := range v.Substr(line) {
+		next := col + 1
+		if r == '\t' && ts > 0 {
+			next = col + ts - col%ts
+		}
+		// past the middle of the character belongs to the next point
+		if float64(col+next)/2*m.EmWidth > x {
+			break
+		}
+		col = next
+		pt++
+	}
+	return pt
+}
+
+// WindowToText returns the text point closest to the coordinates relative
+// to the top left of the viewport.
+func WindowToText(v *backend.View, x, y float64) int {
+	m := MetricsOf(v)
+	return LayoutToText(v, x+m.X, y+m.Y)
+}
+
+// LayoutExtent returns the width of the widest line and the height of all
+// the lines of the view.
+func LayoutExtent(v *backend.View) (w, h float64) {
+	m := MetricsOf(v)
+	lines := strings.Split(v.Substr(text.Region{A: 0, B: v.Size()}), "\n")
+	widest, ts := 0, tabSize(v)
+	for _, l := range lines {
+		if c := columns(strings.TrimSuffix(l, "\r"), ts); c > widest {
+			widest = c
+		}
+	}
+	return float64(widest) * m.EmWidth, float64(len(lines)) * m.LineHeight
+}
+
+// SetViewportPosition scrolls the view so the layout point is at the top
+// left of the viewport, the position is clamped to the layout.
+func SetViewportPosition(v *backend.View, x, y float64, animate bool) {
+	w, h := LayoutExtent(v)
+	x = math.Max(0, math.Min(x, w))
+	y = math.Max(0, math.Min(y, h))
+	metricsFrontend().SetViewportPosition(v, x, y, animate)
+}
+
+// ShowAtCenter scrolls the view so the text point is vertically centered,
+// it's only scrolled horizontally when the point isn't visible.
+func ShowAtCenter(v *backend.View, pt int) {
+	m := MetricsOf(v)
+	x, y := TextToLayout(v, pt)
+	vx := m.X
+	if x < m.X || x >= m.X+m.Width {
+		vx = x - m.Width/2
+	}
+	SetViewportPosition(v, vx, y+m.LineHeight/2-m.Height/2, true)
+}
+
+// Returns the x and y of the (x, y) tuple argument.
+func vectorArg(tu *py.Tuple, i int64, name string) (x, y float64, err error) {
+	v, ok := pyArg(tu, nil, i, name)
+	if !ok {
+		return 0, 0, fmt.Errorf("Missing %s argument", name)
+	}
+	v2, err := fromPython(v)
+	if err != nil {
+		return 0, 0, err
+	}
+	t, ok := v2.(Tuple)
+	if !ok || len(t) != 2 {
+		return 0, 0, fmt.Errorf("Expected (x, y) tuple for %s, not %s", name, v.Type())
+	}
+	var xy [2]float64
+	for i, c := range t {
+		switch n := c.(type) {
+		case int:
+			xy[i] = float64(n)
+		case float64:
+			xy[i] = n
+		default:
+			return 0, 0, fmt.Errorf("Expected (x, y) tuple for %s, not %v", name, t)
+		}
+	}
+	return xy[0], xy[1], nil
+}
+
+func (o *View) Py_viewport_position() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	m := MetricsOf(o.data)
+	return toPython(Tuple{m.X, m.Y})
+}
+
+func (o *View) Py_set_viewport_position(tu *py.Tuple, kw *py.Dict) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	x, y, err := vectorArg(tu, 0, "xy")
+	if err != nil {
+		return nil, err
+	}
+	animate := true
+	if v, ok := pyArg(tu, kw, 1, "animate"); ok {
+		animate = v.IsTrue()
+	}
+	SetViewportPosition(o.data, x, y, animate)
+	return toPython(nil)
+}
+
+func (o *View) Py_viewport_extent() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	m := MetricsOf(o.data)
+	return toPython(Tuple{m.Width, m.Height})
+}
+
+func (o *View) Py_layout_extent() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	w, h := LayoutExtent(o.data)
+	return toPython(Tuple{w, h})
+}
+
+func (o *View) Py_text_to_layout(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	pt, err := pyIntArg(tu, nil, 0, "tp", 0)
+	if err != nil {
+		return nil, err
+	}
+	x, y := TextToLayout(o.data, pt)
+	return toPython(Tuple{x, y})
+}
+
+func (o *View) Py_layout_to_text(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	x, y, err := vectorArg(tu, 0, "vector")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(LayoutToText(o.data, x, y))
+}
+
+func (o *View) Py_window_to_text(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	x, y, err := vectorArg(tu, 0, "vector")
+	if err != nil {
+		return nil, err
+	}
+	return toPython(WindowToText(o.data, x, y))
+}
+
+func (o *View) Py_line_height() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(MetricsOf(o.data).LineHeight)
+}
+
+func (o *View) Py_em_width() (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return toPython(MetricsOf(o.data).EmWidth)
+}
+
+func (o *View) Py_show_at_center(tu *py.Tuple) (py.Object, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	v, ok := pyArg(tu, nil, 0, "x")
+	if !ok {
+		return nil, fmt.Errorf("Missing x argument")
+	}
+	var pt int
+	switch t := v.(type) {
+	case *Region:
+		pt = t.data.Begin()
+	case *py.Long:
+		pt = int(t.Int64())
+	default:
+		return nil, fmt.Errorf("Expected type Region or int for x, not %s", v.Type())
+	}
+	ShowAtCenter(o.data, pt)
+	return toPython(nil)
+}
+
+func init() {
+	backend.OnClose.Add(onFixedMetricsClose)
+}
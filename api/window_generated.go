@@ -84,15 +84,3 @@ func (o *Window) Py_settings() (py.Object, error) {
 func (o *Window) PyStr() string {
 	return o.data.String()
 }
-
-func (o *Window) Py_views() (py.Object, error) {
-	ret0 := o.data.Views()
-	var err error
-	var pyret0 py.Object
-
-	pyret0, err = toPython(ret0)
-	if err != nil {
-		return nil, err
-	}
-	return pyret0, err
-}
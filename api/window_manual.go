@@ -43,7 +43,7 @@ func (o *Window) Py_open_file(tu *py.Tuple) (py.Object, error) {
 			arg2 = int(v2.Int64())
 		}
 	}
-	ret0 := o.data.OpenFile(arg1, arg2)
+	ret0 := OpenFile(o.data, arg1, arg2)
 	var pyret0 py.Object
 
 	pyret0, err = _viewClass.Alloc(1)
@@ -0,0 +1,191 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package build implements sublime build systems.
+// https://www.sublimetext.com/docs/3/build_systems.html
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/limetext/loaders"
+)
+
+type (
+	// System is a build system, the keys used for running the build are
+	// kept as is in Args since they are given to the target command.
+	System struct {
+		Name       string
+		Selector   string
+		FileRegex  string
+		LineRegex  string
+		WorkingDir string
+		// the window command running the build, empty means exec
+		Target   string
+		Variants []*System
+		// all the keys of the build system except variants
+		Args map[string]interface{}
+	}
+)
+
+// Load parses a sublime-build file, the build system name is the file base
+// name.
+func Load(name string, data []byte) (*System, error) {
+	var m map[string]interface{}
+	if err := loaders.LoadJSON(data, &m); err != nil {
+		return nil, err
+	}
+	s, err := FromMap(m)
+	if err != nil {
+		return nil, err
+	}
+	s.Name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	return s, nil
+}
+
+// FromMap returns the build system defined by m, like the ones defined in
+// project files.
+func FromMap(m map[string]interface{}) (*System, error) {
+	s := &System{Args: make(map[string]interface{})}
+	for k, v := range m {
+		if k == "variants" {
+			continue
+		}
+		s.Args[k] = v
+	}
+	str := func(key string, dst *string) error {
+		v, ok := m[key]
+		if !ok {
+			return nil
+		}
+		if *dst, ok = v.(string); !ok {
+			return fmt.Errorf("Expected string for build system %s, not %T", key, v)
+		}
+		return nil
+	}
+	for key, dst := range map[string]*string{
+		"name":        &s.Name,
+		"selector":    &s.Selector,
+		"file_regex":  &s.FileRegex,
+		"line_regex":  &s.LineRegex,
+		"working_dir": &s.WorkingDir,
+		"target":      &s.Target,
+	} {
+		if err := str(key, dst); err != nil {
+			return nil, err
+		}
+	}
+	delete(s.Args, "target")
+	delete(s.Args, "selector")
+	delete(s.Args, "name")
+
+	if v, ok := m["variants"]; ok {
+		vs, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected list for build system variants, not %T", v)
+		}
+		for _, v := range vs {
+			vm, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Expected build system variants to be objects, not %T", v)
+			}
+			vs, err := FromMap(vm)
+			if err != nil {
+				return nil, err
+			}
+			s.Variants = append(s.Variants, vs)
+		}
+	}
+	return s, nil
+}
+
+// Variant returns the build system with the keys of the named variant
+// overriding the base keys, nil if there is no such variant.
+func (s *System) Variant(name string) *System {
+	for _, v := range s.Variants {
+		if v.Name != name {
+			continue
+		}
+		ret := &System{
+			Name:     s.Name,
+			Selector: s.Selector,
+			Target:   s.Target,
+			Args:     make(map[string]interface{}),
+		}
+		for k, v := range s.Args {
+			ret.Args[k] = v
+		}
+		for k, v := range v.Args {
+			ret.Args[k] = v
+		}
+		if v.Target != "" {
+			ret.Target = v.Target
+		}
+		ret.FileRegex, _ = ret.Args["file_regex"].(string)
+		ret.LineRegex, _ = ret.Args["line_regex"].(string)
+		ret.WorkingDir, _ = ret.Args["working_dir"].(string)
+		return ret
+	}
+	return nil
+}
+
+// Command returns the name of the window command running the build.
+func (s *System) Command() string {
+	if s.Target == "" {
+		return "exec"
+	}
+	return s.Target
+}
+
+// Expand returns the arguments of the build system with the variables
+// expanded.
+func (s *System) Expand(vars map[string]string) map[string]interface{} {
+	ret := make(map[string]interface{}, len(s.Args))
+	for k, v := range s.Args {
+		ret[k] = ExpandVariables(v, vars)
+	}
+	return ret
+}
+
+func (s *System) String() string {
+	d, _ := json.Marshal(s.Args)
+	return fmt.Sprintf("%s: %s", s.Name, d)
+}
+
+var systems = struct {
+	sync.Mutex
+	m map[string]*System
+}{m: make(map[string]*System)}
+
+// Add registers the build system under the given key which is usually the
+// resource name of its file.
+func Add(key string, s *System) {
+	systems.Lock()
+	defer systems.Unlock()
+	systems.m[key] = s
+}
+
+// Get returns the build system registered with key.
+func Get(key string) *System {
+	systems.Lock()
+	defer systems.Unlock()
+	return systems.m[key]
+}
+
+// Keys returns the sorted keys of the registered build systems.
+func Keys() []string {
+	systems.Lock()
+	defer systems.Unlock()
+	ret := make([]string, 0, len(systems.m))
+	for k := range systems.m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
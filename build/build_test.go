@@ -0,0 +1,121 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package build
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testBuild = `{
+	// comments are allowed
+	"shell_cmd": "make",
+	"selector": "source.c",
+	"file_regex": "^(.+):(\\d+)",
+	"variants": [
+		{
+			"name": "Clean",
+			"shell_cmd": "make clean"
+		},
+		{
+			"name": "Run",
+			"target": "run_build",
+		}
+	]
+}`
+
+func TestLoad(t *testing.T) {
+	s, err := Load("Packages/C/Make.sublime-build", []byte(testBuild))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "Make" {
+		t.Errorf("Expected name Make, but got %s", s.Name)
+	}
+	if s.Selector != "source.c" {
+		t.Errorf("Expected selector source.c, but got %s", s.Selector)
+	}
+	if s.FileRegex != `^(.+):(\d+)` {
+		t.Errorf("Expected file regex ^(.+):(\\d+), but got %s", s.FileRegex)
+	}
+	if s.Command() != "exec" {
+		t.Errorf("Expected command exec, but got %s", s.Command())
+	}
+	if _, ok := s.Args["selector"]; ok {
+		t.Error("Expected selector not to be in build arguments")
+	}
+	if len(s.Variants) != 2 {
+		t.Fatalf("Expected 2 variants, but got %d", len(s.Variants))
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []string{
+		`{"selector": 1}`,
+		`{"variants": {}}`,
+		`{"variants": [1]}`,
+	}
+	for i, test := range tests {
+		if _, err := Load("Test.sublime-build", []byte(test)); err == nil {
+			t.Errorf("Test %d: Expected error loading %s", i, test)
+		}
+	}
+}
+
+func TestVariant(t *testing.T) {
+	s, err := Load("Make.sublime-build", []byte(testBuild))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		command string
+		args    map[string]interface{}
+	}{
+		{
+			"Clean",
+			"exec",
+			map[string]interface{}{"shell_cmd": "make clean", "file_regex": `^(.+):(\d+)`},
+		},
+		{
+			"Run",
+			"run_build",
+			map[string]interface{}{"shell_cmd": "make", "file_regex": `^(.+):(\d+)`},
+		},
+	}
+	for i, test := range tests {
+		v := s.Variant(test.name)
+		if v == nil {
+			t.Errorf("Test %d: Expected variant %s", i, test.name)
+			continue
+		}
+		if v.Command() != test.command {
+			t.Errorf("Test %d: Expected command %s, but got %s", i, test.command, v.Command())
+		}
+		if !reflect.DeepEqual(v.Args, test.args) {
+			t.Errorf("Test %d: Expected args %v, but got %v", i, test.args, v.Args)
+		}
+	}
+	if v := s.Variant("None"); v != nil {
+		t.Errorf("Expected no variant, but got %s", v)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	s := &System{Name: "Test"}
+	Add("Packages/Test/Test.sublime-build", s)
+	if Get("Packages/Test/Test.sublime-build") != s {
+		t.Error("Expected to get the added build system")
+	}
+	found := false
+	for _, k := range Keys() {
+		if k == "Packages/Test/Test.sublime-build" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected added build system in %v", Keys())
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package build
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/limetext/rubex"
+)
+
+// Result is a location in a file found in the build output.
+type Result struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	// line of the build output the result was found in, zero based
+	OutputLine int
+}
+
+// ParseResults finds the results in the build output. The groups of
+// fileRegex are file, line, column and message. lineRegex groups are line,
+// column and message, the file of a line match is the file of the last file
+// match. Relative file names are resolved against baseDir. The regexes are
+// compiled with rubex, the Oniguruma engine of syntax and find patterns.
+func ParseResults(output, fileRegex, lineRegex, baseDir string) ([]Result, error) {
+	var fre, lre *rubex.Regexp
+	var err error
+	if fileRegex != "" {
+		if fre, err = rubex.Compile(fileRegex); err != nil {
+			return nil, err
+		}
+	}
+	if lineRegex != "" {
+		if lre, err = rubex.Compile(lineRegex); err != nil {
+			return nil, err
+		}
+	}
+
+	var ret []Result
+	var file string
+	for i, line := range strings.Split(output, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if fre != nil {
+			if m := fre.FindStringSubmatch(line); m != nil {
+				file = group(m, 1)
+				if file != "" && !filepath.IsAbs(file) && baseDir != "" {
+					file = filepath.Join(baseDir, file)
+				}
+				ret = append(ret, newResult(file, group(m, 2), group(m, 3), group(m, 4), i))
+				continue
+			}
+		}
+		if lre != nil && file != "" {
+			if m := lre.FindStringSubmatch(line); m != nil {
+				ret = append(ret, newResult(file, group(m, 1), group(m, 2), group(m, 3), i))
+			}
+		}
+	}
+	return ret, nil
+}
+
+func group(m []string, i int) string {
+	if i < len(m) {
+		return m[i]
+	}
+	return ""
+}
+
+func newResult(file, line, col, msg string, outLine int) Result {
+	r := Result{File: file, Message: msg, OutputLine: outLine}
+	r.Line, _ = strconv.Atoi(line)
+	r.Column, _ = strconv.Atoi(col)
+	return r
+}
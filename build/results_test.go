@@ -0,0 +1,47 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package build
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseResults(t *testing.T) {
+	output := "main.go:10:5: undefined: x\n" +
+		"/abs/other.go:3: missing return\n" +
+		"in file lib.c\n" +
+		"  line 7 col 2: warning\n" +
+		"[Finished]"
+	base := filepath.FromSlash("/base")
+	rs, err := ParseResults(output, `^(\S+\.go):(\d+):(?:(\d+):)? (.*)$`, "", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Result{
+		{filepath.Join(base, "main.go"), 10, 5, "undefined: x", 0},
+		{"/abs/other.go", 3, 0, "missing return", 1},
+	}
+	if !reflect.DeepEqual(rs, exp) {
+		t.Errorf("Expected %v, but got %v", exp, rs)
+	}
+
+	rs, err = ParseResults(output, `^in file (\S+)$`, `^\s+line (\d+) col (\d+): (.*)$`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = []Result{
+		{"lib.c", 0, 0, "", 2},
+		{"lib.c", 7, 2, "warning", 3},
+	}
+	if !reflect.DeepEqual(rs, exp) {
+		t.Errorf("Expected %v, but got %v", exp, rs)
+	}
+
+	if _, err := ParseResults(output, "(", "", ""); err == nil {
+		t.Error("Expected error on invalid regex")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package build
+
+import (
+	"reflect"
+
+	"github.com/limetext/sublime/snippet"
+)
+
+// ExpandVariables replaces the variables in all the strings of v, v could be
+// a string or maps and slices containing strings. The supported forms are
+// $name, ${name}, ${name:default} and ${name/regex/format/flags}, which is
+// applied like the transforms of snippet variables. A backslash escapes the
+// dollar sign. Unknown variables expand to empty strings.
+func ExpandVariables(v interface{}, vars map[string]string) interface{} {
+	if s, ok := v.(string); ok {
+		return expand(s, vars)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		ret := reflect.MakeMap(rv.Type())
+		for _, k := range rv.MapKeys() {
+			ret.SetMapIndex(k, value(ExpandVariables(rv.MapIndex(k).Interface(), vars), rv.Type().Elem()))
+		}
+		return ret.Interface()
+	case reflect.Slice:
+		ret := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			ret.Index(i).Set(value(ExpandVariables(rv.Index(i).Interface(), vars), rv.Type().Elem()))
+		}
+		return ret.Interface()
+	default:
+		return v
+	}
+}
+
+// Returns the reflect value of v, nil values become the zero value of t.
+func value(v interface{}, t reflect.Type) reflect.Value {
+	if v == nil {
+		return reflect.Zero(t)
+	}
+	return reflect.ValueOf(v)
+}
+
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+func expand(s string, vars map[string]string) string {
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '$':
+			buf = append(buf, '$')
+			i++
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := closingBrace(s, i+2)
+			if end == -1 {
+				buf = append(buf, s[i:]...)
+				return string(buf)
+			}
+			buf = append(buf, expandBraces(s[i+2:end], vars)...)
+			i = end
+		case c == '$' && i+1 < len(s) && isNameByte(s[i+1], true):
+			j := i + 2
+			for j < len(s) && isNameByte(s[j], false) {
+				j++
+			}
+			buf = append(buf, vars[s[i+1:j]]...)
+			i = j - 1
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return string(buf)
+}
+
+// Returns the index of the brace closing the one opened right before i, -1
+// if there isn't any.
+func closingBrace(s string, i int) int {
+	depth := 1
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Expands the content of ${...}
+func expandBraces(s string, vars map[string]string) string {
+	i := 0
+	for i < len(s) && isNameByte(s[i], i == 0) {
+		i++
+	}
+	name, rest := s[:i], s[i:]
+	val, ok := vars[name]
+	switch {
+	case rest == "":
+		return val
+	case rest[0] == ':':
+		if ok && val != "" {
+			return val
+		}
+		return expand(rest[1:], vars)
+	case rest[0] == '/':
+		// invalid transforms leave the value as it is
+		ret, _ := snippet.Substitute(val, rest[1:])
+		return ret
+	default:
+		return ""
+	}
+}
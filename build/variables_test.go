@@ -0,0 +1,49 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package build
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandVariables(t *testing.T) {
+	vars := map[string]string{
+		"file":      "/home/user/main.go",
+		"file_name": "main.go",
+		"empty":     "",
+	}
+	tests := []struct {
+		in  interface{}
+		exp interface{}
+	}{
+		{"go run $file", "go run /home/user/main.go"},
+		{"${file_name}.bak", "main.go.bak"},
+		{"$file_name_x", ""},
+		{"${none:default}", "default"},
+		{"${empty:$file_name}", "main.go"},
+		{"${file_name/\\.go$/.o/}", "main.o"},
+		{"${file_name/[a-z]/X/}", "Xain.go"},
+		{"${file_name/[a-z]/X/g}", "XXXX.XX"},
+		{"${file_name/(\\w+)\\.go/${1}_test.go/}", "main_test.go"},
+		{"\\$file", "$file"},
+		{"cost $5", "cost $5"},
+		{"${unclosed", "${unclosed"},
+		{1, 1},
+		{
+			[]interface{}{"$file_name", 2},
+			[]interface{}{"main.go", 2},
+		},
+		{
+			map[string]interface{}{"cmd": []interface{}{"go", "run", "$file"}},
+			map[string]interface{}{"cmd": []interface{}{"go", "run", "/home/user/main.go"}},
+		},
+	}
+	for i, test := range tests {
+		if out := ExpandVariables(test.in, vars); !reflect.DeepEqual(out, test.exp) {
+			t.Errorf("Test %d: Expected %v, but got %v", i, test.exp, out)
+		}
+	}
+}
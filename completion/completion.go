@@ -0,0 +1,177 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package completion implements sublime completion files.
+// https://docs.sublimetext.io/reference/completions.html
+package completion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/limetext/loaders"
+)
+
+type (
+	// Completion is a completion entry, the trigger could have a hint
+	// after a tab character and the contents use the snippet syntax.
+	Completion struct {
+		Trigger  string
+		Contents string
+	}
+
+	// File is the content of a .sublime-completions file.
+	File struct {
+		Scope       string
+		Completions []Completion
+	}
+)
+
+// Word returns the part of the trigger which is matched against the typed
+// text.
+func (c Completion) Word() string {
+	if i := strings.Index(c.Trigger, "\t"); i != -1 {
+		return c.Trigger[:i]
+	}
+	return c.Trigger
+}
+
+// Hint returns the part of the trigger after the tab character.
+func (c Completion) Hint() string {
+	if i := strings.Index(c.Trigger, "\t"); i != -1 {
+		return c.Trigger[i+1:]
+	}
+	return ""
+}
+
+// Load parses a .sublime-completions file. Completions are either plain
+// strings, which are both the trigger and the contents, or objects with
+// trigger and contents keys.
+func Load(data []byte) (*File, error) {
+	var raw struct {
+		Scope       string        `json:"scope"`
+		Completions []interface{} `json:"completions"`
+	}
+	if err := loaders.LoadJSON(data, &raw); err != nil {
+		return nil, err
+	}
+	f := &File{Scope: raw.Scope}
+	for _, c := range raw.Completions {
+		comp, err := New(c)
+		if err != nil {
+			return nil, err
+		}
+		f.Completions = append(f.Completions, comp)
+	}
+	return f, nil
+}
+
+// New returns the completion of a completion entry which could be a string,
+// a trigger/contents pair or an object with trigger and contents keys.
+func New(v interface{}) (Completion, error) {
+	switch t := v.(type) {
+	case string:
+		return Completion{Trigger: t, Contents: t}, nil
+	case []interface{}:
+		if len(t) == 2 {
+			trigger, ok1 := t[0].(string)
+			contents, ok2 := t[1].(string)
+			if ok1 && ok2 {
+				return Completion{Trigger: trigger, Contents: contents}, nil
+			}
+		}
+	case map[string]interface{}:
+		trigger, ok := t["trigger"].(string)
+		if !ok {
+			return Completion{}, fmt.Errorf("Expected string trigger in completion %v", t)
+		}
+		contents, ok := t["contents"].(string)
+		if !ok {
+			contents = trigger
+		}
+		return Completion{Trigger: trigger, Contents: contents}, nil
+	}
+	return Completion{}, fmt.Errorf("Invalid completion %v", v)
+}
+
+var files = struct {
+	sync.Mutex
+	m map[string]*File
+}{m: make(map[string]*File)}
+
+// Add registers the completion file under the given key which is usually the
+// resource name of the file.
+func Add(key string, f *File) {
+	files.Lock()
+	defer files.Unlock()
+	files.m[key] = f
+}
+
+// Get returns the completion file registered with key.
+func Get(key string) *File {
+	files.Lock()
+	defer files.Unlock()
+	return files.m[key]
+}
+
+// Find returns the completions of the registered files starting with prefix
+// whose scope matches, completions of the best matching files first. score
+// returns the score of a scope selector, zero meaning no match. Files without
+// scope match everywhere.
+func Find(prefix string, score func(selector string) int) []Completion {
+	files.Lock()
+	keys := make([]string, 0, len(files.m))
+	for k := range files.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fs := make([]*File, len(keys))
+	for i, k := range keys {
+		fs[i] = files.m[k]
+	}
+	files.Unlock()
+
+	var matches byScore
+	for _, f := range fs {
+		sc := 1
+		if f.Scope != "" {
+			if sc = score(f.Scope); sc <= 0 {
+				continue
+			}
+		}
+		matches = append(matches, match{f, sc})
+	}
+	sort.Stable(matches)
+
+	ret := make([]Completion, 0)
+	for _, m := range matches {
+		for _, c := range m.f.Completions {
+			if strings.HasPrefix(c.Word(), prefix) {
+				ret = append(ret, c)
+			}
+		}
+	}
+	return ret
+}
+
+type match struct {
+	f     *File
+	score int
+}
+
+type byScore []match
+
+func (b byScore) Len() int {
+	return len(b)
+}
+
+func (b byScore) Less(i, j int) bool {
+	return b[i].score > b[j].score
+}
+
+func (b byScore) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "Go.sublime-completions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &File{
+		Scope: "source.go",
+		Completions: []Completion{
+			{"package", "package"},
+			{"func\tfunction", "func ${1:name}($2) {\n\t$0\n}"},
+			{"fallthrough", "fallthrough"},
+		},
+	}
+	if !reflect.DeepEqual(f, exp) {
+		t.Errorf("Expected %v, but got %v", exp, f)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		in  interface{}
+		exp Completion
+		err bool
+	}{
+		{"word", Completion{"word", "word"}, false},
+		{[]interface{}{"fn\tfunc", "fn($1)"}, Completion{"fn\tfunc", "fn($1)"}, false},
+		{map[string]interface{}{"trigger": "a", "contents": "b"}, Completion{"a", "b"}, false},
+		{map[string]interface{}{"contents": "b"}, Completion{}, true},
+		{[]interface{}{"a"}, Completion{}, true},
+		{1, Completion{}, true},
+	}
+	for i, test := range tests {
+		c, err := New(test.in)
+		if test.err {
+			if err == nil {
+				t.Errorf("Test %d: Expected error for %v", i, test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: Unexpected error: %s", i, err)
+		} else if c != test.exp {
+			t.Errorf("Test %d: Expected %v, but got %v", i, test.exp, c)
+		}
+	}
+}
+
+func TestWordHint(t *testing.T) {
+	c := Completion{Trigger: "fn\tfunction"}
+	if c.Word() != "fn" || c.Hint() != "function" {
+		t.Errorf("Expected fn and function, but got %s and %s", c.Word(), c.Hint())
+	}
+	c = Completion{Trigger: "fn"}
+	if c.Word() != "fn" || c.Hint() != "" {
+		t.Errorf("Expected fn and empty hint, but got %s and %s", c.Word(), c.Hint())
+	}
+}
+
+func TestFind(t *testing.T) {
+	Add("Packages/Test/Go.sublime-completions", &File{
+		Scope:       "source.go",
+		Completions: []Completion{{"func", "func"}, {"for", "for"}},
+	})
+	Add("Packages/Test/Any.sublime-completions", &File{
+		Completions: []Completion{{"foo\tany", "foo"}},
+	})
+	if f := Get("Packages/Test/Any.sublime-completions"); f == nil {
+		t.Error("Expected to get the added completion file")
+	}
+	score := func(sel string) int {
+		if sel == "source.go" {
+			return 2
+		}
+		return 0
+	}
+	exp := []Completion{{"func", "func"}, {"for", "for"}, {"foo\tany", "foo"}}
+	if cs := Find("f", score); !reflect.DeepEqual(cs, exp) {
+		t.Errorf("Expected %v, but got %v", exp, cs)
+	}
+	if cs := Find("fo", func(string) int { return 0 }); !reflect.DeepEqual(cs, []Completion{{"foo\tany", "foo"}}) {
+		t.Errorf("Expected only the completions without scope, but got %v", cs)
+	}
+}
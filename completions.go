@@ -0,0 +1,24 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package sublime
+
+import (
+	"path/filepath"
+
+	"github.com/limetext/sublime/completion"
+	"github.com/limetext/sublime/resource"
+)
+
+func newCompletions(path string) (*completion.File, error) {
+	data, err := resource.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return completion.Load(data)
+}
+
+func isCompletions(path string) bool {
+	return filepath.Ext(path) == ".sublime-completions"
+}
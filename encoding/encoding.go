@@ -0,0 +1,261 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package encoding detects and converts the character encodings and line
+// endings of files. The names are the ones shown by sublime text.
+package encoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encodings
+const (
+	Undefined   = "Undefined"
+	UTF8        = "UTF-8"
+	UTF8BOM     = "UTF-8 with BOM"
+	UTF16LE     = "UTF-16 LE"
+	UTF16LEBOM  = "UTF-16 LE with BOM"
+	UTF16BE     = "UTF-16 BE"
+	UTF16BEBOM  = "UTF-16 BE with BOM"
+	Latin1      = "Western (ISO 8859-1)"
+	Windows1252 = "Western (Windows 1252)"
+)
+
+// Line endings
+const (
+	Unix    = "Unix"
+	Windows = "Windows"
+	CR      = "CR"
+)
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+
+	// Windows 1252 differs from Latin-1 only in 0x80-0x9f, the zeros are
+	// undefined.
+	windows1252 = [32]rune{
+		0x20ac, 0, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021,
+		0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017d, 0,
+		0, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014,
+		0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0, 0x017e, 0x0178,
+	}
+
+	encodings = []string{UTF8, UTF8BOM, UTF16LE, UTF16LEBOM, UTF16BE, UTF16BEBOM, Latin1, Windows1252}
+)
+
+// Encodings returns the supported encodings.
+func Encodings() []string {
+	ret := make([]string, len(encodings))
+	copy(ret, encodings)
+	return ret
+}
+
+// Valid reports whether the encoding is supported.
+func Valid(enc string) bool {
+	for _, e := range encodings {
+		if e == enc {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect returns the most likely encoding of the data. Byte order marks
+// win, then valid UTF-8, then UTF-16 without a byte order mark which is
+// recognized by the zero bytes of ascii text. Anything else is Windows 1252
+// unless it uses the bytes undefined there, then it's Latin-1.
+func Detect(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return UTF8BOM
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return UTF16LEBOM
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return UTF16BEBOM
+	}
+	if enc := detectUTF16(data); enc != "" {
+		return enc
+	}
+	if utf8.Valid(data) {
+		return UTF8
+	}
+	for _, b := range data {
+		if b >= 0x80 && b < 0xa0 && windows1252[b-0x80] == 0 {
+			return Latin1
+		}
+	}
+	return Windows1252
+}
+
+// Text mostly in ascii has a zero in every other byte in UTF-16, which
+// never happens in the 8 bit encodings.
+func detectUTF16(data []byte) string {
+	if len(data) < 2 || len(data)%2 != 0 {
+		return ""
+	}
+	var even, odd int
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	half := len(data) / 2
+	switch {
+	case odd*2 > half && even == 0:
+		return UTF16LE
+	case even*2 > half && odd == 0:
+		return UTF16BE
+	}
+	return ""
+}
+
+// Decode converts the data in the encoding to a string, the byte order mark
+// is dropped.
+func Decode(data []byte, enc string) (string, error) {
+	switch enc {
+	case UTF8, UTF8BOM, Undefined:
+		data = bytes.TrimPrefix(data, bomUTF8)
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("Invalid %s data", enc)
+		}
+		return string(data), nil
+	case UTF16LE, UTF16LEBOM:
+		return decodeUTF16(bytes.TrimPrefix(data, bomUTF16LE), binary.LittleEndian)
+	case UTF16BE, UTF16BEBOM:
+		return decodeUTF16(bytes.TrimPrefix(data, bomUTF16BE), binary.BigEndian)
+	case Latin1:
+		rs := make([]rune, len(data))
+		for i, b := range data {
+			rs[i] = rune(b)
+		}
+		return string(rs), nil
+	case Windows1252:
+		rs := make([]rune, len(data))
+		for i, b := range data {
+			rs[i] = rune(b)
+			if b >= 0x80 && b < 0xa0 && windows1252[b-0x80] != 0 {
+				rs[i] = windows1252[b-0x80]
+			}
+		}
+		return string(rs), nil
+	}
+	return "", fmt.Errorf("Unknown encoding %s", enc)
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) (string, error) {
+	if len(data)%2 != 0 {
+		return "", fmt.Errorf("Odd length of UTF-16 data")
+	}
+	u := make([]uint16, len(data)/2)
+	for i := range u {
+		u[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(u)), nil
+}
+
+// Encode converts the string to the encoding, adding the byte order mark of
+// the encodings with one. Characters that can't be represented in the
+// encoding are an error.
+func Encode(s string, enc string) ([]byte, error) {
+	switch enc {
+	case UTF8, Undefined:
+		return []byte(s), nil
+	case UTF8BOM:
+		return append(append([]byte{}, bomUTF8...), s...), nil
+	case UTF16LE, UTF16LEBOM, UTF16BE, UTF16BEBOM:
+		var b bytes.Buffer
+		var order binary.ByteOrder = binary.LittleEndian
+		bom := bomUTF16LE
+		if enc == UTF16BE || enc == UTF16BEBOM {
+			order, bom = binary.BigEndian, bomUTF16BE
+		}
+		if strings.HasSuffix(enc, "BOM") {
+			b.Write(bom)
+		}
+		for _, u := range utf16.Encode([]rune(s)) {
+			binary.Write(&b, order, u)
+		}
+		return b.Bytes(), nil
+	case Latin1, Windows1252:
+		ret := make([]byte, 0, len(s))
+		for _, r := range s {
+			c, ok := encodeByte(r, enc == Windows1252)
+			if !ok {
+				return nil, fmt.Errorf("Unable to encode %q in %s", r, enc)
+			}
+			ret = append(ret, c)
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("Unknown encoding %s", enc)
+}
+
+func encodeByte(r rune, cp1252 bool) (byte, bool) {
+	if cp1252 {
+		for i, c := range windows1252 {
+			if c == r && c != 0 {
+				return byte(0x80 + i), true
+			}
+		}
+		if r >= 0x80 && r < 0xa0 && windows1252[r-0x80] != 0 {
+			return 0, false
+		}
+	}
+	if r > 0xff {
+		return 0, false
+	}
+	return byte(r), true
+}
+
+// DetectLineEndings returns the line endings of the first line break of
+// the text, the empty string if there is none.
+func DetectLineEndings(s string) string {
+	i := strings.IndexAny(s, "\r\n")
+	switch {
+	case i == -1:
+		return ""
+	case s[i] == '\n':
+		return Unix
+	case strings.HasPrefix(s[i:], "\r\n"):
+		return Windows
+	}
+	return CR
+}
+
+// NormalizeLineEndings converts all the line endings of the text to "\n".
+func NormalizeLineEndings(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	return strings.Replace(strings.Replace(s, "\r\n", "\n", -1), "\r", "\n", -1)
+}
+
+// ApplyLineEndings converts the "\n" line endings of the text to le.
+func ApplyLineEndings(s, le string) string {
+	switch le {
+	case Windows:
+		return strings.Replace(s, "\n", "\r\n", -1)
+	case CR:
+		return strings.Replace(s, "\n", "\r", -1)
+	}
+	return s
+}
+
+// ValidLineEndings reports whether le is one of Unix, Windows or CR.
+func ValidLineEndings(le string) bool {
+	return le == Unix || le == Windows || le == CR
+}
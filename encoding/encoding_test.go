@@ -0,0 +1,114 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		in  []byte
+		exp string
+	}{
+		{[]byte(""), UTF8},
+		{[]byte("plain ascii"), UTF8},
+		{[]byte("h\xc3\xa9llo"), UTF8},
+		{[]byte("\xef\xbb\xbfhi"), UTF8BOM},
+		{[]byte("\xff\xfeh\x00i\x00"), UTF16LEBOM},
+		{[]byte("\xfe\xff\x00h\x00i"), UTF16BEBOM},
+		{[]byte("h\x00i\x00!\x00"), UTF16LE},
+		{[]byte("\x00h\x00i\x00!"), UTF16BE},
+		{[]byte("caf\xe9"), Windows1252},
+		{[]byte("\x93quoted\x94"), Windows1252},
+		{[]byte("caf\xe9 \x81"), Latin1},
+	}
+	for i, test := range tests {
+		if got := Detect(test.in); got != test.exp {
+			t.Errorf("Test %d: Expected %s for %q, but got %s", i, test.exp, test.in, got)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		enc  string
+		text string
+		data []byte
+	}{
+		{UTF8, "héllo", []byte("h\xc3\xa9llo")},
+		{UTF8BOM, "hi", []byte("\xef\xbb\xbfhi")},
+		{UTF16LE, "hé", []byte("h\x00\xe9\x00")},
+		{UTF16LEBOM, "hi", []byte("\xff\xfeh\x00i\x00")},
+		{UTF16BE, "h€", []byte("\x00h\x20\xac")},
+		{UTF16BEBOM, "hi", []byte("\xfe\xff\x00h\x00i")},
+		{UTF16LE, "\U0001F600", []byte("\x3d\xd8\x00\xde")},
+		{Latin1, "café\u0080", []byte("caf\xe9\x80")},
+		{Windows1252, "“café” €", []byte("\x93caf\xe9\x94 \x80")},
+	}
+	for i, test := range tests {
+		if got, err := Decode(test.data, test.enc); err != nil {
+			t.Errorf("Test %d: Error decoding: %s", i, err)
+		} else if got != test.text {
+			t.Errorf("Test %d: Expected %q decoding, but got %q", i, test.text, got)
+		}
+		if got, err := Encode(test.text, test.enc); err != nil {
+			t.Errorf("Test %d: Error encoding: %s", i, err)
+		} else if !bytes.Equal(got, test.data) {
+			t.Errorf("Test %d: Expected %q encoding, but got %q", i, test.data, got)
+		}
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	tests := []struct {
+		enc, text string
+	}{
+		{Latin1, "€"},
+		{Windows1252, "\u0080"},
+		{Windows1252, "ā"},
+		{"EBCDIC", "a"},
+	}
+	for i, test := range tests {
+		if _, err := Encode(test.text, test.enc); err == nil {
+			t.Errorf("Test %d: Expected an error encoding %q in %s", i, test.text, test.enc)
+		}
+	}
+	if _, err := Decode([]byte("\xff"), UTF8); err == nil {
+		t.Error("Expected an error decoding invalid UTF-8")
+	}
+	if _, err := Decode([]byte("a"), UTF16LE); err == nil {
+		t.Error("Expected an error decoding odd UTF-16")
+	}
+}
+
+func TestLineEndings(t *testing.T) {
+	tests := []struct {
+		in, le, norm string
+	}{
+		{"a", "", "a"},
+		{"a\nb\r\n", Unix, "a\nb\n"},
+		{"a\r\nb\n", Windows, "a\nb\n"},
+		{"a\rb\r", CR, "a\nb\n"},
+	}
+	for i, test := range tests {
+		if got := DetectLineEndings(test.in); got != test.le {
+			t.Errorf("Test %d: Expected line endings %q, but got %q", i, test.le, got)
+		}
+		if got := NormalizeLineEndings(test.in); got != test.norm {
+			t.Errorf("Test %d: Expected %q, but got %q", i, test.norm, got)
+		}
+	}
+	if got := ApplyLineEndings("a\nb\n", Windows); got != "a\r\nb\r\n" {
+		t.Errorf("Unexpected Windows line endings %q", got)
+	}
+	if got := ApplyLineEndings("a\nb\n", CR); got != "a\rb\r" {
+		t.Errorf("Unexpected CR line endings %q", got)
+	}
+	if got := ApplyLineEndings("a\nb\n", Unix); got != "a\nb\n" {
+		t.Errorf("Unexpected Unix line endings %q", got)
+	}
+}
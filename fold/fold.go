@@ -0,0 +1,162 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package fold implements code folding, the folded regions of a view and the
+// ranges which could be folded by syntax or by indentation.
+package fold
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/limetext/text"
+)
+
+// Folds are the folded regions of a view, sorted and not overlapping. The
+// regions are kept in place by Inserted and Erased as the text changes.
+type Folds struct {
+	lock    sync.Mutex
+	regions []text.Region
+}
+
+// Returns r with A <= B
+func normalize(r text.Region) text.Region {
+	return text.Region{A: r.Begin(), B: r.End()}
+}
+
+// Fold folds r, folds overlapping r are merged with it. Empty regions and
+// regions which are folded already aren't folded and false is returned.
+func (f *Folds) Fold(r text.Region) bool {
+	r = normalize(r)
+	if r.A == r.B || f.IsFolded(r) {
+		return false
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var ret []text.Region
+	for _, o := range f.regions {
+		if o.B < r.A || o.A > r.B {
+			ret = append(ret, o)
+			continue
+		}
+		if o.A < r.A {
+			r.A = o.A
+		}
+		if o.B > r.B {
+			r.B = o.B
+		}
+	}
+	ret = append(ret, r)
+	sort.Sort(byBegin(ret))
+	f.regions = ret
+	return true
+}
+
+// Unfold unfolds the folds intersecting r, or containing r if it's empty,
+// and returns them.
+func (f *Folds) Unfold(r text.Region) []text.Region {
+	r = normalize(r)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var keep, ret []text.Region
+	for _, o := range f.regions {
+		if (r.A == r.B && o.A <= r.A && r.A <= o.B) || (o.A < r.B && r.A < o.B) {
+			ret = append(ret, o)
+		} else {
+			keep = append(keep, o)
+		}
+	}
+	f.regions = keep
+	return ret
+}
+
+// UnfoldAll unfolds all the folds and returns them.
+func (f *Folds) UnfoldAll() []text.Region {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	ret := f.regions
+	f.regions = nil
+	return ret
+}
+
+// IsFolded reports whether r is inside a fold.
+func (f *Folds) IsFolded(r text.Region) bool {
+	r = normalize(r)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, o := range f.regions {
+		if o.A <= r.A && r.B <= o.B {
+			return true
+		}
+	}
+	return false
+}
+
+// Regions returns the folded regions in order.
+func (f *Folds) Regions() []text.Region {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]text.Region(nil), f.regions...)
+}
+
+// Inserted moves the folds after an insertion of n characters at p, folds
+// containing p grow.
+func (f *Folds) Inserted(p, n int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i := range f.regions {
+		r := &f.regions[i]
+		switch {
+		case r.A < p && p < r.B:
+			r.B += n
+		case r.A >= p:
+			r.A += n
+			r.B += n
+		}
+	}
+}
+
+// Erased moves the folds after r is erased, folds which are erased entirely
+// are removed.
+func (f *Folds) Erased(r text.Region) {
+	r = normalize(r)
+	n := r.B - r.A
+	move := func(p int) int {
+		switch {
+		case p >= r.B:
+			return p - n
+		case p > r.A:
+			return r.A
+		}
+		return p
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var ret []text.Region
+	for _, o := range f.regions {
+		o = text.Region{A: move(o.A), B: move(o.B)}
+		if o.A != o.B {
+			ret = append(ret, o)
+		}
+	}
+	f.regions = ret
+}
+
+type byBegin []text.Region
+
+func (b byBegin) Len() int {
+	return len(b)
+}
+
+func (b byBegin) Less(i, j int) bool {
+	if b[i].A != b[j].A {
+		return b[i].A < b[j].A
+	}
+	// outer ranges first
+	return b[i].B > b[j].B
+}
+
+func (b byBegin) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package fold
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/text"
+)
+
+func TestFold(t *testing.T) {
+	var f Folds
+	if f.Fold(text.Region{A: 5, B: 5}) {
+		t.Error("Expected empty region not to fold")
+	}
+	if !f.Fold(text.Region{A: 20, B: 10}) || !f.Fold(text.Region{A: 30, B: 40}) {
+		t.Fatal("Expected regions to fold")
+	}
+	if f.Fold(text.Region{A: 12, B: 15}) {
+		t.Error("Expected folded region not to fold again")
+	}
+	exp := []text.Region{{A: 10, B: 20}, {A: 30, B: 40}}
+	if got := f.Regions(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+
+	// overlapping folds are merged
+	f.Fold(text.Region{A: 18, B: 32})
+	exp = []text.Region{{A: 10, B: 40}}
+	if got := f.Regions(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+	if !f.IsFolded(text.Region{A: 15, B: 35}) || f.IsFolded(text.Region{A: 5, B: 15}) {
+		t.Error("Unexpected IsFolded result")
+	}
+}
+
+func TestUnfold(t *testing.T) {
+	var f Folds
+	f.Fold(text.Region{A: 0, B: 5})
+	f.Fold(text.Region{A: 10, B: 20})
+	f.Fold(text.Region{A: 30, B: 40})
+
+	exp := []text.Region{{A: 10, B: 20}}
+	if got := f.Unfold(text.Region{A: 15, B: 15}); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+	if got := f.Unfold(text.Region{A: 20, B: 30}); got != nil {
+		t.Errorf("Expected nothing to unfold, but got %v", got)
+	}
+	exp = []text.Region{{A: 0, B: 5}, {A: 30, B: 40}}
+	if got := f.UnfoldAll(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+	if got := f.Regions(); len(got) != 0 {
+		t.Errorf("Expected no folds, but got %v", got)
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	var f Folds
+	f.Fold(text.Region{A: 10, B: 20})
+	f.Fold(text.Region{A: 30, B: 40})
+	f.Fold(text.Region{A: 50, B: 55})
+
+	// at the start of a fold it moves, inside it grows
+	f.Inserted(10, 2)
+	f.Inserted(15, 3)
+	f.Inserted(25, 1)
+	exp := []text.Region{{A: 12, B: 25}, {A: 36, B: 46}, {A: 56, B: 61}}
+	if got := f.Regions(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+
+	f.Erased(text.Region{A: 20, B: 40})
+	f.Erased(text.Region{A: 30, B: 45})
+	exp = []text.Region{{A: 12, B: 20}, {A: 20, B: 26}}
+	if got := f.Regions(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+}
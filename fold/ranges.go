@@ -0,0 +1,180 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package fold
+
+import (
+	"sort"
+
+	"github.com/limetext/text"
+	"github.com/quarnster/parser"
+)
+
+// a line of the text, end is before the new line
+type line struct {
+	begin, end int
+}
+
+func lines(data []rune) []line {
+	var ret []line
+	begin := 0
+	for i, r := range data {
+		if r == '\n' {
+			ret = append(ret, line{begin, i})
+			begin = i + 1
+		}
+	}
+	return append(ret, line{begin, len(data)})
+}
+
+// Returns the index of the line containing pt
+func lineAt(ls []line, pt int) int {
+	return sort.Search(len(ls), func(i int) bool { return ls[i].end >= pt })
+}
+
+// Returns the indentation width of the line in columns and whether the line
+// is blank.
+func indent(data []rune, l line, tabSize int) (width int, blank bool) {
+	for i := l.begin; i < l.end; i++ {
+		switch data[i] {
+		case ' ':
+			width++
+		case '\t':
+			width += tabSize - width%tabSize
+		default:
+			return width, false
+		}
+	}
+	return width, true
+}
+
+// Level returns the indentation level of the line, tabs advance to the next
+// tab stop.
+func Level(line string, tabSize int) int {
+	if tabSize <= 0 {
+		tabSize = 4
+	}
+	data := []rune(line)
+	w, _ := indent(data, lines(data)[0], tabSize)
+	return w / tabSize
+}
+
+// IndentedRegion returns the full lines around pt which are indented at
+// least as much as the line of pt. Blank lines inside the block belong to it
+// while the ones at its end don't. Lines without indentation have no indented
+// region and an empty region at pt is returned.
+func IndentedRegion(data string, pt, tabSize int) text.Region {
+	if tabSize <= 0 {
+		tabSize = 4
+	}
+	rs := []rune(data)
+	ls := lines(rs)
+	cur := lineAt(ls, pt)
+	if cur >= len(ls) {
+		return text.Region{A: pt, B: pt}
+	}
+	w, blank := indent(rs, ls[cur], tabSize)
+	if blank || w < tabSize {
+		return text.Region{A: pt, B: pt}
+	}
+	in := func(i int) (bool, bool) {
+		iw, blank := indent(rs, ls[i], tabSize)
+		return blank || iw >= w, blank
+	}
+	first, last := cur, cur
+	for i := cur - 1; i >= 0; i-- {
+		ok, blank := in(i)
+		if !ok {
+			break
+		}
+		if !blank {
+			first = i
+		}
+	}
+	for i := cur + 1; i < len(ls); i++ {
+		ok, blank := in(i)
+		if !ok {
+			break
+		}
+		if !blank {
+			last = i
+		}
+	}
+	end := ls[last].end
+	if end < len(rs) {
+		// including the new line
+		end++
+	}
+	return text.Region{A: ls[first].begin, B: end}
+}
+
+// IndentRanges returns the ranges which could be folded by indentation.
+// Every line followed by more indented lines starts a range, which covers
+// from the end of the line to the end of the last more indented line.
+func IndentRanges(data string, tabSize int) []text.Region {
+	if tabSize <= 0 {
+		tabSize = 4
+	}
+	rs := []rune(data)
+	ls := lines(rs)
+	var ret []text.Region
+	for i := range ls {
+		w, blank := indent(rs, ls[i], tabSize)
+		if blank {
+			continue
+		}
+		last := -1
+		for j := i + 1; j < len(ls); j++ {
+			jw, blank := indent(rs, ls[j], tabSize)
+			if blank {
+				continue
+			}
+			if jw <= w {
+				break
+			}
+			last = j
+		}
+		if last != -1 {
+			ret = append(ret, text.Region{A: ls[i].end, B: ls[last].end})
+		}
+	}
+	return ret
+}
+
+// SyntaxRanges returns the ranges which could be folded by the scope tree.
+// Scopes spanning multiple lines come from begin and end patterns, they are
+// folded from the end of their first line to the start of the end pattern on
+// their last line.
+func SyntaxRanges(root *parser.Node) []text.Region {
+	if root == nil || root.P == nil {
+		return nil
+	}
+	rs := []rune(root.P.Data(0, root.Range.End()))
+	ls := lines(rs)
+	seen := make(map[text.Region]bool)
+	var ret []text.Region
+	var walk func(n *parser.Node)
+	walk = func(n *parser.Node) {
+		first, last := lineAt(ls, n.Range.Begin()), lineAt(ls, n.Range.End())
+		if n.Name != "" && first < last && last < len(ls) {
+			r := text.Region{A: ls[first].end, B: ls[last].begin}
+			// skipping the white space before the end pattern
+			for r.B < n.Range.End() && (rs[r.B] == ' ' || rs[r.B] == '\t') {
+				r.B++
+			}
+			if r.A < r.B && !seen[r] {
+				seen[r] = true
+				ret = append(ret, r)
+			}
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	for _, c := range root.Children {
+		walk(c)
+	}
+	sort.Sort(byBegin(ret))
+	return ret
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package fold
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/text"
+	"github.com/quarnster/parser"
+)
+
+const src = "def a():\n    if b:\n\t\tc\n\n    d\ne\n"
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		line string
+		exp  int
+	}{
+		{"a", 0},
+		{"    a", 1},
+		{"\ta", 1},
+		{"  \ta", 1},
+		{"      a", 1},
+		{"\t    a", 2},
+	}
+	for i, test := range tests {
+		if got := Level(test.line, 4); got != test.exp {
+			t.Errorf("Test %d: Expected level %d, but got %d", i, test.exp, got)
+		}
+	}
+}
+
+func TestIndentedRegion(t *testing.T) {
+	tests := []struct {
+		pt  int
+		exp text.Region
+	}{
+		{2, text.Region{A: 2, B: 2}},
+		{12, text.Region{A: 9, B: 30}},
+		{21, text.Region{A: 19, B: 23}},
+		{25, text.Region{A: 9, B: 30}},
+		{30, text.Region{A: 30, B: 30}},
+	}
+	for i, test := range tests {
+		if got := IndentedRegion(src, test.pt, 4); got != test.exp {
+			t.Errorf("Test %d: Expected %v, but got %v", i, test.exp, got)
+		}
+	}
+}
+
+func TestIndentRanges(t *testing.T) {
+	exp := []text.Region{{A: 8, B: 29}, {A: 18, B: 22}}
+	if got := IndentRanges(src, 4); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+}
+
+type data string
+
+func (d data) Data(a, b int) string {
+	return string(d)[a:b]
+}
+
+func TestSyntaxRanges(t *testing.T) {
+	d := data("f() {\n\tg {\n\t}\n\t/* x */\n}\n")
+	root := &parser.Node{Name: "source", P: d, Range: text.Region{A: 0, B: len(d)}, Children: []*parser.Node{
+		{Name: "meta.block", P: d, Range: text.Region{A: 4, B: 23}, Children: []*parser.Node{
+			{Name: "meta.block", P: d, Range: text.Region{A: 9, B: 13}},
+			{Name: "comment.block", P: d, Range: text.Region{A: 15, B: 22}},
+		}},
+	}}
+	exp := []text.Region{{A: 5, B: 23}, {A: 10, B: 12}}
+	if got := SyntaxRanges(root); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+}
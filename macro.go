@@ -0,0 +1,24 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package sublime
+
+import (
+	"path/filepath"
+
+	"github.com/limetext/sublime/macro"
+	"github.com/limetext/sublime/resource"
+)
+
+func newMacro(path string) ([]macro.Action, error) {
+	data, err := resource.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return macro.Load(data)
+}
+
+func isMacro(path string) bool {
+	return filepath.Ext(path) == ".sublime-macro"
+}
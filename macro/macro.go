@@ -0,0 +1,109 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package macro implements sublime macros, the lists of commands in
+// .sublime-macro files and the ones recorded while the user runs commands.
+// https://docs.sublimetext.io/reference/macros.html
+package macro
+
+import (
+	"sync"
+
+	"github.com/limetext/loaders"
+)
+
+// Action is a command of a macro with its arguments.
+type Action struct {
+	Command string                 `json:"command"`
+	Args    map[string]interface{} `json:"args"`
+}
+
+// Load parses a .sublime-macro file.
+func Load(data []byte) ([]Action, error) {
+	var actions []Action
+	if err := loaders.LoadJSON(data, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+var macros = struct {
+	sync.Mutex
+	m map[string][]Action
+}{m: make(map[string][]Action)}
+
+// Add registers the macro under the given key which is usually the resource
+// name of its file.
+func Add(key string, m []Action) {
+	macros.Lock()
+	defer macros.Unlock()
+	macros.m[key] = m
+}
+
+// Get returns the macro registered with key.
+func Get(key string) []Action {
+	macros.Lock()
+	defer macros.Unlock()
+	return macros.m[key]
+}
+
+var recorder struct {
+	sync.Mutex
+	recording bool
+	actions   []Action
+	last      []Action
+}
+
+// Start starts recording a new macro.
+func Start() {
+	recorder.Lock()
+	defer recorder.Unlock()
+	recorder.recording = true
+	recorder.actions = nil
+}
+
+// Stop stops the recording, the recorded macro becomes the one returned by
+// Last.
+func Stop() {
+	recorder.Lock()
+	defer recorder.Unlock()
+	if !recorder.recording {
+		return
+	}
+	recorder.recording = false
+	recorder.last = recorder.actions
+	recorder.actions = nil
+}
+
+// Recording reports whether a macro is being recorded.
+func Recording() bool {
+	recorder.Lock()
+	defer recorder.Unlock()
+	return recorder.recording
+}
+
+// Record adds the command to the macro being recorded, it does nothing when
+// there is no recording.
+func Record(cmd string, args map[string]interface{}) {
+	recorder.Lock()
+	defer recorder.Unlock()
+	if !recorder.recording {
+		return
+	}
+	a := Action{Command: cmd}
+	if len(args) != 0 {
+		a.Args = make(map[string]interface{}, len(args))
+		for k, v := range args {
+			a.Args[k] = v
+		}
+	}
+	recorder.actions = append(recorder.actions, a)
+}
+
+// Last returns the last recorded macro.
+func Last() []Action {
+	recorder.Lock()
+	defer recorder.Unlock()
+	return recorder.last
+}
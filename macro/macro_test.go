@@ -0,0 +1,86 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package macro
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "Delete Line.sublime-macro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Action{
+		{Command: "expand_selection", Args: map[string]interface{}{"to": "line"}},
+		{Command: "add_to_kill_ring", Args: map[string]interface{}{"forward": true}},
+		{Command: "left_delete"},
+	}
+	if !reflect.DeepEqual(m, exp) {
+		t.Errorf("Expected %v, but got %v", exp, m)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	Record("ignored", nil)
+	if Recording() || Last() != nil {
+		t.Fatal("Expected no recording")
+	}
+
+	Start()
+	if !Recording() {
+		t.Fatal("Expected recording")
+	}
+	args := map[string]interface{}{"characters": "a"}
+	Record("insert", args)
+	Record("move", map[string]interface{}{"by": "lines", "forward": true})
+	// changes of the args after recording don't change the macro
+	args["characters"] = "b"
+	if Last() != nil {
+		t.Errorf("Expected no macro until the recording stops, but got %v", Last())
+	}
+	Stop()
+	if Recording() {
+		t.Error("Expected the recording to stop")
+	}
+	Record("ignored", nil)
+
+	exp := []Action{
+		{Command: "insert", Args: map[string]interface{}{"characters": "a"}},
+		{Command: "move", Args: map[string]interface{}{"by": "lines", "forward": true}},
+	}
+	if !reflect.DeepEqual(Last(), exp) {
+		t.Errorf("Expected %v, but got %v", exp, Last())
+	}
+
+	// starting again keeps the last macro until the new one stops
+	Start()
+	Record("left_delete", nil)
+	if !reflect.DeepEqual(Last(), exp) {
+		t.Errorf("Expected %v, but got %v", exp, Last())
+	}
+	Stop()
+	if exp := []Action{{Command: "left_delete"}}; !reflect.DeepEqual(Last(), exp) {
+		t.Errorf("Expected %v, but got %v", exp, Last())
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	m := []Action{{Command: "left_delete"}}
+	Add("Packages/Test/Test.sublime-macro", m)
+	if got := Get("Packages/Test/Test.sublime-macro"); !reflect.DeepEqual(got, m) {
+		t.Errorf("Expected %v, but got %v", m, got)
+	}
+	if got := Get("Packages/Test/Missing.sublime-macro"); got != nil {
+		t.Errorf("Expected no macro, but got %v", got)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package sublime
+
+import (
+	"path/filepath"
+
+	"github.com/limetext/sublime/menu"
+	"github.com/limetext/sublime/resource"
+)
+
+func newMenu(path string) ([]*menu.Item, error) {
+	data, err := resource.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return menu.Load(data)
+}
+
+func newCommands(path string) ([]menu.Command, error) {
+	data, err := resource.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return menu.LoadCommands(data)
+}
+
+func isMenu(path string) bool {
+	return filepath.Ext(path) == ".sublime-menu"
+}
+
+func isCommands(path string) bool {
+	return filepath.Ext(path) == ".sublime-commands"
+}
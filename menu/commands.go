@@ -0,0 +1,71 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package menu
+
+import (
+	"sync"
+
+	"github.com/limetext/loaders"
+)
+
+// Command is an entry of the command palette.
+type Command struct {
+	Caption string                 `json:"caption"`
+	Command string                 `json:"command"`
+	Args    map[string]interface{} `json:"args"`
+}
+
+// LoadCommands parses a .sublime-commands file.
+func LoadCommands(data []byte) ([]Command, error) {
+	var cmds []Command
+	if err := loaders.LoadJSON(data, &cmds); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+var commands = struct {
+	sync.Mutex
+	// package name to palette entries
+	m map[string][]Command
+}{m: make(map[string][]Command)}
+
+// AddCommands adds palette entries of the package.
+func AddCommands(pkg string, cmds []Command) {
+	commands.Lock()
+	defer commands.Unlock()
+	commands.m[pkg] = append(commands.m[pkg], cmds...)
+}
+
+// RemoveCommands removes the palette entries of the package.
+func RemoveCommands(pkg string) {
+	commands.Lock()
+	defer commands.Unlock()
+	delete(commands.m, pkg)
+}
+
+// PackageCommands returns the palette entries of the package.
+func PackageCommands(pkg string) []Command {
+	commands.Lock()
+	defer commands.Unlock()
+	return append([]Command(nil), commands.m[pkg]...)
+}
+
+// Commands returns the palette entries of all the packages in package load
+// order.
+func Commands() []Command {
+	commands.Lock()
+	defer commands.Unlock()
+	pkgs := make([]string, 0, len(commands.m))
+	for pkg := range commands.m {
+		pkgs = append(pkgs, pkg)
+	}
+	sortPackages(pkgs)
+	ret := make([]Command, 0)
+	for _, pkg := range pkgs {
+		ret = append(ret, commands.m[pkg]...)
+	}
+	return ret
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package menu
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadCommands(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "Default.sublime-commands"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := LoadCommands(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []Command{
+		{"Word Wrap: Toggle", "toggle_setting", map[string]interface{}{"setting": "word_wrap"}},
+		{"File: New", "new_file", nil},
+	}
+	if !reflect.DeepEqual(cmds, exp) {
+		t.Errorf("Expected %v, but got %v", exp, cmds)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	AddCommands("User", []Command{{Caption: "User"}})
+	AddCommands("Beta", []Command{{Caption: "Beta"}})
+	AddCommands("Default", []Command{{Caption: "Default"}})
+	AddCommands("Beta", []Command{{Caption: "Beta 2"}})
+
+	var captions []string
+	for _, c := range Commands() {
+		captions = append(captions, c.Caption)
+	}
+	if exp := []string{"Default", "Beta", "Beta 2", "User"}; !reflect.DeepEqual(captions, exp) {
+		t.Errorf("Expected %v, but got %v", exp, captions)
+	}
+	if cmds := PackageCommands("Beta"); len(cmds) != 2 {
+		t.Errorf("Expected 2 Beta commands, but got %v", cmds)
+	}
+	RemoveCommands("Beta")
+	if cmds := PackageCommands("Beta"); len(cmds) != 0 {
+		t.Errorf("Expected no Beta commands, but got %v", cmds)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package menu implements sublime menus and command palette entries.
+// https://docs.sublimetext.io/reference/menus.html
+package menu
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/limetext/loaders"
+	"github.com/limetext/sublime/resource"
+)
+
+// Item is an entry of a menu, items without command and children having "-"
+// as caption are separators. Items with the same id in different packages are
+// merged.
+type Item struct {
+	Id       string                 `json:"id"`
+	Caption  string                 `json:"caption"`
+	Command  string                 `json:"command"`
+	Args     map[string]interface{} `json:"args"`
+	Mnemonic string                 `json:"mnemonic"`
+	Checkbox bool                   `json:"checkbox"`
+	Children []*Item                `json:"children"`
+}
+
+// Load parses a .sublime-menu file.
+func Load(data []byte) ([]*Item, error) {
+	var items []*Item
+	if err := loaders.LoadJSON(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// IsSeparator reports whether the item is a separator.
+func (i *Item) IsSeparator() bool {
+	return i.Caption == "-" && i.Command == "" && len(i.Children) == 0
+}
+
+func (i *Item) copy() *Item {
+	ret := *i
+	ret.Children = Merge(nil, i.Children)
+	return &ret
+}
+
+// Merge returns the items of dst with the items of src added. Items of src
+// having the id of an item in dst override its fields and their children are
+// merged with the children of the dst item, the rest are appended. The given
+// items are left intact.
+func Merge(dst, src []*Item) []*Item {
+	var ret []*Item
+	for _, it := range dst {
+		ret = append(ret, it.copy())
+	}
+	for _, it := range src {
+		var old *Item
+		if it.Id != "" {
+			for _, o := range ret {
+				if o.Id == it.Id {
+					old = o
+					break
+				}
+			}
+		}
+		if old == nil {
+			ret = append(ret, it.copy())
+			continue
+		}
+		if it.Caption != "" {
+			old.Caption = it.Caption
+		}
+		if it.Command != "" {
+			old.Command = it.Command
+			old.Args = it.Args
+		}
+		if it.Mnemonic != "" {
+			old.Mnemonic = it.Mnemonic
+		}
+		if it.Checkbox {
+			old.Checkbox = true
+		}
+		old.Children = Merge(old.Children, it.Children)
+	}
+	return ret
+}
+
+// Name returns the name of the menu defined in the file, like Main for
+// Main.sublime-menu.
+func Name(path string) string {
+	base := path[strings.LastIndexAny(path, `/\`)+1:]
+	return strings.TrimSuffix(base, ".sublime-menu")
+}
+
+var menus = struct {
+	sync.Mutex
+	// menu name to package name to items
+	m map[string]map[string][]*Item
+}{m: make(map[string]map[string][]*Item)}
+
+// Add adds the items to the named menu of the package, multiple files of a
+// package defining the same menu are merged.
+func Add(name, pkg string, items []*Item) {
+	menus.Lock()
+	defer menus.Unlock()
+	if menus.m[name] == nil {
+		menus.m[name] = make(map[string][]*Item)
+	}
+	menus.m[name][pkg] = Merge(menus.m[name][pkg], items)
+}
+
+// Remove removes the menus of the package.
+func Remove(pkg string) {
+	menus.Lock()
+	defer menus.Unlock()
+	for _, m := range menus.m {
+		delete(m, pkg)
+	}
+}
+
+// ForPackage returns the items the package adds to the named menu.
+func ForPackage(name, pkg string) []*Item {
+	menus.Lock()
+	defer menus.Unlock()
+	return Merge(nil, menus.m[name][pkg])
+}
+
+// Get returns the named menu with the items of all the packages merged in
+// package load order.
+func Get(name string) []*Item {
+	menus.Lock()
+	defer menus.Unlock()
+	pkgs := make([]string, 0, len(menus.m[name]))
+	for pkg := range menus.m[name] {
+		pkgs = append(pkgs, pkg)
+	}
+	sortPackages(pkgs)
+	var ret []*Item
+	for _, pkg := range pkgs {
+		ret = Merge(ret, menus.m[name][pkg])
+	}
+	return ret
+}
+
+// Names returns the sorted names of the menus.
+func Names() []string {
+	menus.Lock()
+	defer menus.Unlock()
+	ret := make([]string, 0, len(menus.m))
+	for name := range menus.m {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Sorts the package names in load order, Default first and User last.
+func sortPackages(pkgs []string) {
+	names := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		names[i] = resource.Name(pkg, "")
+	}
+	resource.Sort(names)
+	for i, name := range names {
+		pkgs[i] = strings.TrimSuffix(strings.TrimPrefix(name, resource.Prefix), "/")
+	}
+}
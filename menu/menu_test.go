@@ -0,0 +1,125 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package menu
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "Main.sublime-menu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*Item{
+		{
+			Id:       "file",
+			Caption:  "File",
+			Mnemonic: "F",
+			Children: []*Item{
+				{Caption: "New File", Command: "new_file", Mnemonic: "N"},
+				{Caption: "-"},
+				{
+					Caption:  "Word Wrap",
+					Command:  "toggle_setting",
+					Args:     map[string]interface{}{"setting": "word_wrap"},
+					Checkbox: true,
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(items, exp) {
+		t.Errorf("Expected %v, but got %v", exp, items)
+	}
+	if !items[0].Children[1].IsSeparator() || items[0].Children[0].IsSeparator() {
+		t.Error("Expected only the second child to be a separator")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dst := []*Item{
+		{Id: "file", Caption: "File", Children: []*Item{{Caption: "New", Command: "new_file"}}},
+		{Caption: "-"},
+	}
+	src := []*Item{
+		{Id: "file", Mnemonic: "F", Children: []*Item{{Caption: "Open", Command: "open_file"}}},
+		{Id: "help", Caption: "Help"},
+	}
+	exp := []*Item{
+		{
+			Id:       "file",
+			Caption:  "File",
+			Mnemonic: "F",
+			Children: []*Item{{Caption: "New", Command: "new_file"}, {Caption: "Open", Command: "open_file"}},
+		},
+		{Caption: "-"},
+		{Id: "help", Caption: "Help"},
+	}
+	if got := Merge(dst, src); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+	if len(dst[0].Children) != 1 || dst[0].Mnemonic != "" {
+		t.Errorf("Expected merge to leave the items intact, but got %v", dst[0])
+	}
+}
+
+func TestGet(t *testing.T) {
+	Add("Test", "Zed", []*Item{{Id: "edit", Caption: "Edit", Children: []*Item{{Caption: "Zed"}}}})
+	Add("Test", "User", []*Item{{Id: "edit", Children: []*Item{{Caption: "User"}}}})
+	Add("Test", "Default", []*Item{{Id: "edit", Caption: "Edit", Children: []*Item{{Caption: "Default"}}}})
+	Add("Test", "Alpha", []*Item{{Id: "edit", Children: []*Item{{Caption: "Alpha"}}}})
+
+	items := Get("Test")
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 merged item, but got %v", items)
+	}
+	var captions []string
+	for _, c := range items[0].Children {
+		captions = append(captions, c.Caption)
+	}
+	if exp := []string{"Default", "Alpha", "Zed", "User"}; !reflect.DeepEqual(captions, exp) {
+		t.Errorf("Expected children %v, but got %v", exp, captions)
+	}
+	if items := ForPackage("Test", "Alpha"); len(items) != 1 || items[0].Children[0].Caption != "Alpha" {
+		t.Errorf("Expected the Alpha package items, but got %v", items)
+	}
+
+	found := false
+	for _, name := range Names() {
+		if name == "Test" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected Test in menu names %v", Names())
+	}
+
+	Remove("Zed")
+	if items := ForPackage("Test", "Zed"); len(items) != 0 {
+		t.Errorf("Expected no items after removing the package, but got %v", items)
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		in, exp string
+	}{
+		{"Main.sublime-menu", "Main"},
+		{"Packages/Default/Context.sublime-menu", "Context"},
+		{filepath.Join("a", "Side Bar.sublime-menu"), "Side Bar"},
+	}
+	for i, test := range tests {
+		if got := Name(test.in); got != test.exp {
+			t.Errorf("Test %d: Expected %s, but got %s", i, test.exp, got)
+		}
+	}
+}
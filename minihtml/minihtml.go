@@ -0,0 +1,359 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package minihtml parses the html subset shown in popups and phantoms.
+// https://www.sublimetext.com/docs/3/minihtml.html
+package minihtml
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Node is an element of the document, text nodes have an empty tag.
+type Node struct {
+	Tag      string
+	Attrs    map[string]string
+	Text     string
+	Children []*Node
+	Parent   *Node
+}
+
+// Tags that never have children.
+var void = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+}
+
+// Supported tags, others are kept in the tree but frontends render only
+// their children.
+var tags = map[string]bool{
+	"html": true, "head": true, "body": true, "style": true,
+	"div": true, "p": true, "span": true, "a": true,
+	"b": true, "strong": true, "i": true, "em": true, "u": true,
+	"big": true, "small": true, "code": true, "tt": true, "var": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true,
+	"br": true, "hr": true, "img": true,
+}
+
+// Tags whose content is kept as raw text.
+var raw = map[string]bool{
+	"style": true,
+}
+
+// Tags starting a new line when rendered as plain text.
+var block = map[string]bool{
+	"div": true, "p": true, "ul": true, "ol": true, "li": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+var entities = map[string]string{
+	"amp":  "&",
+	"lt":   "<",
+	"gt":   ">",
+	"quot": `"`,
+	"apos": "'",
+	"nbsp": " ",
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+// Parse returns the root of the document, the root itself has no tag.
+// Unclosed elements are closed at the end of the document and closing tags
+// without a matching element are an error.
+func Parse(src string) (*Node, error) {
+	p := &parser{src: src}
+	root := &Node{}
+	cur := root
+	for !p.eof() {
+		if strings.HasPrefix(p.src[p.pos:], "<!--") {
+			end := strings.Index(p.src[p.pos:], "-->")
+			if end == -1 {
+				return nil, p.errorf("unclosed comment")
+			}
+			p.pos += end + 3
+			continue
+		}
+		if p.src[p.pos] != '<' {
+			end := strings.IndexByte(p.src[p.pos:], '<')
+			if end == -1 {
+				end = len(p.src) - p.pos
+			}
+			cur.add(&Node{Text: Unescape(p.src[p.pos : p.pos+end])})
+			p.pos += end
+			continue
+		}
+		if strings.HasPrefix(p.src[p.pos:], "</") {
+			p.pos += 2
+			tag := strings.ToLower(p.ident())
+			p.skip()
+			if !p.consume('>') {
+				return nil, p.errorf("expected '>'")
+			}
+			n := cur
+			for ; n != root && n.Tag != tag; n = n.Parent {
+			}
+			if n == root {
+				return nil, p.errorf("unexpected </%s>", tag)
+			}
+			cur = n.Parent
+			continue
+		}
+		p.pos++
+		n, closed, err := p.element()
+		if err != nil {
+			return nil, err
+		}
+		cur.add(n)
+		if closed || void[n.Tag] {
+			continue
+		}
+		if raw[n.Tag] {
+			end := strings.Index(strings.ToLower(p.src[p.pos:]), "</"+n.Tag)
+			if end == -1 {
+				end = len(p.src) - p.pos
+			}
+			n.add(&Node{Text: p.src[p.pos : p.pos+end]})
+			p.pos += end
+		}
+		cur = n
+	}
+	return root, nil
+}
+
+// Parses the tag and attributes after '<', closed reports a self closing
+// element.
+func (p *parser) element() (n *Node, closed bool, err error) {
+	n = &Node{Tag: strings.ToLower(p.ident()), Attrs: make(map[string]string)}
+	if n.Tag == "" {
+		return nil, false, p.errorf("expected tag name")
+	}
+	for {
+		p.skip()
+		if p.eof() {
+			return nil, false, p.errorf("unclosed <%s>", n.Tag)
+		}
+		if p.consume('>') {
+			return n, false, nil
+		}
+		if strings.HasPrefix(p.src[p.pos:], "/>") {
+			p.pos += 2
+			return n, true, nil
+		}
+		name := strings.ToLower(p.ident())
+		if name == "" {
+			return nil, false, p.errorf("unexpected %q in <%s>", p.src[p.pos], n.Tag)
+		}
+		p.skip()
+		if !p.consume('=') {
+			n.Attrs[name] = ""
+			continue
+		}
+		p.skip()
+		val, err := p.value()
+		if err != nil {
+			return nil, false, err
+		}
+		n.Attrs[name] = Unescape(val)
+	}
+}
+
+func (p *parser) value() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected attribute value")
+	}
+	if q := p.src[p.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(p.src[p.pos+1:], q)
+		if end == -1 {
+			return "", p.errorf("unclosed attribute value")
+		}
+		v := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return v, nil
+	}
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n>", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *parser) ident() string {
+	start := p.pos
+	for !p.eof() {
+		c := p.src[p.pos]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_') {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) skip() {
+	for !p.eof() && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(c byte) bool {
+	if !p.eof() && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("minihtml at %d: %s", p.pos, fmt.Sprintf(format, a...))
+}
+
+func (n *Node) add(c *Node) {
+	c.Parent = n
+	n.Children = append(n.Children, c)
+}
+
+// Supported reports whether the tag of the element is part of minihtml, text
+// nodes are always supported.
+func (n *Node) Supported() bool {
+	return n.Tag == "" || tags[n.Tag]
+}
+
+// Walk calls f for the node and its descendants in document order, the
+// children of a node are skipped when f returns false.
+func (n *Node) Walk(f func(*Node) bool) {
+	if !f(n) {
+		return
+	}
+	for _, c := range n.Children {
+		c.Walk(f)
+	}
+}
+
+// Links returns the href of every anchor in document order.
+func (n *Node) Links() []string {
+	var ret []string
+	n.Walk(func(c *Node) bool {
+		if href, ok := c.Attrs["href"]; ok && c.Tag == "a" {
+			ret = append(ret, href)
+		}
+		return true
+	})
+	return ret
+}
+
+// PlainText renders the document as text for frontends without html
+// support, block elements and br start new lines and style is dropped.
+func (n *Node) PlainText() string {
+	var b bytes.Buffer
+	var text func(*Node)
+	text = func(n *Node) {
+		switch {
+		case n.Tag == "" && n.Parent != nil && raw[n.Parent.Tag]:
+			return
+		case n.Tag == "":
+			// html collapses white space
+			words := strings.Fields(n.Text)
+			if n.Text != "" && isSpace(n.Text[0]) {
+				space(&b)
+			}
+			b.WriteString(strings.Join(words, " "))
+			if len(words) > 0 && isSpace(n.Text[len(n.Text)-1]) {
+				space(&b)
+			}
+		case n.Tag == "br":
+			b.WriteByte('\n')
+		}
+		if block[n.Tag] {
+			newline(&b)
+		}
+		for _, c := range n.Children {
+			text(c)
+		}
+		if block[n.Tag] {
+			newline(&b)
+		}
+	}
+	text(n)
+	lines := strings.Split(b.String(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Writes a space unless after another one or at the beginning of a line.
+func space(b *bytes.Buffer) {
+	if s := b.String(); s != "" && !isSpace(s[len(s)-1]) {
+		b.WriteByte(' ')
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// Starts a new line unless at the beginning of one.
+func newline(b *bytes.Buffer) {
+	if s := b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		b.WriteByte('\n')
+	}
+}
+
+// Unescape replaces the named and numeric character references of s,
+// unknown references are kept as is.
+func Unescape(s string) string {
+	if !strings.ContainsRune(s, '&') {
+		return s
+	}
+	var b bytes.Buffer
+	for {
+		i := strings.IndexByte(s, '&')
+		if i == -1 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		end := strings.IndexByte(s, ';')
+		if end == -1 {
+			b.WriteString(s)
+			return b.String()
+		}
+		if r, ok := reference(s[1:end]); ok {
+			b.WriteString(r)
+		} else {
+			b.WriteString(s[:end+1])
+		}
+		s = s[end+1:]
+	}
+}
+
+func reference(name string) (string, bool) {
+	if !strings.HasPrefix(name, "#") {
+		r, ok := entities[name]
+		return r, ok
+	}
+	base, num := 10, name[1:]
+	if strings.HasPrefix(num, "x") || strings.HasPrefix(num, "X") {
+		base, num = 16, num[1:]
+	}
+	c, err := strconv.ParseUint(num, base, 32)
+	if err != nil || !utf8.ValidRune(rune(c)) {
+		return "", false
+	}
+	return string(rune(c)), true
+}
@@ -0,0 +1,118 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package minihtml
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Returns the tags of the tree in document order, text nodes are quoted.
+func flatten(n *Node) []string {
+	var ret []string
+	n.Walk(func(c *Node) bool {
+		if c.Tag == "" && c.Parent != nil {
+			ret = append(ret, `"`+c.Text+`"`)
+		} else if c.Tag != "" {
+			ret = append(ret, c.Tag)
+		}
+		return true
+	})
+	return ret
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp []string
+	}{
+		{"plain", []string{`"plain"`}},
+		{"<b>bold</b> text", []string{"b", `"bold"`, `" text"`}},
+		{"<body id=x><p>a<br>b</p></body>", []string{"body", "p", `"a"`, "br", `"b"`}},
+		{"<div><img src='a.png'/>x</div>", []string{"div", "img", `"x"`}},
+		{"<DIV>a</div>", []string{"div", `"a"`}},
+		{"<style>a > b { color: red; }</style>x", []string{"style", `"a > b { color: red; }"`, `"x"`}},
+		{"a<!-- <b>not</b> -->b", []string{`"a"`, `"b"`}},
+		{"<i>a<b>b</i>c", []string{"i", `"a"`, "b", `"b"`, `"c"`}},
+		{"<p>&lt;a&gt; &amp; &#65;&#x42; &bogus;</p>", []string{"p", `"<a> & AB &bogus;"`}},
+		{"<span>unclosed", []string{"span", `"unclosed"`}},
+	}
+	for i, test := range tests {
+		n, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("Test %d: Error parsing %q: %s", i, test.in, err)
+			continue
+		}
+		if got := flatten(n); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("Test %d: Expected %v, but got %v", i, test.exp, got)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []string{
+		"a</b>",
+		"<b>a</i>",
+		"<b",
+		"<a href='x>a</a>",
+		"<!-- unclosed",
+		"< b>",
+	}
+	for i, test := range tests {
+		if _, err := Parse(test); err == nil {
+			t.Errorf("Test %d: Expected an error parsing %q", i, test)
+		}
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	n, err := Parse(`<a href="subl:open?a=1&amp;b=2" class=link disabled>x</a>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]string{"href": "subl:open?a=1&b=2", "class": "link", "disabled": ""}
+	if a := n.Children[0]; !reflect.DeepEqual(a.Attrs, exp) {
+		t.Errorf("Expected attributes %v, but got %v", exp, a.Attrs)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	n, err := Parse(`<a href="one">1</a><div><a name="x">no</a><a href="two">2</a></div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := []string{"one", "two"}, n.Links(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected links %v, but got %v", exp, got)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		in, exp string
+	}{
+		{"a  <b>b</b>\n c", "a b c"},
+		{"<style>p {}</style><h1>Title</h1><p>some <i>text</i></p>end", "Title\nsome text\nend"},
+		{"a<br>b<ul><li>1</li><li>2</li></ul>", "a\nb\n1\n2"},
+	}
+	for i, test := range tests {
+		n, err := Parse(test.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := n.PlainText(); got != test.exp {
+			t.Errorf("Test %d: Expected %q, but got %q", i, test.exp, got)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	n, err := Parse("<b>a</b><blink>b</blink>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !n.Children[0].Supported() || n.Children[1].Supported() {
+		t.Error("Expected b to be supported and blink not")
+	}
+}
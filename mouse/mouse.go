@@ -0,0 +1,153 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package mouse implements sublime mouse bindings, the bindings are loaded
+// from .sublime-mousemap files into a hierarchy like the key bindings.
+// https://docs.sublimetext.io/reference/mouse_bindings.html
+package mouse
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// Binding binds a click of a mouse button with the modifiers held to
+	// commands. PressCommand runs when the button is pressed and Command when
+	// it's released.
+	Binding struct {
+		Button       string                 `json:"button"`
+		Count        int                    `json:"count"`
+		Modifiers    []string               `json:"modifiers"`
+		PressCommand string                 `json:"press_command"`
+		PressArgs    map[string]interface{} `json:"press_args"`
+		Command      string                 `json:"command"`
+		Args         map[string]interface{} `json:"args"`
+	}
+
+	// Event is a click reported by the frontend, x and y are the
+	// coordinates of the click in the view.
+	Event struct {
+		Button    string
+		Modifiers []string
+		Count     int
+		X, Y      float64
+	}
+
+	MouseBindingsInterface interface {
+		MouseBindings() *MouseBindings
+	}
+
+	// MouseBindings is a list of bindings with a parent, bindings which
+	// don't match an event are looked up in the parent.
+	MouseBindings struct {
+		lock     sync.Mutex
+		bindings []*Binding
+		parent   MouseBindingsInterface
+	}
+
+	HasMouseBindings struct {
+		mousebindings MouseBindings
+	}
+)
+
+var editor HasMouseBindings
+
+// Editor returns the root of the mouse bindings hierarchy, packages add
+// their bindings under it.
+func Editor() *MouseBindings {
+	return editor.MouseBindings()
+}
+
+func (h *HasMouseBindings) MouseBindings() *MouseBindings {
+	return &h.mousebindings
+}
+
+// UnmarshalJSON replaces the bindings with the ones of a .sublime-mousemap
+// file.
+func (m *MouseBindings) UnmarshalJSON(d []byte) error {
+	var bindings []*Binding
+	if err := json.Unmarshal(d, &bindings); err != nil {
+		return err
+	}
+	for _, b := range bindings {
+		b.Modifiers = normalize(b.Modifiers)
+		if b.Count == 0 {
+			b.Count = 1
+		}
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.bindings = bindings
+	return nil
+}
+
+func (m *MouseBindings) SetParent(p MouseBindingsInterface) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.parent = p
+}
+
+func (m *MouseBindings) Parent() MouseBindingsInterface {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.parent
+}
+
+func (m *MouseBindings) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.bindings)
+}
+
+// Find returns the binding of the event, nil when there isn't any. Later
+// bindings of a file override the earlier ones and bindings override the
+// ones of the parent.
+func (m *MouseBindings) Find(e Event) *Binding {
+	mods := normalize(e.Modifiers)
+	count := e.Count
+	if count == 0 {
+		count = 1
+	}
+	for cur := m; cur != nil; {
+		cur.lock.Lock()
+		bindings, parent := cur.bindings, cur.parent
+		cur.lock.Unlock()
+		for i := len(bindings) - 1; i >= 0; i-- {
+			if b := bindings[i]; b.matches(e.Button, mods, count) {
+				return b
+			}
+		}
+		if parent == nil {
+			return nil
+		}
+		cur = parent.MouseBindings()
+	}
+	return nil
+}
+
+func (b *Binding) matches(button string, mods []string, count int) bool {
+	if b.Button != button || b.Count != count || len(b.Modifiers) != len(mods) {
+		return false
+	}
+	for i := range mods {
+		if b.Modifiers[i] != mods[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the sorted lower case modifiers so that their order doesn't
+// matter
+func normalize(mods []string) []string {
+	ret := make([]string, len(mods))
+	for i, m := range mods {
+		ret[i] = strings.ToLower(m)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,106 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package mouse
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func load(t *testing.T, m *MouseBindings, data string) {
+	if err := json.Unmarshal([]byte(data), m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "Default.sublime-mousemap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m MouseBindings
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Len() != 4 {
+		t.Fatalf("Expected 4 bindings, but got %d", m.Len())
+	}
+	exp := &Binding{
+		Button:       "button1",
+		Count:        1,
+		Modifiers:    []string{"alt", "ctrl"},
+		PressCommand: "drag_select",
+		PressArgs:    map[string]interface{}{"additive": true},
+	}
+	if b := m.bindings[2]; !reflect.DeepEqual(b, exp) {
+		t.Errorf("Expected %+v, but got %+v", exp, b)
+	}
+}
+
+func TestFind(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "Default.sublime-mousemap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m MouseBindings
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		e     Event
+		press string
+		cmd   string
+		args  map[string]interface{}
+	}{
+		{Event{Button: "button1"}, "drag_select", "", nil},
+		{Event{Button: "button1", Count: 2}, "drag_select", "", map[string]interface{}{"by": "words"}},
+		{Event{Button: "button1", Modifiers: []string{"Alt", "ctrl"}}, "drag_select", "", map[string]interface{}{"additive": true}},
+		{Event{Button: "button2"}, "", "context_menu", nil},
+		{Event{Button: "button1", Count: 3}, "", "", nil},
+		{Event{Button: "button3"}, "", "", nil},
+	}
+	for i, test := range tests {
+		b := m.Find(test.e)
+		if test.press == "" && test.cmd == "" {
+			if b != nil {
+				t.Errorf("Test %d: Expected no binding, but got %+v", i, b)
+			}
+			continue
+		}
+		if b == nil {
+			t.Errorf("Test %d: Expected a binding", i)
+			continue
+		}
+		if b.PressCommand != test.press || b.Command != test.cmd || !reflect.DeepEqual(b.PressArgs, test.args) {
+			t.Errorf("Test %d: Unexpected binding %+v", i, b)
+		}
+	}
+}
+
+func TestFindParent(t *testing.T) {
+	var parent, child HasMouseBindings
+	load(t, parent.MouseBindings(), `[
+		{"button": "button1", "press_command": "drag_select"},
+		{"button": "button2", "command": "context_menu"}
+	]`)
+	load(t, child.MouseBindings(), `[
+		{"button": "button1", "press_command": "old"},
+		{"button": "button1", "press_command": "new"}
+	]`)
+	child.MouseBindings().SetParent(&parent)
+
+	if b := child.MouseBindings().Find(Event{Button: "button1"}); b == nil || b.PressCommand != "new" {
+		t.Errorf("Expected the last binding of the child, but got %+v", b)
+	}
+	if b := child.MouseBindings().Find(Event{Button: "button2"}); b == nil || b.Command != "context_menu" {
+		t.Errorf("Expected the binding of the parent, but got %+v", b)
+	}
+	if b := parent.MouseBindings().Find(Event{Button: "button1"}); b == nil || b.PressCommand != "drag_select" {
+		t.Errorf("Expected the binding of the parent, but got %+v", b)
+	}
+}
@@ -8,12 +8,21 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/limetext/backend"
 	"github.com/limetext/backend/keys"
 	"github.com/limetext/backend/log"
 	"github.com/limetext/backend/packages"
-	_ "github.com/limetext/sublime/api"
+	"github.com/limetext/sublime/api"
+	"github.com/limetext/sublime/build"
+	"github.com/limetext/sublime/completion"
+	"github.com/limetext/sublime/macro"
+	"github.com/limetext/sublime/menu"
+	"github.com/limetext/sublime/mouse"
+	"github.com/limetext/sublime/resource"
+	"github.com/limetext/sublime/snippet"
+	"github.com/limetext/sublime/textmate/preferences"
 	"github.com/limetext/text"
 )
 
@@ -22,26 +31,42 @@ import (
 type pkg struct {
 	dir  string
 	name string
+	// archive packages are .sublime-package zip files
+	archive bool
 	text.HasSettings
 	keys.HasKeyBindings
+	mouse.HasMouseBindings
 	platformSettings *text.HasSettings
 	defaultSettings  *text.HasSettings
 	defaultKB        *keys.HasKeyBindings
+	defaultMB        *mouse.HasMouseBindings
 	plugins          map[string]*plugin
 	syntaxes         map[string]*syntax
 	colorSchemes     map[string]*colorScheme
+	buildSystems     map[string]*build.System
+	snippets         map[string]*snippet.Snippet
+	completions      map[string]*completion.File
+	macros           map[string][]macro.Action
+	preferences      map[string]*preferences.Preferences
 }
 
 func newPKG(dir string) packages.Package {
 	p := &pkg{
 		dir:              dir,
 		name:             pkgName(dir),
+		archive:          isArchive(dir),
 		platformSettings: new(text.HasSettings),
 		defaultSettings:  new(text.HasSettings),
 		defaultKB:        new(keys.HasKeyBindings),
+		defaultMB:        new(mouse.HasMouseBindings),
 		plugins:          make(map[string]*plugin),
 		syntaxes:         make(map[string]*syntax),
 		colorSchemes:     make(map[string]*colorScheme),
+		buildSystems:     make(map[string]*build.System),
+		snippets:         make(map[string]*snippet.Snippet),
+		completions:      make(map[string]*completion.File),
+		macros:           make(map[string][]macro.Action),
+		preferences:      make(map[string]*preferences.Preferences),
 	}
 
 	ed := backend.GetEditor()
@@ -62,6 +87,16 @@ func newPKG(dir string) packages.Package {
 		p.defaultKB.KeyBindings().SetParent(tmp)
 	}
 
+	// Initializing mousebindings hierarchy
+	// default <- platform(package) <- editor
+	edMB := mouse.Editor()
+	mtmp := edMB.Parent()
+	edMB.SetParent(p)
+	p.MouseBindings().SetParent(p.defaultMB)
+	if mtmp != nil {
+		p.defaultMB.MouseBindings().SetParent(mtmp)
+	}
+
 	backend.OnUserPathAdd.Add(p.loadUserSettings)
 
 	return p
@@ -69,7 +104,12 @@ func newPKG(dir string) packages.Package {
 
 func (p *pkg) Load() {
 	log.Debug("Loading package %s", p.Name())
+	if p.archive {
+		p.loadArchive()
+		return
+	}
 	p.loadKeyBindings()
+	p.loadMouseBindings()
 	p.loadSettings()
 	p.loadUserSettings(backend.GetEditor().UserPath())
 	// When we failed on importing sublime_plugin module we continue
@@ -82,6 +122,22 @@ func (p *pkg) Load() {
 	filepath.Walk(p.Path(), p.scan)
 }
 
+// TODO: we only load resources, syntaxes, colour schemes, preferences, build
+// systems, snippets, completions, macros, menus and commands from archive
+// packages, plugins, key bindings, mouse bindings and settings should be
+// loaded too
+func (p *pkg) loadArchive() {
+	log.Fine("Loading %s archive", p.Name())
+	names, err := resource.AddArchive(p.Name(), p.Path())
+	if err != nil {
+		log.Warn("Error on reading archive %s, %s", p.Path(), err)
+		return
+	}
+	for _, name := range names {
+		p.load(name)
+	}
+}
+
 func (p *pkg) UnLoad() {}
 
 func (p *pkg) Path() string {
@@ -126,6 +182,10 @@ func (p *pkg) loadColorScheme(path string) {
 
 	p.colorSchemes[path] = cs
 	backend.GetEditor().AddColorScheme(path, cs)
+	// settings refer to color schemes by their resource names
+	if name := p.resourceName(path); name != path {
+		backend.GetEditor().AddColorScheme(name, cs)
+	}
 }
 
 func (p *pkg) loadSyntax(path string) {
@@ -138,6 +198,104 @@ func (p *pkg) loadSyntax(path string) {
 
 	p.syntaxes[path] = syn
 	backend.GetEditor().AddSyntax(path, syn)
+	api.AddSyntax(path, syn)
+	if name := p.resourceName(path); name != path {
+		backend.GetEditor().AddSyntax(name, syn)
+	}
+}
+
+func (p *pkg) loadPreferences(path string) {
+	log.Fine("Loading %s package preferences %s", p.Name(), path)
+	pref, err := preferences.Load(path)
+	if err != nil {
+		log.Warn("Error loading %s preferences %s: %s", p.Name(), path, err)
+		return
+	}
+
+	p.preferences[path] = pref
+	preferences.Add(p.resourceName(path), pref)
+}
+
+func (p *pkg) loadBuildSystem(path string) {
+	log.Fine("Loading %s package build system %s", p.Name(), path)
+	bs, err := newBuildSystem(path)
+	if err != nil {
+		log.Warn("Error loading %s build system: %s", p.Name(), err)
+		return
+	}
+
+	p.buildSystems[path] = bs
+	// build_system setting refers to build systems by their resource names
+	build.Add(p.resourceName(path), bs)
+}
+
+func (p *pkg) loadSnippet(path string) {
+	log.Fine("Loading %s package snippet %s", p.Name(), path)
+	s, err := newSnippet(path)
+	if err != nil {
+		log.Warn("Error loading %s snippet %s: %s", p.Name(), path, err)
+		return
+	}
+
+	p.snippets[path] = s
+	snippet.Add(p.resourceName(path), s)
+}
+
+func (p *pkg) loadCompletions(path string) {
+	log.Fine("Loading %s package completions %s", p.Name(), path)
+	f, err := newCompletions(path)
+	if err != nil {
+		log.Warn("Error loading %s completions %s: %s", p.Name(), path, err)
+		return
+	}
+
+	p.completions[path] = f
+	completion.Add(p.resourceName(path), f)
+}
+
+func (p *pkg) loadMacro(path string) {
+	log.Fine("Loading %s package macro %s", p.Name(), path)
+	m, err := newMacro(path)
+	if err != nil {
+		log.Warn("Error loading %s macro %s: %s", p.Name(), path, err)
+		return
+	}
+
+	p.macros[path] = m
+	// run_macro_file refers to macros by their resource names
+	macro.Add(p.resourceName(path), m)
+}
+
+func (p *pkg) loadMenu(path string) {
+	log.Fine("Loading %s package menu %s", p.Name(), path)
+	items, err := newMenu(path)
+	if err != nil {
+		log.Warn("Error loading %s menu %s: %s", p.Name(), path, err)
+		return
+	}
+	menu.Add(menu.Name(path), p.Name(), items)
+}
+
+func (p *pkg) loadCommands(path string) {
+	log.Fine("Loading %s package commands %s", p.Name(), path)
+	cmds, err := newCommands(path)
+	if err != nil {
+		log.Warn("Error loading %s commands %s: %s", p.Name(), path, err)
+		return
+	}
+	menu.AddCommands(p.Name(), cmds)
+}
+
+// Returns the resource name of the file at path in the package
+func (p *pkg) resourceName(path string) string {
+	if resource.IsName(path) {
+		return path
+	}
+	rel, err := filepath.Rel(p.Path(), path)
+	if err != nil {
+		return path
+	}
+	return resource.Name(p.Name(), rel)
 }
 
 func (p *pkg) loadKeyBindings() {
@@ -153,6 +311,19 @@ func (p *pkg) loadKeyBindings() {
 	packages.LoadJSON(pt, p.KeyBindings())
 }
 
+func (p *pkg) loadMouseBindings() {
+	log.Fine("Loading %s mousebindings", p.Name())
+	ed := backend.GetEditor()
+
+	pt := filepath.Join(p.Path(), "Default.sublime-mousemap")
+	log.Finest("Loading %s", pt)
+	packages.LoadJSON(pt, p.defaultMB.MouseBindings())
+
+	pt = filepath.Join(p.Path(), "Default ("+ed.Plat()+").sublime-mousemap")
+	log.Finest("Loading %s", pt)
+	packages.LoadJSON(pt, p.MouseBindings())
+}
+
 func (p *pkg) loadSettings() {
 	log.Fine("Loading %s settings", p.Name())
 	ed := backend.GetEditor()
@@ -177,23 +348,58 @@ func (p *pkg) scan(path string, info os.FileInfo, err error) error {
 	if info.IsDir() {
 		return nil
 	}
+	if rel, err := filepath.Rel(p.Path(), path); err == nil {
+		resource.AddFile(p.Name(), rel, path)
+	}
+	p.load(path)
+	return nil
+}
+
+// Loads files that could be anywhere in the package
+func (p *pkg) load(path string) {
 	if isColorScheme(path) {
 		p.loadColorScheme(path)
 	}
 	if isSyntax(path) {
 		p.loadSyntax(path)
 	}
-	return nil
+	if isPreferences(path) {
+		p.loadPreferences(path)
+	}
+	if isBuildSystem(path) {
+		p.loadBuildSystem(path)
+	}
+	if isSnippet(path) {
+		p.loadSnippet(path)
+	}
+	if isCompletions(path) {
+		p.loadCompletions(path)
+	}
+	if isMacro(path) {
+		p.loadMacro(path)
+	}
+	if isMenu(path) {
+		p.loadMenu(path)
+	}
+	if isCommands(path) {
+		p.loadCommands(path)
+	}
 }
 
 func pkgName(dir string) string {
-	return filepath.Base(dir)
+	return strings.TrimSuffix(filepath.Base(dir), archiveExt)
+}
+
+const archiveExt = ".sublime-package"
+
+func isArchive(path string) bool {
+	return filepath.Ext(path) == archiveExt
 }
 
-// Any directory in sublime is a package
+// Any directory or .sublime-package archive in sublime is a package
 func isPKG(dir string) bool {
 	fi, err := os.Stat(dir)
-	if err != nil || !fi.IsDir() {
+	if err != nil || (!fi.IsDir() && !isArchive(dir)) {
 		return false
 	}
 
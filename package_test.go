@@ -12,6 +12,13 @@ import (
 	"github.com/limetext/backend/packages"
 	_ "github.com/limetext/commands"
 	_ "github.com/limetext/sublime/api"
+	"github.com/limetext/sublime/build"
+	"github.com/limetext/sublime/completion"
+	"github.com/limetext/sublime/macro"
+	"github.com/limetext/sublime/menu"
+	"github.com/limetext/sublime/resource"
+	"github.com/limetext/sublime/snippet"
+	"github.com/limetext/sublime/textmate/preferences"
 )
 
 var (
@@ -19,6 +26,13 @@ var (
 	pluginPath = filepath.Join("testdata", "package", "plugin.py")
 	synPath    = filepath.Join(pkgPath, "Go.tmLanguage")
 	csPath     = filepath.Join(pkgPath, "Twilight.tmTheme")
+	prefPath   = filepath.Join(pkgPath, "Symbol List.tmPreferences")
+	bsPath     = filepath.Join(pkgPath, "Go.sublime-build")
+	snipPath   = filepath.Join(pkgPath, "func.sublime-snippet")
+	compPath   = filepath.Join(pkgPath, "Go.sublime-completions")
+	macroPath  = filepath.Join(pkgPath, "Delete Line.sublime-macro")
+	menuPath   = filepath.Join(pkgPath, "Main.sublime-menu")
+	cmdsPath   = filepath.Join(pkgPath, "Default.sublime-commands")
 )
 
 func TestLoadPlugin(t *testing.T) {
@@ -45,6 +59,57 @@ func TestLoadSyntax(t *testing.T) {
 	checkSyntax(pkg, t)
 }
 
+func TestLoadPreferences(t *testing.T) {
+	pkg := newPKG(pkgPath).(*pkg)
+	pkg.loadPreferences(prefPath)
+	checkPreferences(pkg, t)
+}
+
+func TestLoadBuildSystem(t *testing.T) {
+	pkg := newPKG(pkgPath).(*pkg)
+	pkg.loadBuildSystem(bsPath)
+	checkBuildSystem(pkg, t)
+}
+
+func TestLoadSnippet(t *testing.T) {
+	pkg := newPKG(pkgPath).(*pkg)
+	pkg.loadSnippet(snipPath)
+	checkSnippet(pkg, t)
+}
+
+func TestLoadCompletions(t *testing.T) {
+	pkg := newPKG(pkgPath).(*pkg)
+	pkg.loadCompletions(compPath)
+	checkCompletions(pkg, t)
+}
+
+func TestLoadMacro(t *testing.T) {
+	pkg := newPKG(pkgPath).(*pkg)
+	pkg.loadMacro(macroPath)
+	checkMacro(pkg, t)
+}
+
+func TestLoadMenu(t *testing.T) {
+	pkg := newPKG(pkgPath).(*pkg)
+	removeMenus(pkg)
+	pkg.loadMenu(menuPath)
+	checkMenu(pkg, t)
+}
+
+func TestLoadCommands(t *testing.T) {
+	pkg := newPKG(pkgPath).(*pkg)
+	removeMenus(pkg)
+	pkg.loadCommands(cmdsPath)
+	checkCommands(pkg, t)
+}
+
+// Menus and palette entries are added to the ones the package already has,
+// the ones of previous tests are removed first.
+func removeMenus(p *pkg) {
+	menu.Remove(p.Name())
+	menu.RemoveCommands(p.Name())
+}
+
 func checkPlugin(p *pkg, t *testing.T) {
 	if _, exist := p.plugins[pluginPath]; !exist {
 		t.Errorf("Expected to %s exist in %s package plugins", pluginPath, p.Name())
@@ -69,11 +134,109 @@ func checkSyntax(p *pkg, t *testing.T) {
 	}
 }
 
+func checkBuildSystem(p *pkg, t *testing.T) {
+	bs, ok := p.buildSystems[bsPath]
+	if !ok {
+		t.Fatalf("Expected %s in %s package build systems", bsPath, p.Name())
+	}
+	if bs.Name != "Go" {
+		t.Errorf("Expected build system name Go, but got %s", bs.Name)
+	}
+	if name := "Packages/package/Go.sublime-build"; build.Get(name) != bs {
+		t.Errorf("Expected %s in registered build systems", name)
+	}
+}
+
+func checkPreferences(p *pkg, t *testing.T) {
+	pref, ok := p.preferences[prefPath]
+	if !ok {
+		t.Fatalf("Expected %s in %s package preferences", prefPath, p.Name())
+	}
+	if pref.Settings.ShowInSymbolList != 1 {
+		t.Errorf("Expected showInSymbolList 1, but got %d", pref.Settings.ShowInSymbolList)
+	}
+	if got := pref.Settings.SymbolTransformation.Apply("  main "); got != "main" {
+		t.Errorf("Expected transformed symbol main, but got %q", got)
+	}
+	if name := "Packages/package/Symbol List.tmPreferences"; preferences.Get(name) != pref {
+		t.Errorf("Expected %s in registered preferences", name)
+	}
+}
+
+func checkSnippet(p *pkg, t *testing.T) {
+	s, ok := p.snippets[snipPath]
+	if !ok {
+		t.Fatalf("Expected %s in %s package snippets", snipPath, p.Name())
+	}
+	if s.TabTrigger != "func" {
+		t.Errorf("Expected snippet tab trigger func, but got %s", s.TabTrigger)
+	}
+	if name := "Packages/package/func.sublime-snippet"; snippet.Get(name) != s {
+		t.Errorf("Expected %s in registered snippets", name)
+	}
+}
+
+func checkCompletions(p *pkg, t *testing.T) {
+	f, ok := p.completions[compPath]
+	if !ok {
+		t.Fatalf("Expected %s in %s package completions", compPath, p.Name())
+	}
+	if f.Scope != "source.go" || len(f.Completions) != 2 {
+		t.Errorf("Expected 2 source.go completions, but got %s %v", f.Scope, f.Completions)
+	}
+	if name := "Packages/package/Go.sublime-completions"; completion.Get(name) != f {
+		t.Errorf("Expected %s in registered completions", name)
+	}
+}
+
+func checkMacro(p *pkg, t *testing.T) {
+	m, ok := p.macros[macroPath]
+	if !ok {
+		t.Fatalf("Expected %s in %s package macros", macroPath, p.Name())
+	}
+	if len(m) != 3 || m[0].Command != "expand_selection" {
+		t.Errorf("Expected 3 commands starting with expand_selection, but got %v", m)
+	}
+	if name := "Packages/package/Delete Line.sublime-macro"; macro.Get(name) == nil {
+		t.Errorf("Expected %s in registered macros", name)
+	}
+}
+
+func checkMenu(p *pkg, t *testing.T) {
+	items := menu.ForPackage("Main", p.Name())
+	if len(items) != 1 || items[0].Id != "tools" || len(items[0].Children) != 1 {
+		t.Errorf("Expected tools menu of %s package, but got %v", p.Name(), items)
+	}
+}
+
+func checkCommands(p *pkg, t *testing.T) {
+	cmds := menu.PackageCommands(p.Name())
+	if len(cmds) != 1 || cmds[0].Caption != "Build" || cmds[0].Command != "build" {
+		t.Errorf("Expected Build command of %s package, but got %v", p.Name(), cmds)
+	}
+}
+
 func TestScan(t *testing.T) {
 	pkg := newPKG(pkgPath).(*pkg)
+	removeMenus(pkg)
 	filepath.Walk(pkg.Path(), pkg.scan)
 	checkColorScheme(pkg, t)
 	checkSyntax(pkg, t)
+	checkPreferences(pkg, t)
+	checkBuildSystem(pkg, t)
+	checkSnippet(pkg, t)
+	checkCompletions(pkg, t)
+	checkMacro(pkg, t)
+	checkMenu(pkg, t)
+	checkCommands(pkg, t)
+
+	name := "Packages/package/Go.tmLanguage"
+	if names := resource.Find("Go.tmLanguage"); len(names) != 1 || names[0] != name {
+		t.Errorf("Expected to find %s in resources, but got %v", name, names)
+	}
+	if syn := backend.GetEditor().GetSyntax(name); syn == nil {
+		t.Errorf("Expected %s in editor syntaxes", name)
+	}
 }
 
 func init() {
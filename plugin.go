@@ -27,17 +27,25 @@ func (p *plugin) Load() {
 	dir, file := filepath.Split(p.Path())
 	p.name = file
 	name := filepath.Base(dir) + "." + file[:len(file)-3]
+	log.Debug("Loading plugin %s", name)
+	l := py.NewLock()
+	defer l.Unlock()
+
 	s, err := py.NewUnicode(name)
 	if err != nil {
 		log.Warn(err)
 		return
 	}
 	defer s.Decref()
+	// the directory of the plugin is imported as the package
+	pth, err := py.NewUnicode(p.Path())
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+	defer pth.Decref()
 
-	log.Debug("Loading plugin %s", name)
-	l := py.NewLock()
-	defer l.Unlock()
-	if r, err := module.Base().CallMethodObjArgs("reload_plugin", s); err != nil {
+	if r, err := module.Base().CallMethodObjArgs("reload_plugin", s, pth); err != nil {
 		log.Warn(err)
 		return
 	} else if r != nil {
@@ -9,7 +9,7 @@ import (
 
 	"github.com/limetext/backend"
 	"github.com/limetext/gopy"
-	_ "github.com/limetext/sublime/api"
+	"github.com/limetext/sublime/api"
 )
 
 func TestPlugin(t *testing.T) {
@@ -32,3 +32,24 @@ func init() {
 	ed.Init()
 	ed.NewWindow()
 }
+
+func TestPluginNamespaces(t *testing.T) {
+	newPlugin("testdata/namespace/First/plugin.py").Load()
+	newPlugin("testdata/namespace/First/other.py").Load()
+	newPlugin("testdata/namespace/Second/plugin.py").Load()
+	pyTest(t, "namespace_test")
+}
+
+func TestPluginCommandState(t *testing.T) {
+	newPlugin("testdata/plugin.py").Load()
+	visible, enabled, checked := api.CommandState("test_state", backend.Args{"checked": true})
+	if !visible || enabled || !checked {
+		t.Errorf("Expected a visible, disabled and checked command, but got %v, %v and %v", visible, enabled, checked)
+	}
+	// the command is queried on one instance
+	for i := 0; i < 2; i++ {
+		if d := api.CommandDescription("test_state", backend.Args{"name": "test"}); d != "test 1" {
+			t.Errorf("Expected description %q, but got %q", "test 1", d)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package sublime
+
+import (
+	"path/filepath"
+)
+
+func isPreferences(path string) bool {
+	return filepath.Ext(path) == ".tmPreferences"
+}
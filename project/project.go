@@ -0,0 +1,221 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package project implements loading and saving sublime-project files.
+// https://www.sublimetext.com/docs/3/projects.html
+package project
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/limetext/loaders"
+)
+
+type (
+	Project struct {
+		lock     sync.Mutex
+		filename string
+		// the project file content as is, so we don't lose any key we don't
+		// know about when saving
+		data map[string]interface{}
+	}
+
+	Folder struct {
+		Path                  string   `json:"path"`
+		Name                  string   `json:"name,omitempty"`
+		FolderExcludePatterns []string `json:"folder_exclude_patterns,omitempty"`
+		FileExcludePatterns   []string `json:"file_exclude_patterns,omitempty"`
+		FileIncludePatterns   []string `json:"file_include_patterns,omitempty"`
+		FollowSymlinks        bool     `json:"follow_symlinks,omitempty"`
+	}
+)
+
+// New returns an empty project which isn't backed by any file.
+func New() *Project {
+	return &Project{data: make(map[string]interface{})}
+}
+
+// Load reads the project file.
+func Load(filename string) (*Project, error) {
+	d, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	p := New()
+	if err := loaders.LoadJSON(d, &p.data); err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	p.filename = filename
+	return p, nil
+}
+
+func (p *Project) FileName() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.filename
+}
+
+// SetFileName changes the file the project is saved to.
+func (p *Project) SetFileName(filename string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.filename = filename
+}
+
+// Data returns the project file content.
+func (p *Project) Data() map[string]interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ret := make(map[string]interface{}, len(p.data))
+	for k, v := range p.data {
+		ret[k] = v
+	}
+	return ret
+}
+
+// SetData replaces the project content and saves the project if it is
+// backed by a file.
+func (p *Project) SetData(data map[string]interface{}) error {
+	p.lock.Lock()
+	p.data = make(map[string]interface{}, len(data))
+	for k, v := range data {
+		p.data[k] = v
+	}
+	p.lock.Unlock()
+	return p.Save()
+}
+
+// Save writes the project to its file, projects without file are ignored.
+func (p *Project) Save() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.filename == "" {
+		return nil
+	}
+	d, err := json.MarshalIndent(p.data, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.filename, d, 0644)
+}
+
+// Dir returns the directory relative folder paths are resolved against.
+func (p *Project) Dir() string {
+	if fn := p.FileName(); fn != "" {
+		return filepath.Dir(fn)
+	}
+	return ""
+}
+
+// Folders returns the project folders with absolute paths.
+func (p *Project) Folders() []Folder {
+	var folders []Folder
+	if !p.decode("folders", &folders) {
+		return nil
+	}
+	dir := p.Dir()
+	for i := range folders {
+		if !filepath.IsAbs(folders[i].Path) && dir != "" {
+			folders[i].Path = filepath.Join(dir, folders[i].Path)
+		}
+		folders[i].Path = filepath.Clean(folders[i].Path)
+	}
+	return folders
+}
+
+// Settings returns the settings that override window settings.
+func (p *Project) Settings() map[string]interface{} {
+	var set map[string]interface{}
+	p.decode("settings", &set)
+	return set
+}
+
+// BuildSystems returns the build systems defined in the project.
+func (p *Project) BuildSystems() []map[string]interface{} {
+	var bs []map[string]interface{}
+	p.decode("build_systems", &bs)
+	return bs
+}
+
+// Decodes the value of key into v going through json, which is the simplest
+// way of getting typed values from the generic data.
+func (p *Project) decode(key string, v interface{}) bool {
+	p.lock.Lock()
+	val, ok := p.data[key]
+	p.lock.Unlock()
+	if !ok {
+		return false
+	}
+	d, err := json.Marshal(val)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(d, v) == nil
+}
+
+// Excluded reports whether the file or folder at rel path inside the folder
+// is excluded by the folder patterns. Include patterns only apply to files.
+func (f *Folder) Excluded(rel string, isDir bool) bool {
+	if isDir {
+		return match(f.FolderExcludePatterns, rel)
+	}
+	if match(f.FileExcludePatterns, rel) {
+		return true
+	}
+	return len(f.FileIncludePatterns) > 0 && !match(f.FileIncludePatterns, rel)
+}
+
+// Files returns the files of the folder which aren't excluded.
+func (f *Folder) Files() ([]string, error) {
+	var files []string
+	err := filepath.Walk(f.Path, func(pt string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(f.Path, pt)
+		if err != nil || rel == "." {
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 && !f.FollowSymlinks {
+			return nil
+		}
+		if f.Excluded(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			files = append(files, pt)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Patterns without a slash match the base name, the others match the whole
+// relative path.
+func match(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	base := rel[strings.LastIndex(rel, "/")+1:]
+	for _, pat := range patterns {
+		name := base
+		if strings.Contains(pat, "/") {
+			name = rel
+		}
+		if ok, _ := path.Match(pat, name); ok {
+			return true
+		}
+	}
+	return false
+}
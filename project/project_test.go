@@ -0,0 +1,91 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testProject = "testdata/test.sublime-project"
+
+func TestLoad(t *testing.T) {
+	p, err := Load(testProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, _ := filepath.Abs(testProject)
+	if p.FileName() != abs {
+		t.Errorf("Expected file name %s, but got %s", abs, p.FileName())
+	}
+
+	folders := p.Folders()
+	if len(folders) != 1 {
+		t.Fatalf("Expected 1 folder, but got %d", len(folders))
+	}
+	if exp := filepath.Join(filepath.Dir(abs), "src"); folders[0].Path != exp {
+		t.Errorf("Expected folder path %s, but got %s", exp, folders[0].Path)
+	}
+	if exp := []string{"build"}; !reflect.DeepEqual(folders[0].FolderExcludePatterns, exp) {
+		t.Errorf("Expected folder exclude patterns %v, but got %v", exp, folders[0].FolderExcludePatterns)
+	}
+
+	if ts := p.Settings()["tab_size"]; ts != 8.0 {
+		t.Errorf("Expected tab_size 8, but got %v", ts)
+	}
+	if bs := p.BuildSystems(); len(bs) != 1 || bs[0]["name"] != "List" {
+		t.Errorf("Expected List build system, but got %v", bs)
+	}
+}
+
+func TestFolderFiles(t *testing.T) {
+	p, err := Load(testProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := p.Folders()[0]
+	files, err := f.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{filepath.Join(f.Path, "main.txt"), filepath.Join(f.Path, "sub", "sub.txt")}
+	if !reflect.DeepEqual(files, exp) {
+		t.Errorf("Expected files %v, but got %v", exp, files)
+	}
+
+	f.FileIncludePatterns = []string{"sub/*.txt"}
+	files, _ = f.Files()
+	if exp := exp[1:]; !reflect.DeepEqual(files, exp) {
+		t.Errorf("Expected files %v, but got %v", exp, files)
+	}
+}
+
+func TestSetData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := New()
+	p.SetFileName(filepath.Join(dir, "new.sublime-project"))
+	data := map[string]interface{}{
+		"folders": []interface{}{map[string]interface{}{"path": "."}},
+	}
+	if err := p.SetData(data); err != nil {
+		t.Fatal(err)
+	}
+
+	p2, err := Load(p.FileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if folders := p2.Folders(); len(folders) != 1 || folders[0].Path != dir {
+		t.Errorf("Expected saved folder %s, but got %v", dir, folders)
+	}
+}
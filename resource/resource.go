@@ -0,0 +1,231 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package resource keeps an index of the files of all the packages so they
+// could be referred to by their package relative names like
+// "Packages/Default/Default.sublime-keymap", no matter if the package is a
+// folder or a .sublime-package archive.
+package resource
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Prefix of all resource names
+const Prefix = "Packages/"
+
+// Resources of folder packages override the ones with the same name from
+// archive packages
+const (
+	archivePriority = iota
+	folderPriority
+)
+
+type (
+	Index struct {
+		lock    sync.RWMutex
+		entries map[string]entry
+	}
+
+	entry struct {
+		// path of the file or the archive containing the resource
+		path string
+		// name of the file inside the archive, empty for folder resources
+		member   string
+		priority int
+	}
+)
+
+func NewIndex() *Index {
+	return &Index{entries: make(map[string]entry)}
+}
+
+// Name returns the resource name of the file at rel path in the package.
+func Name(pkg, rel string) string {
+	return Prefix + pkg + "/" + filepath.ToSlash(rel)
+}
+
+// IsName reports whether name is a resource name rather than a file path.
+func IsName(name string) bool {
+	return strings.HasPrefix(name, Prefix)
+}
+
+func (i *Index) add(name string, e entry) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if old, ok := i.entries[name]; ok && old.priority > e.priority {
+		return
+	}
+	i.entries[name] = e
+}
+
+// AddFile adds the file at path which is at rel path in the folder package.
+func (i *Index) AddFile(pkg, rel, path string) {
+	i.add(Name(pkg, rel), entry{path: path, priority: folderPriority})
+}
+
+// AddArchive adds all the files of the archive package at path and returns
+// their resource names.
+func (i *Index) AddArchive(pkg, path string) ([]string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var names []string
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		name := Name(pkg, f.Name)
+		i.add(name, entry{path: path, member: f.Name, priority: archivePriority})
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Find returns the names of the resources whose base name matches the
+// pattern, the names are sorted in package load order.
+func (i *Index) Find(pattern string) []string {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	ret := make([]string, 0)
+	for name := range i.entries {
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			ret = append(ret, name)
+		}
+	}
+	Sort(ret)
+	return ret
+}
+
+// Read returns the content of the resource.
+func (i *Index) Read(name string) ([]byte, error) {
+	i.lock.RLock()
+	e, ok := i.entries[name]
+	i.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Resource not found: %s", name)
+	}
+	if e.member == "" {
+		return ioutil.ReadFile(e.path)
+	}
+
+	r, err := zip.OpenReader(e.path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != e.member {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("Resource %s not found in %s", e.member, e.path)
+}
+
+// Path returns the file path of folder resources, ok is false for unknown
+// resources and the ones inside archives.
+func (i *Index) Path(name string) (path string, ok bool) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	e, ok := i.entries[name]
+	if !ok || e.member != "" {
+		return "", false
+	}
+	return e.path, true
+}
+
+// Sort sorts the resource names in package load order.
+func Sort(names []string) {
+	sort.Sort(byLoadOrder(names))
+}
+
+// Default package goes first and User package last, the rest are sorted by
+// name like sublime does
+type byLoadOrder []string
+
+func (b byLoadOrder) Len() int {
+	return len(b)
+}
+
+func (b byLoadOrder) Less(i, j int) bool {
+	pi, pj := pkgOrder(b[i]), pkgOrder(b[j])
+	if pi != pj {
+		return pi < pj
+	}
+	return b[i] < b[j]
+}
+
+func (b byLoadOrder) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
+
+func pkgOrder(name string) int {
+	switch pkg := strings.SplitN(strings.TrimPrefix(name, Prefix), "/", 2)[0]; pkg {
+	case "Default":
+		return 0
+	case "User":
+		return 2
+	default:
+		return 1
+	}
+}
+
+var index = NewIndex()
+
+// AddFile adds a folder package file to the global index.
+func AddFile(pkg, rel, path string) {
+	index.AddFile(pkg, rel, path)
+}
+
+// AddArchive adds the files of an archive package to the global index.
+func AddArchive(pkg, path string) ([]string, error) {
+	return index.AddArchive(pkg, path)
+}
+
+// Find returns the names of the resources matching pattern from the global
+// index.
+func Find(pattern string) []string {
+	return index.Find(pattern)
+}
+
+// Load returns the content of the resource from the global index as text.
+func Load(name string) (string, error) {
+	data, err := index.Read(name)
+	return string(data), err
+}
+
+// LoadBinary returns the content of the resource from the global index.
+func LoadBinary(name string) ([]byte, error) {
+	return index.Read(name)
+}
+
+// Path returns the file path of a folder resource from the global index.
+func Path(name string) (string, bool) {
+	return index.Path(name)
+}
+
+// ReadFile reads the resource if name is a resource name, otherwise reads
+// the file from disk. Loaders use this so files could refer to each other by
+// resource names.
+func ReadFile(name string) ([]byte, error) {
+	if IsName(name) {
+		return index.Read(name)
+	}
+	return ioutil.ReadFile(name)
+}
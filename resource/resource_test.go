@@ -0,0 +1,101 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package resource
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Creates an archive package containing the files in a temp dir
+func archive(t *testing.T, files map[string]string) (string, func()) {
+	dir, err := ioutil.TempDir("", "resource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "Archive.sublime-package")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestIndex(t *testing.T) {
+	path, clean := archive(t, map[string]string{
+		"a.txt":     "archive a",
+		"sub/b.txt": "archive b",
+	})
+	defer clean()
+
+	idx := NewIndex()
+	names, err := idx.AddArchive("Archive", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Errorf("Expected 2 resources in the archive, but got %v", names)
+	}
+	// folder resources override the archive ones
+	idx.AddFile("Archive", "a.txt", filepath.Join("testdata", "a.txt"))
+	idx.AddFile("Default", "a.txt", filepath.Join("testdata", "a.txt"))
+	idx.AddFile("User", "a.txt", filepath.Join("testdata", "a.txt"))
+
+	find := []struct {
+		pattern string
+		exp     []string
+	}{
+		{"a.txt", []string{"Packages/Default/a.txt", "Packages/Archive/a.txt", "Packages/User/a.txt"}},
+		{"*.txt", []string{"Packages/Default/a.txt", "Packages/Archive/a.txt", "Packages/Archive/sub/b.txt", "Packages/User/a.txt"}},
+		{"c.txt", []string{}},
+	}
+	for i, test := range find {
+		if got := idx.Find(test.pattern); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("Test %d: Expected %v, but got %v", i, test.exp, got)
+		}
+	}
+
+	read := []struct {
+		name string
+		exp  string
+		err  bool
+	}{
+		{"Packages/Archive/a.txt", "folder a\n", false},
+		{"Packages/Archive/sub/b.txt", "archive b", false},
+		{"Packages/Archive/c.txt", "", true},
+	}
+	for i, test := range read {
+		data, err := idx.Read(test.name)
+		if test.err != (err != nil) {
+			t.Errorf("Test %d: Expected error %v, but got %v", i, test.err, err)
+		}
+		if string(data) != test.exp {
+			t.Errorf("Test %d: Expected %q, but got %q", i, test.exp, data)
+		}
+	}
+
+	if _, ok := idx.Path("Packages/Archive/sub/b.txt"); ok {
+		t.Error("Expected archive resources not to have a path")
+	}
+	if p, ok := idx.Path("Packages/Default/a.txt"); !ok || p != filepath.Join("testdata", "a.txt") {
+		t.Errorf("Expected the path of Packages/Default/a.txt, but got %q", p)
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package selector implements scope selectors which pick scopes of the
+// syntax tree, like the scope of snippets and preferences.
+// https://manual.macromates.com/en/scope_selectors
+package selector
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type (
+	// Selector is a compiled scope selector.
+	Selector struct {
+		src string
+		m   matcher
+	}
+
+	matcher interface {
+		score(scopes [][]string) int
+	}
+
+	// Space separated scopes matching the descendants of the scope path.
+	path [][]string
+
+	// "-" prefixed expression matching when the inner doesn't.
+	negation struct {
+		m matcher
+	}
+
+	// Composite of two expressions joined with one of "|", "&" or "-".
+	composite struct {
+		op   byte
+		l, r matcher
+	}
+
+	// Comma separated alternatives.
+	group []matcher
+
+	parser struct {
+		src string
+		pos int
+	}
+)
+
+var cache = struct {
+	sync.Mutex
+	m map[string]*Selector
+}{m: make(map[string]*Selector)}
+
+// Compile parses the selector. Selectors are comma separated composites of
+// expressions joined with "|" (either), "&" (both) or "-" (excluding).
+// Expressions are space separated scope paths, "-" negated expressions or
+// parenthesized groups. Scope names may contain "*" wildcards and the
+// "L:", "R:" and "B:" side prefixes are accepted and ignored.
+func Compile(sel string) (*Selector, error) {
+	p := &parser{src: sel}
+	p.skip()
+	if p.eof() {
+		return &Selector{src: sel}, nil
+	}
+	m, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return &Selector{src: sel, m: m}, nil
+}
+
+// Score returns the score of the selector for the space separated scope
+// names, zero if it doesn't match. Following TextMate, matches deeper in the
+// scope path score higher and for the same depth longer matches win. Empty
+// selectors match everything with a score of 1.
+func (s *Selector) Score(scope string) int {
+	if s.m == nil {
+		return 1
+	}
+	return s.m.score(split(scope))
+}
+
+// Match reports whether the selector matches the scope.
+func (s *Selector) Match(scope string) bool {
+	return s.Score(scope) > 0
+}
+
+func (s *Selector) String() string {
+	return s.src
+}
+
+// Score compiles the selector, caching it for later calls, and returns its
+// score for the scope. Invalid selectors never match.
+func Score(sel, scope string) int {
+	cache.Lock()
+	s, ok := cache.m[sel]
+	if !ok {
+		var err error
+		if s, err = Compile(sel); err != nil {
+			s = nil
+		}
+		cache.m[sel] = s
+	}
+	cache.Unlock()
+	if s == nil {
+		return 0
+	}
+	return s.Score(scope)
+}
+
+// Match reports whether the selector matches the scope.
+func Match(sel, scope string) bool {
+	return Score(sel, scope) > 0
+}
+
+func split(scope string) [][]string {
+	names := strings.Fields(scope)
+	ret := make([][]string, len(names))
+	for i, n := range names {
+		ret[i] = strings.Split(n, ".")
+	}
+	return ret
+}
+
+// Matches the selector parts from the innermost scope outwards. Each
+// matched part adds its atom count weighted by the number of atoms of the
+// enclosing scopes, the integer equivalent of TextMate's rank.
+func (p path) score(scopes [][]string) int {
+	before := 0
+	for _, s := range scopes {
+		before += len(s)
+	}
+	sc, j := 0, len(p)
+	for i := len(scopes); i > 0 && j > 0 && j <= i; i-- {
+		before -= len(scopes[i-1])
+		if n := matchAtoms(p[j-1], scopes[i-1]); n > 0 {
+			shift := uint(before)
+			if shift > 40 {
+				shift = 40
+			}
+			sc += n << shift
+			j--
+		}
+	}
+	if j > 0 {
+		return 0
+	}
+	return sc
+}
+
+// Returns the number of matched atoms when the selector atoms are a prefix
+// of the scope atoms, zero otherwise.
+func matchAtoms(sel, scope []string) int {
+	if len(sel) > len(scope) {
+		return 0
+	}
+	for i, a := range sel {
+		if a != "*" && a != scope[i] {
+			return 0
+		}
+	}
+	return len(sel)
+}
+
+func (n negation) score(scopes [][]string) int {
+	if n.m.score(scopes) > 0 {
+		return 0
+	}
+	return 1
+}
+
+func (c composite) score(scopes [][]string) int {
+	l := c.l.score(scopes)
+	switch c.op {
+	case '|':
+		return max(l, c.r.score(scopes))
+	case '&':
+		if l == 0 {
+			return 0
+		}
+		if r := c.r.score(scopes); r > 0 {
+			return max(l, r)
+		}
+		return 0
+	case '-':
+		if l == 0 || c.r.score(scopes) > 0 {
+			return 0
+		}
+		return l
+	}
+	return 0
+}
+
+func (g group) score(scopes [][]string) int {
+	best := 0
+	for _, m := range g {
+		best = max(best, m.score(scopes))
+	}
+	return best
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) skip() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("selector %q at %d: %s", p.src, p.pos, fmt.Sprintf(format, a...))
+}
+
+func (p *parser) group() (matcher, error) {
+	var g group
+	for {
+		m, err := p.composite()
+		if err != nil {
+			return nil, err
+		}
+		g = append(g, m)
+		p.skip()
+		if p.eof() || p.src[p.pos] != ',' {
+			break
+		}
+		p.pos++
+	}
+	if len(g) == 1 {
+		return g[0], nil
+	}
+	return g, nil
+}
+
+func (p *parser) composite() (matcher, error) {
+	l, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skip()
+		if p.eof() {
+			return l, nil
+		}
+		op := p.src[p.pos]
+		if op != '|' && op != '&' && op != '-' {
+			return l, nil
+		}
+		p.pos++
+		r, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		l = composite{op: op, l: l, r: r}
+	}
+}
+
+func (p *parser) expression() (matcher, error) {
+	p.skip()
+	if p.eof() {
+		return nil, p.errorf("unexpected end")
+	}
+	switch p.src[p.pos] {
+	case '-':
+		p.pos++
+		m, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return negation{m}, nil
+	case '(':
+		p.pos++
+		m, err := p.group()
+		if err != nil {
+			return nil, err
+		}
+		p.skip()
+		if p.eof() || p.src[p.pos] != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return m, nil
+	}
+	return p.path()
+}
+
+func (p *parser) path() (matcher, error) {
+	var ret path
+	for {
+		p.skip()
+		name := p.name()
+		if name == "" {
+			break
+		}
+		ret = append(ret, strings.Split(name, "."))
+	}
+	if len(ret) == 0 {
+		return nil, p.errorf("expected scope name")
+	}
+	return ret, nil
+}
+
+// Reads a scope name, skipping side prefixes. A "-" continues a name but
+// never starts one as it's the exclusion operator there.
+func (p *parser) name() string {
+	for {
+		start := p.pos
+		for !p.eof() && isNameChar(p.src[p.pos], p.pos > start) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if !p.eof() && p.src[p.pos] == ':' && (name == "L" || name == "R" || name == "B") {
+			p.pos++
+			p.skip()
+			continue
+		}
+		return name
+	}
+}
+
+func isNameChar(c byte, inName bool) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '.', c == '_', c == '*', c == '+':
+		return true
+	case c == '-':
+		return inName
+	}
+	return false
+}
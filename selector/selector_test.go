@@ -0,0 +1,87 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package selector
+
+import (
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		sel, scope string
+		match      bool
+	}{
+		{"", "source.go", true},
+		{"source.go", "source.go entity.name", true},
+		{"source", "source.go", true},
+		{"source.g", "source.go", false},
+		{"source.go entity.name", "source.go meta.function entity.name.function", true},
+		{"entity.name source.go", "source.go entity.name", false},
+		{"text, source.go", "source.go", true},
+		{"source.*.embedded", "source.js.embedded.html", true},
+		{"source.*.embedded", "source.js", false},
+		{"source - string", "source.go string.quoted", false},
+		{"source - string", "source.go comment", true},
+		{"source - (string | comment)", "source.go comment.line", false},
+		{"source & comment", "source.go comment.line", true},
+		{"source & comment", "source.go string", false},
+		{"string | comment", "source.go comment", true},
+		{"-string", "source.go comment", true},
+		{"-string", "source.go string", false},
+		{"(text, source) & meta.tag-name", "text.html meta.tag-name", true},
+		{"L:source.go comment", "source.go comment", true},
+		{"source (", "source.go", false},
+	}
+	for i, test := range tests {
+		if got := Match(test.sel, test.scope); got != test.match {
+			t.Errorf("Test %d: Expected match %v of %q for %q", i, test.match, test.sel, test.scope)
+		}
+	}
+	if Score("source.go entity.name", "source.go entity.name") <= Score("entity.name", "source.go entity.name") {
+		t.Error("Expected the longer selector to score higher")
+	}
+	if Score("meta.function", "source.go meta.function") <= Score("source.go", "source.go meta.function") {
+		t.Error("Expected the deeper match to score higher")
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		sel string
+		err bool
+	}{
+		{"", false},
+		{"source.go, text", false},
+		{"source - (string | comment)", false},
+		{"source (", true},
+		{"source )", true},
+		{"source -", true},
+		{",", true},
+	}
+	for i, test := range tests {
+		if _, err := Compile(test.sel); (err != nil) != test.err {
+			t.Errorf("Test %d: Expected error %v compiling %q, but got %v", i, test.err, test.sel, err)
+		}
+	}
+}
+
+func TestScoreRank(t *testing.T) {
+	scope := "source.go meta.function string.quoted"
+	tests := []struct {
+		lo, hi string
+	}{
+		{"source", "source.go"},
+		{"source.go", "meta"},
+		{"meta", "string"},
+		{"string", "string.quoted"},
+		{"meta string", "source meta string"},
+		{"source - comment", "string - comment"},
+	}
+	for i, test := range tests {
+		if lo, hi := Score(test.lo, scope), Score(test.hi, scope); lo >= hi {
+			t.Errorf("Test %d: Expected %q (%d) to score lower than %q (%d)", i, test.lo, lo, test.hi, hi)
+		}
+	}
+}
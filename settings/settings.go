@@ -0,0 +1,142 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package settings implements named settings like sublime load_settings
+// does. The named settings are the merge of all the package files with the
+// same name in package load order and the user file on top.
+package settings
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/backend/log"
+	"github.com/limetext/backend/packages"
+	"github.com/limetext/loaders"
+	"github.com/limetext/sublime/resource"
+	"github.com/limetext/text"
+)
+
+type named struct {
+	// the user layer, this is the settings given to the callers so any
+	// modification goes to this layer
+	user text.HasSettings
+	// package layers in load order
+	layers []*text.HasSettings
+}
+
+var registry = struct {
+	sync.Mutex
+	m map[string]*named
+}{m: make(map[string]*named)}
+
+// Load returns the named settings, the first call loads the settings files
+// and later calls return the same settings.
+func Load(name string) *text.Settings {
+	registry.Lock()
+	defer registry.Unlock()
+	n, ok := registry.m[name]
+	if !ok {
+		n = load(name)
+		registry.m[name] = n
+	}
+	return n.user.Settings()
+}
+
+// Save writes the user layer of the named settings to the user directory.
+func Save(name string) error {
+	registry.Lock()
+	n, ok := registry.m[name]
+	registry.Unlock()
+	if !ok {
+		return nil
+	}
+	data, err := json.MarshalIndent(n.user.Settings(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(backend.GetEditor().UserPath(), name), data, 0644)
+}
+
+func load(name string) *named {
+	log.Fine("Loading named settings %s", name)
+	n := &named{}
+	var parent *text.HasSettings
+	for _, r := range layerNames(name) {
+		layer := new(text.HasSettings)
+		if parent != nil {
+			layer.Settings().SetParent(parent)
+		}
+		loadLayer(r, layer.Settings())
+		n.layers = append(n.layers, layer)
+		parent = layer
+	}
+	if parent != nil {
+		n.user.Settings().SetParent(parent)
+	}
+	if dir := backend.GetEditor().UserPath(); dir != "" {
+		pt := filepath.Join(dir, name)
+		log.Finest("Loading %s", pt)
+		packages.LoadJSON(pt, n.user.Settings())
+	}
+	return n
+}
+
+// Loads the package resource into the settings, files in folder packages
+// are watched by the packages loader
+func loadLayer(name string, set *text.Settings) {
+	log.Finest("Loading %s", name)
+	if pt, ok := resource.Path(name); ok {
+		packages.LoadJSON(pt, set)
+		return
+	}
+	data, err := resource.LoadBinary(name)
+	if err != nil {
+		log.Warn("Error loading %s: %s", name, err)
+		return
+	}
+	if err := loaders.LoadJSON(data, set); err != nil {
+		log.Warn("Error loading %s: %s", name, err)
+	}
+}
+
+// Returns the resource names of the package layers of the named settings,
+// each package file is followed by its platform specific file.
+func layerNames(name string) []string {
+	ext := path.Ext(name)
+	platName := strings.TrimSuffix(name, ext) + " (" + backend.GetEditor().Plat() + ")" + ext
+
+	files := make(map[string]bool)
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, n := range [][]string{resource.Find(name), resource.Find(platName)} {
+		for _, r := range n {
+			// user files are the top layer which is loaded separately
+			if strings.HasPrefix(r, resource.Prefix+"User/") {
+				continue
+			}
+			files[r] = true
+			if dir := path.Dir(r); !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	resource.Sort(dirs)
+
+	var ret []string
+	for _, dir := range dirs {
+		for _, n := range []string{name, platName} {
+			if r := dir + "/" + n; files[r] {
+				ret = append(ret, r)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,155 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package settings
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/limetext/backend"
+	"github.com/limetext/sublime/resource"
+)
+
+func init() {
+	for _, pkg := range []string{"Package", "Default"} {
+		resource.AddFile(pkg, "Test.sublime-settings", filepath.Join("testdata", pkg, "Test.sublime-settings"))
+	}
+}
+
+func TestLayerNames(t *testing.T) {
+	plat := "Layers (" + backend.GetEditor().Plat() + ").sublime-settings"
+	resource.AddFile("B", plat, "")
+	resource.AddFile("A", "Layers.sublime-settings", "")
+	resource.AddFile("B", "Layers.sublime-settings", "")
+	resource.AddFile("User", "Layers.sublime-settings", "")
+	resource.AddFile("Default", plat, "")
+
+	exp := []string{
+		"Packages/Default/" + plat,
+		"Packages/A/Layers.sublime-settings",
+		"Packages/B/Layers.sublime-settings",
+		"Packages/B/" + plat,
+	}
+	if got := layerNames("Layers.sublime-settings"); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected layers %v, but got %v", exp, got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	s := Load("Test.sublime-settings")
+	if s != Load("Test.sublime-settings") {
+		t.Error("Expected the same settings on each load")
+	}
+	tests := []struct {
+		key string
+		exp int
+	}{
+		{"a", 1},
+		{"b", 2},
+		{"c", 0},
+	}
+	for i, test := range tests {
+		if got := s.Int(test.key, 0); got != test.exp {
+			t.Errorf("Test %d: Expected %s to be %d, but got %d", i, test.key, test.exp, got)
+		}
+	}
+
+	var changed []string
+	s.AddOnChange("test", func(name string) {
+		changed = append(changed, name)
+	})
+	s.Set("a", 3)
+	if s.Int("a") != 3 {
+		t.Errorf("Expected a to be 3, but got %d", s.Int("a"))
+	}
+	if !reflect.DeepEqual(changed, []string{"a"}) {
+		t.Errorf("Expected on change callback for a, but got %v", changed)
+	}
+	s.ClearOnChange("test")
+}
+
+// Sets the user path to a temporary directory, the returned function
+// restores the previous one and removes the directory.
+func tempUserPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed := backend.GetEditor()
+	old := ed.UserPath()
+	ed.SetUserPath(dir)
+	return dir, func() {
+		ed.SetUserPath(old)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir, restore := tempUserPath(t)
+	defer restore()
+
+	if err := Save("Unloaded.sublime-settings"); err != nil {
+		t.Errorf("Expected saving unloaded settings to do nothing, but got %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Unloaded.sublime-settings")); !os.IsNotExist(err) {
+		t.Error("Expected unloaded settings not to be written")
+	}
+
+	s := Load("Save.sublime-settings")
+	s.Set("a", 1)
+	if err := Save("Save.sublime-settings"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "Save.sublime-settings"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Error unmarshaling saved settings %s: %s", data, err)
+	}
+	if exp := map[string]interface{}{"a": 1.0}; !reflect.DeepEqual(m, exp) {
+		t.Errorf("Expected saved settings %v, but got %v", exp, m)
+	}
+}
+
+func TestUserFileChange(t *testing.T) {
+	dir, restore := tempUserPath(t)
+	defer restore()
+
+	pt := filepath.Join(dir, "Change.sublime-settings")
+	if err := ioutil.WriteFile(pt, []byte(`{"a": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := Load("Change.sublime-settings")
+	if s.Int("a", 0) != 1 {
+		t.Fatalf("Expected a to be 1, but got %d", s.Int("a", 0))
+	}
+
+	changed := make(chan string, 1)
+	s.AddOnChange("test", func(name string) {
+		select {
+		case changed <- name:
+		default:
+		}
+	})
+	defer s.ClearOnChange("test")
+	if err := ioutil.WriteFile(pt, []byte(`{"a": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected on change callback after the user file changed")
+	}
+	if s.Int("a", 0) != 2 {
+		t.Errorf("Expected a to be 2, but got %d", s.Int("a", 0))
+	}
+}
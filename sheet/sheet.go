@@ -0,0 +1,119 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package sheet implements sheets, the content of a window tab. A sheet
+// either wraps a text view or shows an image or html.
+package sheet
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/limetext/backend"
+)
+
+type Kind int
+
+const (
+	Text Kind = iota
+	Image
+	HTML
+)
+
+var lastId int64
+
+type Sheet struct {
+	id     int
+	kind   Kind
+	window *backend.Window
+	view   *backend.View
+	// path of the image for image sheets
+	path string
+	// content of html sheets
+	html string
+
+	lock sync.Mutex
+	// transient sheets are previews which are replaced by the next
+	// transient sheet opened in the same group
+	transient bool
+}
+
+func newSheet(kind Kind, w *backend.Window) *Sheet {
+	return &Sheet{id: int(atomic.AddInt64(&lastId, 1)), kind: kind, window: w}
+}
+
+// NewText returns a sheet wrapping the view.
+func NewText(v *backend.View) *Sheet {
+	s := newSheet(Text, v.Window())
+	s.view = v
+	return s
+}
+
+// NewImage returns a sheet showing the image at path.
+func NewImage(w *backend.Window, path string) *Sheet {
+	s := newSheet(Image, w)
+	s.path = path
+	return s
+}
+
+// NewHTML returns a sheet showing the html content.
+func NewHTML(w *backend.Window, html string) *Sheet {
+	s := newSheet(HTML, w)
+	s.html = html
+	return s
+}
+
+func (s *Sheet) Id() int {
+	return s.id
+}
+
+func (s *Sheet) Kind() Kind {
+	return s.kind
+}
+
+func (s *Sheet) Window() *backend.Window {
+	if s.view != nil {
+		return s.view.Window()
+	}
+	return s.window
+}
+
+// View returns the view of text sheets and nil for other kinds.
+func (s *Sheet) View() *backend.View {
+	return s.view
+}
+
+// Path returns the image path of image sheets.
+func (s *Sheet) Path() string {
+	return s.path
+}
+
+// HTML returns the content of html sheets.
+func (s *Sheet) HTML() string {
+	return s.html
+}
+
+func (s *Sheet) IsTransient() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.transient
+}
+
+func (s *Sheet) SetTransient(t bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.transient = t
+}
+
+func (s *Sheet) String() string {
+	switch s.kind {
+	case Text:
+		return fmt.Sprintf("Sheet %d: %s", s.id, s.view)
+	case Image:
+		return fmt.Sprintf("Sheet %d: image %s", s.id, s.path)
+	default:
+		return fmt.Sprintf("Sheet %d: html", s.id)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package sheet
+
+import (
+	"testing"
+
+	"github.com/limetext/backend"
+)
+
+func TestNewSheets(t *testing.T) {
+	w := backend.GetEditor().NewWindow()
+	defer w.Close()
+	v := w.NewFile()
+	defer func() {
+		v.SetScratch(true)
+		v.Close()
+	}()
+
+	tests := []struct {
+		s    *Sheet
+		kind Kind
+		view *backend.View
+		path string
+		html string
+	}{
+		{NewText(v), Text, v, "", ""},
+		{NewImage(w, "image.png"), Image, nil, "image.png", ""},
+		{NewHTML(w, "<b>hi</b>"), HTML, nil, "", "<b>hi</b>"},
+	}
+	ids := make(map[int]bool)
+	for i, test := range tests {
+		if test.s.Kind() != test.kind {
+			t.Errorf("Test %d: Expected kind %d, but got %d", i, test.kind, test.s.Kind())
+		}
+		if test.s.View() != test.view {
+			t.Errorf("Test %d: Expected view %v, but got %v", i, test.view, test.s.View())
+		}
+		if test.s.Window() != w {
+			t.Errorf("Test %d: Expected window %v, but got %v", i, w, test.s.Window())
+		}
+		if test.s.Path() != test.path {
+			t.Errorf("Test %d: Expected path %q, but got %q", i, test.path, test.s.Path())
+		}
+		if test.s.HTML() != test.html {
+			t.Errorf("Test %d: Expected html %q, but got %q", i, test.html, test.s.HTML())
+		}
+		if ids[test.s.Id()] {
+			t.Errorf("Test %d: Sheet id %d isn't unique", i, test.s.Id())
+		}
+		ids[test.s.Id()] = true
+	}
+}
+
+func TestTransient(t *testing.T) {
+	s := NewImage(nil, "image.png")
+	if s.IsTransient() {
+		t.Error("Expected new sheets not to be transient")
+	}
+	s.SetTransient(true)
+	if !s.IsTransient() {
+		t.Error("Expected the sheet to be transient")
+	}
+}
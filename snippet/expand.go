@@ -0,0 +1,195 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"sort"
+
+	"github.com/limetext/text"
+)
+
+type (
+	// Expansion is the text of an expanded snippet with the fields in it,
+	// regions are rune offsets relative to the start of the text.
+	Expansion struct {
+		Text string
+		// fields in the tab order, $0 is always the last one
+		Fields     []Field
+		Transforms []Transform
+	}
+
+	// Field is a tab stop of the snippet, mirrored fields have more than
+	// one region.
+	Field struct {
+		Index   int
+		Regions []text.Region
+	}
+
+	// Transform is the region of a ${n/regex/format/} transformation of
+	// field n which should be updated when the field changes.
+	Transform struct {
+		Index  int
+		Region text.Region
+		*transform
+	}
+)
+
+type expander struct {
+	vars         map[string]string
+	placeholders map[int]*tabStop
+	// fields which placeholder is being rendered, to stop self references
+	rendering map[int]bool
+	buf       []rune
+	// whether fields and transforms are recorded
+	record     bool
+	fields     map[int][]text.Region
+	transforms []Transform
+}
+
+// Expand expands the snippet content, vars are the values of the snippet
+// variables like TM_FILENAME. Mirrors of a field get the text of the first
+// placeholder of the field.
+func Expand(content string, vars map[string]string) (*Expansion, error) {
+	nodes, err := parse(content)
+	if err != nil {
+		return nil, err
+	}
+	e := &expander{
+		vars:         vars,
+		placeholders: make(map[int]*tabStop),
+		rendering:    make(map[int]bool),
+		record:       true,
+		fields:       make(map[int][]text.Region),
+	}
+	e.collect(nodes)
+	e.render(nodes)
+
+	ret := &Expansion{Text: string(e.buf), Transforms: e.transforms}
+	indices := make([]int, 0, len(e.fields))
+	for i := range e.fields {
+		if i != 0 {
+			indices = append(indices, i)
+		}
+	}
+	sort.Ints(indices)
+	for _, i := range indices {
+		ret.Fields = append(ret.Fields, Field{Index: i, Regions: e.fields[i]})
+	}
+	end, ok := e.fields[0]
+	if !ok {
+		// without $0 the cursor ends up at the end of the snippet
+		end = []text.Region{{A: len(e.buf), B: len(e.buf)}}
+	}
+	ret.Fields = append(ret.Fields, Field{Index: 0, Regions: end})
+	return ret, nil
+}
+
+// Finds the first placeholder of every field
+func (e *expander) collect(nodes []node) {
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case *tabStop:
+			if _, ok := e.placeholders[t.index]; !ok && t.hasPlaceholder {
+				e.placeholders[t.index] = t
+			}
+			e.collect(t.placeholder)
+		case *variable:
+			e.collect(t.def)
+		}
+	}
+}
+
+// Returns the text the field gets on expansion
+func (e *expander) placeholder(index int) string {
+	t, ok := e.placeholders[index]
+	if !ok || e.rendering[index] {
+		return ""
+	}
+	e.rendering[index] = true
+	defer delete(e.rendering, index)
+	sub := &expander{
+		vars:         e.vars,
+		placeholders: e.placeholders,
+		rendering:    e.rendering,
+	}
+	sub.render(t.placeholder)
+	return string(sub.buf)
+}
+
+func (e *expander) render(nodes []node) {
+	for _, n := range nodes {
+		start := len(e.buf)
+		switch t := n.(type) {
+		case textNode:
+			e.buf = append(e.buf, []rune(string(t))...)
+		case *tabStop:
+			if e.record && e.placeholders[t.index] == t && !e.rendering[t.index] {
+				// nested fields are only recorded in the first placeholder
+				e.rendering[t.index] = true
+				e.render(t.placeholder)
+				delete(e.rendering, t.index)
+			} else {
+				e.buf = append(e.buf, []rune(e.placeholder(t.index))...)
+			}
+			if e.record {
+				e.fields[t.index] = append(e.fields[t.index], text.Region{A: start, B: len(e.buf)})
+			}
+		case *transformNode:
+			e.buf = append(e.buf, []rune(t.Apply(e.placeholder(t.index)))...)
+			if e.record {
+				e.transforms = append(e.transforms, Transform{
+					Index:     t.index,
+					Region:    text.Region{A: start, B: len(e.buf)},
+					transform: t.transform,
+				})
+			}
+		case *variable:
+			val, ok := e.vars[t.name]
+			switch {
+			case t.transform != nil:
+				e.buf = append(e.buf, []rune(t.Apply(val))...)
+			case ok && val != "":
+				e.buf = append(e.buf, []rune(val)...)
+			default:
+				e.render(t.def)
+			}
+		}
+	}
+}
+
+// Indent inserts indent after every new line of the expansion and replaces
+// tabs with tab, the regions are moved accordingly. It's used for matching
+// the indentation of the line the snippet is inserted in.
+func (x *Expansion) Indent(indent, tab string) {
+	in := []rune(x.Text)
+	// new offsets of the old ones
+	pos := make([]int, len(in)+1)
+	var out []rune
+	for i, c := range in {
+		pos[i] = len(out)
+		switch c {
+		case '\n':
+			out = append(out, c)
+			out = append(out, []rune(indent)...)
+		case '\t':
+			out = append(out, []rune(tab)...)
+		default:
+			out = append(out, c)
+		}
+	}
+	pos[len(in)] = len(out)
+	move := func(r text.Region) text.Region {
+		return text.Region{A: pos[r.A], B: pos[r.B]}
+	}
+	for i := range x.Fields {
+		for j, r := range x.Fields[i].Regions {
+			x.Fields[i].Regions[j] = move(r)
+		}
+	}
+	for i := range x.Transforms {
+		x.Transforms[i].Region = move(x.Transforms[i].Region)
+	}
+	x.Text = string(out)
+}
@@ -0,0 +1,169 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/text"
+)
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{
+		"TM_FILENAME": "main.go",
+		"EMPTY":       "",
+	}
+	tests := []struct {
+		in     string
+		text   string
+		fields []Field
+	}{
+		{
+			"plain text",
+			"plain text",
+			[]Field{{0, []text.Region{{A: 10, B: 10}}}},
+		},
+		{
+			"a $1 b $0 c",
+			"a  b  c",
+			[]Field{{1, []text.Region{{A: 2, B: 2}}}, {0, []text.Region{{A: 5, B: 5}}}},
+		},
+		{
+			"${2:two} ${1:one} $2",
+			"two one two",
+			[]Field{
+				{1, []text.Region{{A: 4, B: 7}}},
+				{2, []text.Region{{A: 0, B: 3}, {A: 8, B: 11}}},
+				{0, []text.Region{{A: 11, B: 11}}},
+			},
+		},
+		{
+			"${1:a ${2:b}}",
+			"a b",
+			[]Field{
+				{1, []text.Region{{A: 0, B: 3}}},
+				{2, []text.Region{{A: 2, B: 3}}},
+				{0, []text.Region{{A: 3, B: 3}}},
+			},
+		},
+		{
+			"$TM_FILENAME ${TM_FILENAME} ${NONE:none} ${EMPTY:$TM_FILENAME}",
+			"main.go main.go none main.go",
+			[]Field{{0, []text.Region{{A: 28, B: 28}}}},
+		},
+		{
+			"${TM_FILENAME/(\\w+)\\.go/$1_test.go/}",
+			"main_test.go",
+			[]Field{{0, []text.Region{{A: 12, B: 12}}}},
+		},
+		{
+			"\\$1 \\${2} cost $ \\\\ {\\}",
+			"$1 ${2} cost $ \\ {}",
+			[]Field{{0, []text.Region{{A: 19, B: 19}}}},
+		},
+		{
+			"${1:$1}",
+			"",
+			[]Field{{1, []text.Region{{A: 0, B: 0}, {A: 0, B: 0}}}, {0, []text.Region{{A: 0, B: 0}}}},
+		},
+		{
+			"ü$1",
+			"ü",
+			[]Field{{1, []text.Region{{A: 1, B: 1}}}, {0, []text.Region{{A: 1, B: 1}}}},
+		},
+	}
+	for i, test := range tests {
+		e, err := Expand(test.in, vars)
+		if err != nil {
+			t.Errorf("Test %d: Error expanding %s: %s", i, test.in, err)
+			continue
+		}
+		if e.Text != test.text {
+			t.Errorf("Test %d: Expected text %q, but got %q", i, test.text, e.Text)
+		}
+		if !reflect.DeepEqual(e.Fields, test.fields) {
+			t.Errorf("Test %d: Expected fields %v, but got %v", i, test.fields, e.Fields)
+		}
+	}
+}
+
+func TestExpandTransform(t *testing.T) {
+	e, err := Expand("${1:hello world} ${1/(\\w+) (\\w+)/\\u$2 (?1:yes:no)/}", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "hello world World yes"; e.Text != exp {
+		t.Errorf("Expected %q, but got %q", exp, e.Text)
+	}
+	if len(e.Transforms) != 1 {
+		t.Fatalf("Expected 1 transform, but got %d", len(e.Transforms))
+	}
+	tr := e.Transforms[0]
+	if exp := (text.Region{A: 12, B: 21}); tr.Index != 1 || tr.Region != exp {
+		t.Errorf("Expected transform of field 1 at %v, but got %d at %v", exp, tr.Index, tr.Region)
+	}
+	if s := tr.Apply("foo bar"); s != "Bar yes" {
+		t.Errorf("Expected Bar yes, but got %s", s)
+	}
+}
+
+func TestTransformApply(t *testing.T) {
+	tests := []struct {
+		snippet string
+		in      string
+		exp     string
+	}{
+		{"${1/a/b/}", "aaa", "baa"},
+		{"${1/a/b/g}", "aaa", "bbb"},
+		{"${1/A/b/gi}", "aAa", "bbb"},
+		{"${1/(\\w+)/\\U$1\\E!/}", "abc def", "ABC! def"},
+		{"${1/(\\w+)/\\L${1}x/}", "ABC", "abcx"},
+		{"${1/(\\w)(\\w*)/\\u$1$2/g}", "foo bar", "Foo Bar"},
+		{"${1/(\\w)(\\w)/\\2\\1/}", "ab", "ba"},
+		{"${1/(a)|(b)/(?1:A:(?2:B))/g}", "abc", "ABc"},
+		{"${1/x/\\n\\t\\$\\//}", "x", "\n\t$/"},
+		{"${1/^$/empty/}", "", "empty"},
+	}
+	for i, test := range tests {
+		e, err := Expand(test.snippet, nil)
+		if err != nil {
+			t.Errorf("Test %d: Error expanding %s: %s", i, test.snippet, err)
+			continue
+		}
+		if s := e.Transforms[0].Apply(test.in); s != test.exp {
+			t.Errorf("Test %d: Expected %q, but got %q", i, test.exp, s)
+		}
+	}
+}
+
+func TestExpandInvalid(t *testing.T) {
+	tests := []string{
+		"${1:unclosed",
+		"${1/unclosed",
+		"${1/(/x/}",
+		"${1-}",
+	}
+	for i, test := range tests {
+		if _, err := Expand(test, nil); err == nil {
+			t.Errorf("Test %d: Expected error expanding %s", i, test)
+		}
+	}
+}
+
+func TestIndent(t *testing.T) {
+	e, err := Expand("if $1 {\n\t$0\n}", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Indent("  ", "    ")
+	if exp := "if  {\n      \n  }"; e.Text != exp {
+		t.Errorf("Expected %q, but got %q", exp, e.Text)
+	}
+	exp := []Field{{1, []text.Region{{A: 3, B: 3}}}, {0, []text.Region{{A: 12, B: 12}}}}
+	if !reflect.DeepEqual(e.Fields, exp) {
+		t.Errorf("Expected fields %v, but got %v", exp, e.Fields)
+	}
+}
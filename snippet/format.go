@@ -0,0 +1,156 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"unicode"
+)
+
+// Case conversions of the format strings
+const (
+	caseNone = iota
+	caseUpper
+	caseLower
+)
+
+// Writes the replacement of a transform match. Format strings support $n,
+// ${n} and \n groups, (?n:if:else) conditionals, \n and \t, \u and \l changing the
+// case of the next character and \U and \L changing the case until \E.
+type formatter struct {
+	buf []rune
+	// case of the next character
+	next int
+	// case of the characters until \E
+	fold int
+}
+
+func (f *formatter) write(s string) {
+	for _, c := range s {
+		cs := f.fold
+		if f.next != caseNone {
+			cs, f.next = f.next, caseNone
+		}
+		switch cs {
+		case caseUpper:
+			c = unicode.ToUpper(c)
+		case caseLower:
+			c = unicode.ToLower(c)
+		}
+		f.buf = append(f.buf, c)
+	}
+}
+
+// Format returns the format string with the groups of a match, groups[0] is
+// the whole match.
+func Format(format string, groups []string) string {
+	f := &formatter{}
+	f.run([]rune(format), groups)
+	return string(f.buf)
+}
+
+func group(groups []string, i int) string {
+	if i < len(groups) {
+		return groups[i]
+	}
+	return ""
+}
+
+// Reads the digits at the start of s returning the number and its length
+func number(s []rune) (n, l int) {
+	for l < len(s) && isDigit(s[l]) {
+		n = n*10 + int(s[l]-'0')
+		l++
+	}
+	return n, l
+}
+
+func (f *formatter) run(format []rune, groups []string) {
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		switch {
+		case c == '\\' && i+1 < len(format):
+			i++
+			switch format[i] {
+			case 'n':
+				f.write("\n")
+			case 't':
+				f.write("\t")
+			case 'u':
+				f.next = caseUpper
+			case 'l':
+				f.next = caseLower
+			case 'U':
+				f.fold = caseUpper
+			case 'L':
+				f.fold = caseLower
+			case 'E':
+				f.fold = caseNone
+			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				n, l := number(format[i:])
+				f.write(group(groups, n))
+				i += l - 1
+			default:
+				f.write(string(format[i]))
+			}
+		case c == '$' && i+1 < len(format) && isDigit(format[i+1]):
+			n, l := number(format[i+1:])
+			f.write(group(groups, n))
+			i += l
+		case c == '$' && i+2 < len(format) && format[i+1] == '{' && isDigit(format[i+2]):
+			n, l := number(format[i+2:])
+			if i+2+l < len(format) && format[i+2+l] == '}' {
+				f.write(group(groups, n))
+				i += l + 2
+			} else {
+				f.write(string(c))
+			}
+		case c == '(' && i+2 < len(format) && format[i+1] == '?' && isDigit(format[i+2]):
+			n, l := number(format[i+2:])
+			start := i + 2 + l
+			if start >= len(format) || format[start] != ':' {
+				f.write(string(c))
+				continue
+			}
+			then, els, end := conditional(format, start+1)
+			if group(groups, n) != "" {
+				f.run(then, groups)
+			} else {
+				f.run(els, groups)
+			}
+			i = end
+		default:
+			f.write(string(c))
+		}
+	}
+}
+
+// Splits the branches of the conditional starting at i, end is the index of
+// the closing parenthesis.
+func conditional(format []rune, i int) (then, els []rune, end int) {
+	depth := 0
+	sep := -1
+	for end = i; end < len(format); end++ {
+		switch c := format[end]; {
+		case c == '\\':
+			end++
+		case c == '(' && end+1 < len(format) && format[end+1] == '?':
+			depth++
+		case c == ':' && depth == 0 && sep == -1:
+			sep = end
+		case c == ')':
+			if depth == 0 {
+				if sep == -1 {
+					return format[i:end], nil, end
+				}
+				return format[i:sep], format[sep+1 : end], end
+			}
+			depth--
+		}
+	}
+	if sep == -1 {
+		return format[i:], nil, end
+	}
+	return format[i:sep], format[sep+1:], end
+}
@@ -0,0 +1,281 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/limetext/rubex"
+)
+
+type (
+	node interface{}
+
+	// literal text
+	textNode string
+
+	// $1, ${1} and ${1:placeholder}
+	tabStop struct {
+		index          int
+		hasPlaceholder bool
+		placeholder    []node
+	}
+
+	// ${1/regex/format/flags}
+	transformNode struct {
+		index int
+		*transform
+	}
+
+	// $NAME, ${NAME}, ${NAME:default} and ${NAME/regex/format/flags}
+	variable struct {
+		name string
+		def  []node
+		*transform
+	}
+
+	transform struct {
+		re     Regexp
+		format string
+		global bool
+	}
+
+	// Regexp is the regex transforms replace the matches of, it's
+	// implemented by rubex regexps which transform regexes are compiled
+	// with.
+	Regexp interface {
+		FindAllStringSubmatchIndex(s string, n int) [][]int
+	}
+)
+
+type parser struct {
+	s   []rune
+	pos int
+}
+
+func parse(s string) ([]node, error) {
+	p := &parser{s: []rune(s)}
+	return p.parse(false)
+}
+
+func (p *parser) peek(i int) rune {
+	if p.pos+i < len(p.s) {
+		return p.s[p.pos+i]
+	}
+	return 0
+}
+
+// Parses until the end of the input or the closing brace of the nested
+// placeholder.
+func (p *parser) parse(nested bool) ([]node, error) {
+	var (
+		nodes []node
+		buf   []rune
+	)
+	flush := func() {
+		if len(buf) != 0 {
+			nodes = append(nodes, textNode(buf))
+			buf = nil
+		}
+	}
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == '\\' && strings.ContainsRune(`\$}`, p.peek(1)):
+			buf = append(buf, p.peek(1))
+			p.pos += 2
+		case c == '}' && nested:
+			flush()
+			return nodes, nil
+		case c == '$':
+			n, err := p.dollar()
+			if err != nil {
+				return nil, err
+			}
+			if n == nil {
+				buf = append(buf, c)
+				p.pos++
+				continue
+			}
+			flush()
+			nodes = append(nodes, n)
+		default:
+			buf = append(buf, c)
+			p.pos++
+		}
+	}
+	if nested {
+		return nil, fmt.Errorf("Unclosed ${ in snippet")
+	}
+	flush()
+	return nodes, nil
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameRune(c rune, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && isDigit(c))
+}
+
+// Reads a tab stop index or a variable name
+func (p *parser) name() (index int, name string) {
+	start := p.pos
+	if isDigit(p.peek(0)) {
+		for isDigit(p.peek(0)) {
+			index = index*10 + int(p.peek(0)-'0')
+			p.pos++
+		}
+		return index, ""
+	}
+	for isNameRune(p.peek(0), p.pos == start) {
+		p.pos++
+	}
+	return 0, string(p.s[start:p.pos])
+}
+
+// Parses the field or variable starting at the current dollar sign, nil
+// means the dollar sign is a literal.
+func (p *parser) dollar() (node, error) {
+	start := p.pos
+	p.pos++
+	if c := p.peek(0); c != '{' {
+		if !isDigit(c) && !isNameRune(c, true) {
+			p.pos = start
+			return nil, nil
+		}
+		if index, name := p.name(); name != "" {
+			return &variable{name: name}, nil
+		} else {
+			return &tabStop{index: index}, nil
+		}
+	}
+
+	p.pos++
+	if c := p.peek(0); !isDigit(c) && !isNameRune(c, true) {
+		p.pos = start
+		return nil, nil
+	}
+	index, name := p.name()
+	switch p.peek(0) {
+	case '}':
+		p.pos++
+		if name != "" {
+			return &variable{name: name}, nil
+		}
+		return &tabStop{index: index}, nil
+	case ':':
+		p.pos++
+		nodes, err := p.parse(true)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		if name != "" {
+			return &variable{name: name, def: nodes}, nil
+		}
+		return &tabStop{index: index, hasPlaceholder: true, placeholder: nodes}, nil
+	case '/':
+		p.pos++
+		t, err := p.transform()
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			return &variable{name: name, transform: t}, nil
+		}
+		return &transformNode{index: index, transform: t}, nil
+	default:
+		return nil, fmt.Errorf("Unexpected %q in snippet field at %d", p.peek(0), p.pos)
+	}
+}
+
+// Reads the part of a transform until the unescaped sep, escapes of sep are
+// removed.
+func (p *parser) until(sep rune) (string, error) {
+	var buf []rune
+	for ; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		switch {
+		case c == '\\' && p.peek(1) == sep:
+			buf = append(buf, sep)
+			p.pos++
+		case c == '\\' && p.peek(1) != 0:
+			buf = append(buf, c, p.peek(1))
+			p.pos++
+		case c == sep:
+			p.pos++
+			return string(buf), nil
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return "", fmt.Errorf("Unclosed transform in snippet, expected %q", sep)
+}
+
+// Parses regex/format/flags} of a transform, the g flag replaces all the
+// matches and i makes the regex case insensitive.
+func (p *parser) transform() (*transform, error) {
+	re, err := p.until('/')
+	if err != nil {
+		return nil, err
+	}
+	format, err := p.until('/')
+	if err != nil {
+		return nil, err
+	}
+	flags, err := p.until('}')
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(flags, "i") {
+		re = "(?i)" + re
+	}
+	t := &transform{format: format, global: strings.Contains(flags, "g")}
+	if t.re, err = rubex.Compile(re); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Substitute applies the regex/format/flags transform to s the way the
+// ${NAME/regex/format/flags} variables of snippets are applied.
+func Substitute(s, transform string) (string, error) {
+	p := &parser{s: []rune(transform + "}")}
+	t, err := p.transform()
+	if err != nil {
+		return s, err
+	}
+	return t.Apply(s), nil
+}
+
+// Apply replaces the matches of the transform regex in s with the format.
+func (t *transform) Apply(s string) string {
+	return Replace(t.re, s, t.format, t.global)
+}
+
+// Replace replaces the first match of re in s, or all of them with global,
+// with the format string. Format strings are the ones of snippet transforms.
+func Replace(re Regexp, s, format string, global bool) string {
+	var buf []rune
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		buf = append(buf, []rune(s[last:m[0]])...)
+		groups := make([]string, len(m)/2)
+		for i := range groups {
+			if m[2*i] >= 0 {
+				groups[i] = s[m[2*i]:m[2*i+1]]
+			}
+		}
+		buf = append(buf, []rune(Format(format, groups))...)
+		last = m[1]
+		if !global {
+			break
+		}
+	}
+	return string(buf) + s[last:]
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package snippet implements sublime snippets.
+// https://docs.sublimetext.io/guide/extensibility/snippets.html
+package snippet
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Snippet is the content of a .sublime-snippet file.
+type Snippet struct {
+	Content     string `xml:"content"`
+	TabTrigger  string `xml:"tabTrigger"`
+	Scope       string `xml:"scope"`
+	Description string `xml:"description"`
+}
+
+// Load parses a .sublime-snippet file, the content is validated so snippets
+// with invalid syntax are reported on load rather than on insertion.
+func Load(data []byte) (*Snippet, error) {
+	s := &Snippet{}
+	if err := xml.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if _, err := parse(s.Content); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Expand expands the snippet content with the given variables.
+func (s *Snippet) Expand(vars map[string]string) (*Expansion, error) {
+	return Expand(s.Content, vars)
+}
+
+func (s *Snippet) String() string {
+	if s.Description != "" {
+		return s.TabTrigger + ": " + s.Description
+	}
+	return s.TabTrigger
+}
+
+var snippets = struct {
+	sync.Mutex
+	m map[string]*Snippet
+}{m: make(map[string]*Snippet)}
+
+// Add registers the snippet under the given key which is usually the
+// resource name of its file.
+func Add(key string, s *Snippet) {
+	snippets.Lock()
+	defer snippets.Unlock()
+	snippets.m[key] = s
+}
+
+// Get returns the snippet registered with key.
+func Get(key string) *Snippet {
+	snippets.Lock()
+	defer snippets.Unlock()
+	return snippets.m[key]
+}
+
+// Find returns the registered snippets having a tab trigger with the given
+// prefix whose scope matches, best matches first. score returns the score of
+// a scope selector, zero meaning no match. Snippets without scope match
+// everywhere.
+func Find(prefix string, score func(selector string) int) []*Snippet {
+	snippets.Lock()
+	keys := make([]string, 0, len(snippets.m))
+	for k := range snippets.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var matches byScore
+	for _, k := range keys {
+		s := snippets.m[k]
+		if s.TabTrigger == "" || !strings.HasPrefix(s.TabTrigger, prefix) {
+			continue
+		}
+		sc := 1
+		if s.Scope != "" {
+			if sc = score(s.Scope); sc <= 0 {
+				continue
+			}
+		}
+		matches = append(matches, match{s, sc})
+	}
+	snippets.Unlock()
+
+	sort.Stable(matches)
+	ret := make([]*Snippet, len(matches))
+	for i, m := range matches {
+		ret[i] = m.s
+	}
+	return ret
+}
+
+type match struct {
+	s     *Snippet
+	score int
+}
+
+type byScore []match
+
+func (b byScore) Len() int {
+	return len(b)
+}
+
+func (b byScore) Less(i, j int) bool {
+	return b[i].score > b[j].score
+}
+
+func (b byScore) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
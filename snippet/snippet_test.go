@@ -0,0 +1,81 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package snippet
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "hello.sublime-snippet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &Snippet{
+		Content:     "Hello, ${1:this} is a ${2:snippet}.$0",
+		TabTrigger:  "hello",
+		Scope:       "source.python",
+		Description: "Hello snippet",
+	}
+	if !reflect.DeepEqual(s, exp) {
+		t.Errorf("Expected %+v, but got %+v", exp, s)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []string{
+		"<snippet><content>",
+		"<snippet><content>${1:unclosed</content></snippet>",
+		"<snippet><content>${1/(/x/}</content></snippet>",
+	}
+	for i, test := range tests {
+		if _, err := Load([]byte(test)); err == nil {
+			t.Errorf("Test %d: Expected error loading %s", i, test)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	py := &Snippet{TabTrigger: "for", Scope: "source.python"}
+	pyFor := &Snippet{TabTrigger: "fori", Scope: "source.python meta.loop"}
+	any := &Snippet{TabTrigger: "fixme"}
+	Add("Packages/Test/py.sublime-snippet", py)
+	Add("Packages/Test/pyFor.sublime-snippet", pyFor)
+	Add("Packages/Test/any.sublime-snippet", any)
+	Add("Packages/Test/none.sublime-snippet", &Snippet{})
+
+	scores := map[string]int{"source.python": 1, "source.python meta.loop": 2}
+	score := func(sel string) int {
+		return scores[sel]
+	}
+	if s := Get("Packages/Test/py.sublime-snippet"); s != py {
+		t.Errorf("Expected to get %s, but got %s", py, s)
+	}
+	tests := []struct {
+		prefix string
+		exp    []*Snippet
+	}{
+		{"f", []*Snippet{pyFor, any, py}},
+		{"for", []*Snippet{pyFor, py}},
+		{"fix", []*Snippet{any}},
+		{"x", []*Snippet{}},
+	}
+	for i, test := range tests {
+		if ss := Find(test.prefix, score); !reflect.DeepEqual(ss, test.exp) {
+			t.Errorf("Test %d: Expected %v, but got %v", i, test.exp, ss)
+		}
+	}
+	delete(scores, "source.python meta.loop")
+	if ss := Find("for", score); !reflect.DeepEqual(ss, []*Snippet{py}) {
+		t.Errorf("Expected only %s to match, but got %v", py, ss)
+	}
+}
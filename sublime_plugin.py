@@ -2,20 +2,54 @@ import os
 import os.path
 import inspect
 import traceback
-import imp
 import sublime
 import sys
 import importlib
+import importlib.machinery
 
 
 class Command(object):
 
-    def is_enabled(self, args=None):
+    def is_enabled(self):
         return True
 
-    def is_visible(self, args=None):
+    def is_visible(self):
         return True
 
+    def is_checked(self):
+        return False
+
+    def description(self):
+        return None
+
+    def want_event(self):
+        return False
+
+    def call_(self, f, kwargs):
+        if kwargs and 'event' in kwargs and not self.want_event():
+            del kwargs['event']
+
+        if kwargs:
+            try:
+                return f(**kwargs)
+            except TypeError:
+                # like sublime, methods not taking the arguments are
+                # called without them
+                return f()
+        return f()
+
+    def is_enabled_(self, kwargs):
+        return self.call_(self.is_enabled, kwargs)
+
+    def is_visible_(self, kwargs):
+        return self.call_(self.is_visible, kwargs)
+
+    def is_checked_(self, kwargs):
+        return self.call_(self.is_checked, kwargs)
+
+    def description_(self, kwargs):
+        return self.call_(self.description, kwargs)
+
 
 class ApplicationCommand(Command):
     pass
@@ -27,7 +61,7 @@ class WindowCommand(Command):
         self.window = wnd
 
     def run_(self, kwargs):
-        if kwargs and 'event' in kwargs:
+        if kwargs and 'event' in kwargs and not self.want_event():
             del kwargs['event']
 
         if kwargs:
@@ -42,7 +76,7 @@ class TextCommand(Command):
         self.view = view
 
     def run__(self, edit_token, kwargs):
-        if kwargs and 'event' in kwargs:
+        if kwargs and 'event' in kwargs and not self.want_event():
             del kwargs['event']
 
         if kwargs:
@@ -55,44 +89,76 @@ class EventListener(object):
     pass
 
 
-def fn(fullname):
-    paths = fullname.split(".")
-    paths = "/".join(paths)
-    for p in sys.path:
-        f = os.path.abspath(os.path.join(p, paths))
-        if os.path.exists(f):
-            return f
-        f += ".py"
-        if os.path.exists(f):
-            return f
-    return None
-
+class PackageFinder(object):
+    """Imports every package as a python package named like it, so its
+    modules are PackageName.module and relative imports work."""
 
-class __myfinder:
-
-    class myloader(object):
-
-        def load_module(self, fullname):
-            if fullname in sys.modules:
-                return sys.modules[fullname]
-            f = fn(fullname)
-            if not f.endswith(".py"):
-                print("new module: %s" % f)
-                m = imp.new_module(fullname)
-                m.__path__ = f
-                sys.modules[fullname] = m
-                return m
-            return imp.load_source(fullname, f)
-
-    def find_module(self, fullname, path=None):
-        f = fn(fullname)
-        if f and "/lime/" in f:  # TODO
-            return self.myloader()
-
-sys.meta_path.append(__myfinder())
+    def __init__(self):
+        self.packages = {}
+
+    def add_package(self, name, path):
+        paths = self.packages.setdefault(name, [])
+        if path not in paths:
+            paths.append(path)
+        m = getattr(sys.modules.get(name), "__path__", None)
+        if m is not None and path not in m:
+            m.append(path)
+
+    def find_spec(self, fullname, path, target=None):
+        if fullname not in self.packages:
+            return None
+        spec = importlib.machinery.ModuleSpec(fullname, self, is_package=True)
+        spec.submodule_search_locations = list(self.packages[fullname])
+        return spec
+
+    def create_module(self, spec):
+        return None
+
+    def exec_module(self, module):
+        pass
+
+package_finder = PackageFinder()
+sys.meta_path.insert(0, package_finder)
+
+# the modules loaded as plugins, all the modules of a package are imported
+# again when one of its plugins is reloaded
+plugins = set()
+
+
+# Removes the modules of the plugin's package, the other plugins among them
+# are returned to be loaded again with the new modules.
+def purge_modules(module):
+    package = module.split(".")[0]
+    others = []
+    for name in list(sys.modules):
+        if not name.startswith(package + "."):
+            continue
+        del sys.modules[name]
+        if name != module and name in plugins:
+            others.append(name)
+        # or "from . import name" would still find the old module
+        parent, _, child = name.rpartition(".")
+        if hasattr(sys.modules.get(parent), child):
+            delattr(sys.modules[parent], child)
+    return sorted(others)
+
+
+def reload_plugin(module, path=None):
+    print("Loading plugin %s" % module)
+    try:
+        if path:
+            package_finder.add_package(module.split(".")[0], os.path.dirname(path))
+        others = purge_modules(module)
+        importlib.invalidate_caches()
+        load_plugin(module)
+        for other in others:
+            print("Loading plugin %s" % other)
+            load_plugin(other)
+    except:
+        traceback.print_exc()
 
 
-def reload_plugin(module):
+def load_plugin(module):
     def cmdname(name):
         if name.endswith("Command"):
             name = name[:-7]
@@ -103,8 +169,8 @@ def reload_plugin(module):
                 ret += "_"
             ret += l
         return ret
-    print("Loading plugin %s" % module)
     try:
+        plugins.add(module)
         module = importlib.import_module(module)
         for item in inspect.getmembers(module):
             if not isinstance(item[1], type(EventListener)):
@@ -117,6 +183,12 @@ def reload_plugin(module):
                     toadd = getattr(inst, "on_query_context", None)
                     if toadd:
                         sublime.OnQueryContextGlue(toadd)
+                    toadd = getattr(inst, "on_query_completions", None)
+                    if toadd:
+                        sublime.OnQueryCompletionsGlue(toadd)
+                    toadd = getattr(inst, "on_hover", None)
+                    if toadd:
+                        sublime.OnHoverGlue(toadd)
                     for name in ["on_load"]:  # TODO
                         toadd = getattr(inst, name, None)
                         if toadd:
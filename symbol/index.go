@@ -0,0 +1,95 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"sort"
+	"sync"
+)
+
+type (
+	// Location is where a symbol is defined, row and col start at 1.
+	Location struct {
+		Path     string
+		Name     string
+		Row, Col int
+	}
+
+	// Index maps symbols to the files defining them, it's used for goto
+	// definition across a project.
+	Index struct {
+		lock  sync.Mutex
+		files map[string][]Location
+	}
+)
+
+func NewIndex() *Index {
+	return &Index{files: make(map[string][]Location)}
+}
+
+// Update replaces the symbols of the file at path, data is the content of the
+// file the symbol regions refer to.
+func (i *Index) Update(path, data string, symbols []Symbol) {
+	locs := make([]Location, len(symbols))
+	rs := []rune(data)
+	row, col, pos := 1, 1, 0
+	for j, s := range symbols {
+		// symbols are in order so the rows are counted once
+		for ; pos < s.Region.Begin() && pos < len(rs); pos++ {
+			if rs[pos] == '\n' {
+				row, col = row+1, 1
+			} else {
+				col++
+			}
+		}
+		locs[j] = Location{Path: path, Name: s.Name, Row: row, Col: col}
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.files[path] = locs
+}
+
+// Remove removes the symbols of the file at path.
+func (i *Index) Remove(path string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	delete(i.files, path)
+}
+
+// Lookup returns the locations of the symbol ordered by path and row.
+func (i *Index) Lookup(name string) []Location {
+	i.lock.Lock()
+	var ret []Location
+	for _, locs := range i.files {
+		for _, l := range locs {
+			if l.Name == name {
+				ret = append(ret, l)
+			}
+		}
+	}
+	i.lock.Unlock()
+	sort.Sort(byLocation(ret))
+	return ret
+}
+
+type byLocation []Location
+
+func (b byLocation) Len() int {
+	return len(b)
+}
+
+func (b byLocation) Less(i, j int) bool {
+	if b[i].Path != b[j].Path {
+		return b[i].Path < b[j].Path
+	}
+	if b[i].Row != b[j].Row {
+		return b[i].Row < b[j].Row
+	}
+	return b[i].Col < b[j].Col
+}
+
+func (b byLocation) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
@@ -0,0 +1,39 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/text"
+)
+
+func TestIndex(t *testing.T) {
+	idx := NewIndex()
+	idx.Update("b.go", "func main\nfunc f\n", []Symbol{
+		{text.Region{A: 5, B: 9}, "main"},
+		{text.Region{A: 15, B: 16}, "f"},
+	})
+	idx.Update("a.go", "\n\n  func f", []Symbol{
+		{text.Region{A: 9, B: 10}, "f"},
+	})
+
+	exp := []Location{
+		{Path: "a.go", Name: "f", Row: 3, Col: 8},
+		{Path: "b.go", Name: "f", Row: 2, Col: 6},
+	}
+	if got := idx.Lookup("f"); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+
+	idx.Remove("a.go")
+	if got := idx.Lookup("f"); !reflect.DeepEqual(got, exp[1:]) {
+		t.Errorf("Expected %v, but got %v", exp[1:], got)
+	}
+	if got := idx.Lookup("none"); got != nil {
+		t.Errorf("Expected no locations, but got %v", got)
+	}
+}
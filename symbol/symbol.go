@@ -0,0 +1,98 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+// Package symbol extracts the symbols of a scope tree. Scopes are picked by
+// the showInSymbolList settings of the tmPreferences files and their text is
+// transformed by symbolTransformation.
+// https://docs.sublimetext.io/reference/symbols.html
+package symbol
+
+import (
+	"strings"
+
+	"github.com/limetext/sublime/selector"
+	"github.com/limetext/sublime/textmate/preferences"
+	"github.com/limetext/text"
+	"github.com/quarnster/parser"
+)
+
+// Symbol is a symbol of a file with its region.
+type Symbol struct {
+	Region text.Region
+	Name   string
+}
+
+// symbol settings of a scope
+type settings struct {
+	show bool
+	tr   preferences.Transformation
+}
+
+type extractor struct {
+	prefs []*preferences.Preferences
+	index bool
+	cache map[string]settings
+	ret   []Symbol
+}
+
+// Extract returns the symbols of the scope tree in the order they appear.
+// With index the symbols of the indexed symbol list, which are used for
+// goto definition, are returned instead.
+func Extract(root *parser.Node, index bool) []Symbol {
+	e := &extractor{
+		prefs: preferences.All(),
+		index: index,
+		cache: make(map[string]settings),
+	}
+	e.walk(root, "")
+	return e.ret
+}
+
+func (e *extractor) walk(n *parser.Node, scope string) {
+	if n.Name != "" {
+		if scope != "" {
+			scope += " "
+		}
+		scope += n.Name
+		if set := e.settings(scope); set.show {
+			name := set.tr.Apply(strings.TrimSpace(n.P.Data(n.Range.A, n.Range.B)))
+			if name != "" {
+				e.ret = append(e.ret, Symbol{Region: n.Range, Name: name})
+			}
+		}
+	}
+	for _, c := range n.Children {
+		e.walk(c, scope)
+	}
+}
+
+// Returns the settings of the scope, every setting comes from the
+// preferences with the best matching scope selector which has it.
+func (e *extractor) settings(scope string) settings {
+	if set, ok := e.cache[scope]; ok {
+		return set
+	}
+	var (
+		set                settings
+		showScore, trScore int
+	)
+	for _, p := range e.prefs {
+		sc := selector.Score(p.Scope, scope)
+		if sc == 0 {
+			continue
+		}
+		show, tr := p.Settings.ShowInSymbolList, p.Settings.SymbolTransformation
+		if e.index {
+			show, tr = p.Settings.ShowInIndexedSymbolList, p.Settings.SymbolIndexTransformation
+		}
+		if show != 0 && sc > showScore {
+			set.show, showScore = show == 1, sc
+		}
+		if len(tr) != 0 && sc > trScore {
+			set.tr, trScore = tr, sc
+		}
+	}
+	e.cache[scope] = set
+	return set
+}
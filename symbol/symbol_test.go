@@ -0,0 +1,86 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/limetext/sublime/textmate/preferences"
+	"github.com/limetext/text"
+	"github.com/quarnster/parser"
+)
+
+type data string
+
+func (d data) Data(a, b int) string {
+	return string(d)[a:b]
+}
+
+func node(d data, name string, a, b int, children ...*parser.Node) *parser.Node {
+	return &parser.Node{Name: name, Range: text.Region{A: a, B: b}, P: d, Children: children}
+}
+
+func transformation(t *testing.T, s string) preferences.Transformation {
+	tr, err := preferences.ParseTransformation(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+func TestExtract(t *testing.T) {
+	preferences.Add("Packages/Test/Symbols.tmPreferences", &preferences.Preferences{
+		Scope: "source.test entity.name.function, source.test entity.name.type",
+		Settings: preferences.Settings{
+			ShowInSymbolList:          1,
+			ShowInIndexedSymbolList:   1,
+			SymbolIndexTransformation: transformation(t, `s/^\*//`),
+		},
+	})
+	preferences.Add("Packages/Test/Methods.tmPreferences", &preferences.Preferences{
+		Scope: "source.test meta.type entity.name.function",
+		Settings: preferences.Settings{
+			SymbolTransformation: transformation(t, `s/^/  /`),
+		},
+	})
+	preferences.Add("Packages/Test/Hidden.tmPreferences", &preferences.Preferences{
+		Scope: "source.test entity.name.function.hidden",
+		Settings: preferences.Settings{
+			ShowInSymbolList: 2,
+		},
+	})
+
+	//         0         1         2         3         4
+	//         0123456789012345678901234567890123456789012345
+	d := data("func main\ntype T { func *m }\nfunc hidden\n")
+	root := node(d, "source.test", 0, len(d),
+		node(d, "meta.function", 0, 9, node(d, "entity.name.function", 5, 9)),
+		node(d, "meta.type", 10, 28,
+			node(d, "entity.name.type", 15, 16),
+			node(d, "meta.function", 19, 26, node(d, "entity.name.function", 24, 26)),
+		),
+		node(d, "meta.function", 29, 40, node(d, "entity.name.function.hidden", 34, 40)),
+	)
+
+	exp := []Symbol{
+		{text.Region{A: 5, B: 9}, "main"},
+		{text.Region{A: 15, B: 16}, "T"},
+		{text.Region{A: 24, B: 26}, "  *m"},
+	}
+	if got := Extract(root, false); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+
+	exp = []Symbol{
+		{text.Region{A: 5, B: 9}, "main"},
+		{text.Region{A: 15, B: 16}, "T"},
+		{text.Region{A: 24, B: 26}, "m"},
+		{text.Region{A: 34, B: 40}, "hidden"},
+	}
+	if got := Extract(root, true); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected %v, but got %v", exp, got)
+	}
+}
@@ -5,8 +5,7 @@
 package syntax
 
 import (
-	"io/ioutil"
-
+	"github.com/limetext/sublime/resource"
 	"gopkg.in/yaml.v1"
 )
 
@@ -24,7 +23,7 @@ type Syntax struct {
 
 func Load(filename string) (*Syntax, error) {
 	var syn Syntax
-	data, err := ioutil.ReadFile(filename)
+	data, err := resource.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,5 @@
+from .plugin import FirstNameCommand
+
+
+class FirstOtherCommand(FirstNameCommand):
+    pass
@@ -0,0 +1,8 @@
+import sublime_plugin
+from . import utils
+
+
+class FirstNameCommand(sublime_plugin.WindowCommand):
+
+    def run(self):
+        print(utils.NAME)
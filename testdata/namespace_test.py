@@ -0,0 +1,26 @@
+import sys
+import traceback
+try:
+    import sublime_plugin
+    import First.plugin
+    import Second.plugin
+
+    assert First.plugin.utils.NAME == "first"
+    assert Second.plugin.utils.NAME == "second"
+    assert sys.modules["First.utils"] is not sys.modules["Second.utils"]
+
+    # reloading a plugin imports the other modules of its package again
+    utils = sys.modules["First.utils"]
+    plugin = sys.modules["First.plugin"]
+    sublime_plugin.reload_plugin("First.plugin")
+    assert sys.modules["First.utils"] is not utils
+    assert sys.modules["First.plugin"] is not plugin
+    assert sys.modules["Second.utils"].NAME == "second"
+
+    # the other plugins of the package are loaded again with the new modules
+    assert issubclass(sys.modules["First.other"].FirstOtherCommand, sys.modules["First.plugin"].FirstNameCommand)
+    assert "Second.plugin" in sys.modules
+except:
+    print(sys.exc_info()[1])
+    traceback.print_exc()
+    raise
@@ -16,3 +16,47 @@ class TestWindow(sublime_plugin.WindowCommand):
         e = v.begin_edit()
         v.insert(e, 0, "window hello")
         v.end_edit(e)
+
+# the edits of TestKeepEdit, used after the command returned
+kept = []
+
+
+class TestKeepEdit(sublime_plugin.TextCommand):
+
+    def run(self, edit):
+        kept.append(edit)
+        kept.append(self.view.begin_edit())
+
+
+# whether TestBypassEdit got a ValueError using its edit
+bypassed = []
+
+
+class TestBypassEdit(sublime_plugin.TextCommand):
+
+    def run_(self, edit, args):
+        try:
+            self.view.insert(edit, 0, "x")
+            bypassed.append(False)
+        except ValueError:
+            bypassed.append(True)
+
+
+class TestState(sublime_plugin.WindowCommand):
+    created = 0
+
+    def __init__(self, wnd):
+        super().__init__(wnd)
+        TestState.created += 1
+
+    def is_enabled(self):
+        return False
+
+    def is_checked(self, checked=False):
+        return checked
+
+    def description(self, name="state"):
+        return "%s %d" % (name, TestState.created)
+
+    def run(self):
+        pass
@@ -1,4 +1,5 @@
 try:
+    import sys
     import traceback
     import sublime
     print("new file")
@@ -13,6 +14,24 @@ try:
     v = sublime.active_window().active_view()
     sublime.active_window().run_command("test_window")
     assert v.substr(sublime.Region(0, v.size())) == "window hello"
+
+    # edits expire once their command returns
+    v.run_command("test_keep_edit")
+    assert not v.is_in_edit()
+    kept = sys.modules["testdata.plugin"].kept
+    assert len(kept) == 2
+    for e in kept:
+        try:
+            v.insert(e, 0, "x")
+            assert False
+        except ValueError:
+            pass
+    assert v.substr(sublime.Region(0, v.size())) == "window hello"
+
+    # commands bypassing the undo stack get an ended edit
+    v.run_command("test_bypass_edit")
+    assert sys.modules["testdata.plugin"].bypassed == [True]
+    assert v.substr(sublime.Region(0, v.size())) == "window hello"
 except:
     traceback.print_exc()
     raise
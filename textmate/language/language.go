@@ -8,11 +8,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"strings"
 	"sync"
 
 	"github.com/limetext/loaders"
+	"github.com/limetext/sublime/resource"
 )
 
 type (
@@ -38,7 +38,7 @@ type (
 )
 
 func Load(filename string) (*Language, error) {
-	d, err := ioutil.ReadFile(filename)
+	d, err := resource.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't load file %s: %s", filename, err)
 	}
@@ -7,10 +7,11 @@ package preferences
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"sort"
+	"sync"
 
 	"github.com/limetext/loaders"
+	"github.com/limetext/sublime/resource"
 	"github.com/limetext/sublime/textmate"
 )
 
@@ -32,8 +33,8 @@ type (
 		CancelCompletion             textmate.Regex
 		ShowInSymbolList             int
 		ShowInIndexedSymbolList      int
-		SymbolTransformation         textmate.Regex
-		SymbolIndexTransformation    textmate.Regex
+		SymbolTransformation         Transformation
+		SymbolIndexTransformation    Transformation
 		ShellVariables               ShellVariables
 	}
 
@@ -42,7 +43,7 @@ type (
 
 func Load(filename string) (*Preferences, error) {
 	var pref Preferences
-	if d, err := ioutil.ReadFile(filename); err != nil {
+	if d, err := resource.ReadFile(filename); err != nil {
 		return nil, fmt.Errorf("Unable to read preferences file: %s", err)
 	} else if err = loaders.LoadPlist(d, &pref); err != nil {
 		return nil, fmt.Errorf("Unable to load preferences data: %s", err)
@@ -51,6 +52,42 @@ func Load(filename string) (*Preferences, error) {
 	return &pref, nil
 }
 
+var prefs = struct {
+	sync.Mutex
+	m map[string]*Preferences
+}{m: make(map[string]*Preferences)}
+
+// Add registers the preferences under the given key which is usually the
+// resource name of its file.
+func Add(key string, p *Preferences) {
+	prefs.Lock()
+	defer prefs.Unlock()
+	prefs.m[key] = p
+}
+
+// Get returns the preferences registered with key.
+func Get(key string) *Preferences {
+	prefs.Lock()
+	defer prefs.Unlock()
+	return prefs.m[key]
+}
+
+// All returns the registered preferences ordered by their keys.
+func All() []*Preferences {
+	prefs.Lock()
+	defer prefs.Unlock()
+	keys := make([]string, 0, len(prefs.m))
+	for k := range prefs.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ret := make([]*Preferences, len(keys))
+	for i, k := range keys {
+		ret[i] = prefs.m[k]
+	}
+	return ret
+}
+
 func (m Preferences) String() string {
 	ret := fmt.Sprintf("%s - %s\n", m.Name, m.UUID)
 	ret += fmt.Sprintf("Scope: %s\n", m.Scope)
@@ -29,3 +29,36 @@ func TestLoad(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestTransformation(t *testing.T) {
+	tests := []struct {
+		tr, in, exp string
+	}{
+		{`s/^\s*func\s+//;`, "  func main()", "main()"},
+		{`
+			s/^\s*func\s+//;
+			s/\(.*//;
+		`, "func main(args)", "main"},
+		{`s/a/b/g`, "banana", "bbnbnb"},
+		{`s/a/b/`, "banana", "bbnana"},
+		{`s/A/b/gi`, "banana", "bbnbnb"},
+		{`s/(\w+)\.(\w+)/\u$2 of $1/`, "pkg.func", "Func of pkg"},
+		{`s/\//./g`, "a/b/c", "a.b.c"},
+	}
+	for i, test := range tests {
+		tr, err := ParseTransformation(test.tr)
+		if err != nil {
+			t.Errorf("Test %d: Error parsing transformation: %s", i, err)
+			continue
+		}
+		if got := tr.Apply(test.in); got != test.exp {
+			t.Errorf("Test %d: Expected %q, but got %q", i, test.exp, got)
+		}
+	}
+
+	for _, tr := range []string{"x/a/b/", "s/a/b", "s/(/b/"} {
+		if _, err := ParseTransformation(tr); err == nil {
+			t.Errorf("Expected error parsing %q", tr)
+		}
+	}
+}
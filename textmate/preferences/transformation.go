@@ -0,0 +1,108 @@
+// Copyright 2017 The lime Authors.
+// Use of this source code is governed by a 2-clause
+// BSD-style license that can be found in the LICENSE file.
+
+package preferences
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/limetext/backend/log"
+	"github.com/limetext/sublime/snippet"
+	"github.com/limetext/sublime/textmate"
+)
+
+type (
+	// Transformation is a list of s/regex/format/flags; substitutions like
+	// the symbolTransformation setting, applied in order.
+	Transformation []substitution
+
+	substitution struct {
+		pattern string
+		re      *textmate.Regex
+		format  string
+		flags   string
+	}
+)
+
+// ParseTransformation parses the substitutions of a transformation.
+func ParseTransformation(s string) (Transformation, error) {
+	var ret Transformation
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) || rs[i] == ';' {
+			i++
+			continue
+		}
+		if rs[i] != 's' || i+1 >= len(rs) || rs[i+1] != '/' {
+			return nil, fmt.Errorf("Expected s/regex/format/ at %d of transformation %q", i, s)
+		}
+		i += 2
+		var parts [2]string
+		for j := range parts {
+			var buf []rune
+			for ; i < len(rs) && rs[i] != '/'; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) && rs[i+1] == '/' {
+					i++
+				}
+				buf = append(buf, rs[i])
+			}
+			if i >= len(rs) {
+				return nil, fmt.Errorf("Unclosed substitution in transformation %q", s)
+			}
+			parts[j] = string(buf)
+			i++
+		}
+		start := i
+		for i < len(rs) && rs[i] != ';' && !unicode.IsSpace(rs[i]) {
+			i++
+		}
+		sub := substitution{pattern: parts[0], format: parts[1], flags: string(rs[start:i])}
+		re := sub.pattern
+		if strings.Contains(sub.flags, "i") {
+			re = "(?i)" + re
+		}
+		var err error
+		if sub.re, err = textmate.NewRegex(re); err != nil {
+			return nil, err
+		}
+		ret = append(ret, sub)
+	}
+	return ret, nil
+}
+
+func (t *Transformation) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	tr, err := ParseTransformation(s)
+	if err != nil {
+		log.Warn("Couldn't parse transformation %s: %s", s, err)
+		return nil
+	}
+	*t = tr
+	return nil
+}
+
+// Apply runs the substitutions on s.
+func (t Transformation) Apply(s string) string {
+	for _, sub := range t {
+		s = snippet.Replace(sub.re, s, sub.format, strings.Contains(sub.flags, "g"))
+	}
+	return s
+}
+
+func (t Transformation) String() string {
+	if len(t) == 0 {
+		return "nil"
+	}
+	subs := make([]string, len(t))
+	for i, sub := range t {
+		subs[i] = fmt.Sprintf("s/%s/%s/%s;", sub.pattern, sub.format, sub.flags)
+	}
+	return strings.Join(subs, " ")
+}
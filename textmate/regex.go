@@ -23,6 +23,16 @@ type (
 	MatchObject []int
 )
 
+// NewRegex compiles the pattern the way the patterns of syntaxes are
+// compiled.
+func NewRegex(pattern string) (*Regex, error) {
+	re, err := rubex.CompileWithOption(pattern, rubex.ONIG_OPTION_CAPTURE_GROUP)
+	if err != nil {
+		return nil, err
+	}
+	return &Regex{re: re}, nil
+}
+
 func (r Regex) Empty() bool {
 	return r.re == nil
 }
@@ -87,6 +97,15 @@ func (r *Regex) Find(data string, pos int) MatchObject {
 	return nil
 }
 
+// FindAllStringSubmatchIndex returns the submatch indices of up to n
+// successive matches in data, all of them if n is negative.
+func (r *Regex) FindAllStringSubmatchIndex(data string, n int) [][]int {
+	if r.re == nil {
+		return nil
+	}
+	return r.re.FindAllStringSubmatchIndex(data, n)
+}
+
 func (r *Regex) Copy() *Regex {
 	ret := &Regex{}
 	if r.re == nil {
@@ -7,13 +7,13 @@ package theme
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"sort"
 	"strings"
 
 	"github.com/limetext/backend/log"
 	"github.com/limetext/backend/render"
 	"github.com/limetext/loaders"
+	"github.com/limetext/sublime/resource"
 	"github.com/limetext/util"
 )
 
@@ -37,7 +37,7 @@ type (
 
 func Load(filename string) (*Theme, error) {
 	var scheme Theme
-	if d, err := ioutil.ReadFile(filename); err != nil {
+	if d, err := resource.ReadFile(filename); err != nil {
 		return nil, fmt.Errorf("Unable to read theme definition: %s", err)
 	} else if err := loaders.LoadPlist(d, &scheme); err != nil {
 		return nil, fmt.Errorf("Unable to load theme definition: %s", err)